@@ -0,0 +1,55 @@
+package audit
+
+import "context"
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+)
+
+const (
+	Anonymous       = "anonymous"
+	RequestIDHeader = "X-Request-ID"
+
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionPurge   = "purge"
+
+	EntityAuthor     = "author"
+	EntityBook       = "book"
+	EntityBookAuthor = "book_author"
+	EntityPublisher  = "publisher"
+	EntityGenre      = "genre"
+	EntityBookGenre  = "book_genre"
+	EntityWork       = "work"
+)
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+func Actor(ctx context.Context) string {
+	if ctx != nil {
+		if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+			return actor
+		}
+	}
+	return Anonymous
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	if ctx != nil {
+		if requestID, ok := ctx.Value(requestIDKey).(string); ok {
+			return requestID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+)
+
+const (
+	PrincipalKey = "principal"
+	APIKeyHeader = "X-API-Key"
+
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+
+	apiKeyPrefix = "olk_"
+)
+
+type Role string
+
+const (
+	RoleReader    Role = "reader"
+	RoleLibrarian Role = "librarian"
+	RoleAdmin     Role = "admin"
+)
+
+func ParseRole(value string) (Role, bool) {
+	switch role := Role(strings.ToLower(value)); role {
+	case RoleReader, RoleLibrarian, RoleAdmin:
+		return role, true
+	}
+	return "", false
+}
+
+type Principal struct {
+	Subject string
+	Method  string
+	Role    Role
+}
+
+func (principal Principal) Actor() string {
+	return principal.Method + ":" + principal.Subject
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
+}
+
+func PrincipalFrom(ctx *gin.Context) (Principal, bool) {
+	value, ok := ctx.Get(PrincipalKey)
+
+	if !ok {
+		return Principal{}, false
+	}
+
+	principal, ok := value.(Principal)
+
+	return principal, ok
+}
+
+func GenerateAPIKey() (string, error) {
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func DisplayPrefix(key string) string {
+	if len(key) < len(apiKeyPrefix)+4 {
+		return key
+	}
+	return key[:len(apiKeyPrefix)+4]
+}
+
+type JWTVerifier struct {
+	hmacSecret   []byte
+	rsaPublicKey *rsa.PublicKey
+	issuer       string
+	audience     string
+}
+
+func NewJWTVerifier(hmacSecret []byte, rsaPublicKey *rsa.PublicKey, issuer string, audience string) *JWTVerifier {
+	return &JWTVerifier{hmacSecret, rsaPublicKey, issuer, audience}
+}
+
+func LoadJWTVerifier() (*JWTVerifier, error) {
+	var publicKey *rsa.PublicKey
+
+	if config.JWTPublicKeyPath != "" {
+		pem, err := os.ReadFile(config.JWTPublicKeyPath)
+
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewJWTVerifier([]byte(config.JWTSecret), publicKey, config.JWTIssuer, config.JWTAudience), nil
+}
+
+func (verifier *JWTVerifier) methods() []string {
+	var methods []string
+
+	if len(verifier.hmacSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if verifier.rsaPublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	return methods
+}
+
+func (verifier *JWTVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return verifier.hmacSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		return verifier.rsaPublicKey, nil
+	}
+	return nil, &custom.InvalidCredentials
+}
+
+func (verifier *JWTVerifier) parse(tokenString string, claims jwt.Claims) error {
+	methods := verifier.methods()
+
+	if len(methods) == 0 {
+		return &custom.InvalidCredentials
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+
+	if verifier.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(verifier.issuer))
+	}
+	if verifier.audience != "" {
+		opts = append(opts, jwt.WithAudience(verifier.audience))
+	}
+
+	if _, err := jwt.ParseWithClaims(tokenString, claims, verifier.keyFunc, opts...); err != nil {
+		return &custom.InvalidCredentials
+	}
+
+	return nil
+}
+
+func (verifier *JWTVerifier) Verify(tokenString string) (Principal, error) {
+	var tokenClaims claims
+
+	if err := verifier.parse(tokenString, &tokenClaims); err != nil {
+		return Principal{}, err
+	}
+
+	role := RoleReader
+
+	if tokenClaims.Role != "" {
+		parsed, ok := ParseRole(tokenClaims.Role)
+
+		if !ok {
+			return Principal{}, &custom.InvalidCredentials
+		}
+		role = parsed
+	}
+
+	return Principal{Subject: tokenClaims.Subject, Method: MethodJWT, Role: role}, nil
+}
+
+func FromRequest(req *http.Request, apiKeys repositories.APIKeyRepository, verifier *JWTVerifier) (Principal, error) {
+	return FromHeader(req.Header, apiKeys, verifier)
+}
+
+// FromHeader authenticates the API key or bearer token in header, for callers
+// that do not hold an *http.Request, such as the gRPC service.
+func FromHeader(header http.Header, apiKeys repositories.APIKeyRepository, verifier *JWTVerifier) (Principal, error) {
+	if key := header.Get(APIKeyHeader); key != "" {
+		apiKey, err := apiKeys.GetActiveByHash(HashAPIKey(key))
+
+		if err != nil {
+			if errors.Is(err, &custom.APIKeyNotFound) {
+				return Principal{}, &custom.InvalidCredentials
+			}
+			return Principal{}, err
+		}
+
+		role, ok := ParseRole(apiKey.Role)
+
+		if !ok {
+			return Principal{}, &custom.InvalidCredentials
+		}
+
+		return Principal{Subject: apiKey.ID.String(), Method: MethodAPIKey, Role: role}, nil
+	}
+
+	if authorization := header.Get("Authorization"); authorization != "" {
+		scheme, token, found := strings.Cut(authorization, " ")
+
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return Principal{}, &custom.InvalidCredentials
+		}
+
+		return verifier.Verify(token)
+	}
+
+	return Principal{}, &custom.MissingCredentials
+}
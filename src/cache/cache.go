@@ -0,0 +1,240 @@
+// Package cache keeps the results of catalog reads in memory for a short time.
+// Entries are tagged with the entities they were built from and writes drop
+// every entry carrying one of their tags.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+)
+
+type contextKey int
+
+const bypassKey contextKey = iota
+
+// WithBypass marks ctx so that reads skip the cache and go to the database.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey, true)
+}
+
+func Bypassed(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	bypass, _ := ctx.Value(bypassKey).(bool)
+	return bypass
+}
+
+type Stats struct {
+	Enabled       bool   `json:"enabled"`
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Bypasses      uint64 `json:"bypasses"`
+	Evictions     uint64 `json:"evictions"`
+	Invalidations uint64 `json:"invalidations"`
+	Entries       int    `json:"entries"`
+}
+
+type entry struct {
+	key       string
+	value     interface{}
+	tags      []string
+	expiresAt time.Time
+}
+
+// Cache is a size bounded LRU of entries expiring after a TTL. A nil *Cache is
+// valid and caches nothing.
+type Cache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+	entries    map[string]*list.Element
+	order      *list.List
+	tagged     map[string]map[string]struct{}
+	epoch      uint64
+	stats      Stats
+}
+
+func New(ttl time.Duration, maxEntries int, now func() time.Time) *Cache {
+	if now == nil {
+		now = time.Now
+	}
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        now,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+		tagged:     map[string]map[string]struct{}{},
+	}
+}
+
+var (
+	shared     *Cache
+	sharedOnce sync.Once
+)
+
+// Shared is the cache of the process, built from the config on first use. It
+// is nil when the cache is disabled.
+func Shared() *Cache {
+	sharedOnce.Do(func() {
+		if config.CacheEnabled && config.CacheTTL > 0 && config.CacheMaxEntries > 0 {
+			shared = New(config.CacheTTL, config.CacheMaxEntries, nil)
+		}
+	})
+	return shared
+}
+
+// Fetch answers the value cached under key or loads it, caching the result
+// under the tags it was built from. Errors are never cached, and neither is a
+// value loaded while a write invalidated the cache, since it may predate the
+// write.
+func Fetch[T any](ctx context.Context, cache *Cache, key string, load func() (T, error), tags func(T) []string) (T, error) {
+	if cache == nil {
+		return load()
+	}
+
+	if Bypassed(ctx) {
+		cache.count(func(stats *Stats) { stats.Bypasses++ })
+		return load()
+	}
+
+	if value, ok := cache.get(key); ok {
+		return value.(T), nil
+	}
+
+	epoch := cache.currentEpoch()
+
+	value, err := load()
+
+	if err != nil {
+		return value, err
+	}
+
+	cache.set(key, value, tags(value), epoch)
+
+	return value, nil
+}
+
+func (cache *Cache) count(update func(stats *Stats)) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	update(&cache.stats)
+}
+
+func (cache *Cache) currentEpoch() uint64 {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return cache.epoch
+}
+
+func (cache *Cache) get(key string) (interface{}, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.entries[key]
+
+	if !ok {
+		cache.stats.Misses++
+		return nil, false
+	}
+
+	current := element.Value.(*entry)
+
+	if !cache.now().Before(current.expiresAt) {
+		cache.remove(element)
+		cache.stats.Misses++
+		return nil, false
+	}
+
+	cache.order.MoveToFront(element)
+	cache.stats.Hits++
+
+	return current.value, true
+}
+
+func (cache *Cache) set(key string, value interface{}, tags []string, epoch uint64) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if epoch != cache.epoch {
+		return
+	}
+
+	if element, ok := cache.entries[key]; ok {
+		cache.remove(element)
+	}
+
+	element := cache.order.PushFront(&entry{key, value, tags, cache.now().Add(cache.ttl)})
+	cache.entries[key] = element
+
+	for _, tag := range tags {
+		if cache.tagged[tag] == nil {
+			cache.tagged[tag] = map[string]struct{}{}
+		}
+		cache.tagged[tag][key] = struct{}{}
+	}
+
+	for cache.order.Len() > cache.maxEntries {
+		cache.remove(cache.order.Back())
+		cache.stats.Evictions++
+	}
+}
+
+// Invalidate drops every entry tagged with any of tags.
+func (cache *Cache) Invalidate(tags ...string) {
+	if cache == nil || len(tags) < 1 {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.epoch++
+
+	for _, tag := range tags {
+		for key := range cache.tagged[tag] {
+			if element, ok := cache.entries[key]; ok {
+				cache.remove(element)
+				cache.stats.Invalidations++
+			}
+		}
+	}
+}
+
+func (cache *Cache) remove(element *list.Element) {
+	current := element.Value.(*entry)
+
+	cache.order.Remove(element)
+	delete(cache.entries, current.key)
+
+	for _, tag := range current.tags {
+		delete(cache.tagged[tag], current.key)
+
+		if len(cache.tagged[tag]) < 1 {
+			delete(cache.tagged, tag)
+		}
+	}
+}
+
+func (cache *Cache) Stats() Stats {
+	if cache == nil {
+		return Stats{}
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	stats := cache.stats
+	stats.Enabled = true
+	stats.Entries = cache.order.Len()
+
+	return stats
+}
@@ -3,14 +3,27 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"log"
+	"net"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
 	"github.com/joaooliveira247/go_olist_challenge/src/config"
 	"github.com/joaooliveira247/go_olist_challenge/src/db"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
 	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
 	"github.com/joaooliveira247/go_olist_challenge/src/routes"
+	"github.com/joaooliveira247/go_olist_challenge/src/rpc"
+	"github.com/joaooliveira247/go_olist_challenge/src/tracing"
 	"github.com/joaooliveira247/go_olist_challenge/src/utils"
+	"github.com/joaooliveira247/go_olist_challenge/src/webhooks"
 	"github.com/urfave/cli/v3"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 )
 
 func createTables(_ context.Context, cmd *cli.Command) error {
@@ -41,37 +54,142 @@ func deleteTables(_ context.Context, cmd *cli.Command) error {
 	return nil
 }
 
-func runAPI(_ context.Context, cmd *cli.Command) error {
+const cliActor = "cli"
+
+func purgeDeleted(ctx context.Context, cmd *cli.Command) error {
+	olderThan := cmd.Duration("older-than")
+
+	if olderThan <= 0 {
+		return fmt.Errorf("--older-than must be greater than zero")
+	}
+
+	gormDB, err := db.GetDBConnection()
+
+	if err != nil {
+		return err
+	}
+
+	deletedBefore := time.Now().Add(-olderThan)
+	ctx = audit.WithActor(ctx, cliActor)
+
+	books, err := repositories.NewBookRepository(gormDB).WithContext(ctx).Purge(deletedBefore)
+
+	if err != nil {
+		return err
+	}
+
+	authors, err := repositories.NewAuthorRepository(gormDB).WithContext(ctx).Purge(deletedBefore)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(fmt.Sprintf("Purged %d books and %d authors deleted before %s", books, authors, deletedBefore.Format(time.RFC3339)))
+
+	return nil
+}
+
+func runAPI(ctx context.Context, cmd *cli.Command) error {
+	shutdown, err := tracing.Setup(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer shutdown(context.Background())
+
+	gormDB, err := db.GetDBConnection()
+
+	if err != nil {
+		return err
+	}
+
 	api := gin.Default()
-	routes.RegistryRoutes(api)
+
+	if config.TracingEnabled {
+		api.Use(tracing.Middleware())
+	}
+
+	routes.RegistryRoutes(api, gormDB)
 	port := config.APIPort
 	if cliPort := cmd.Int("port"); cliPort > 0 {
 		port = int(cliPort)
 	}
 
+	grpcPort := config.GRPCPort
+	if cliPort := cmd.Int("grpc-port"); cliPort > 0 {
+		grpcPort = int(cliPort)
+	}
+
+	if grpcPort > 0 {
+		server, err := serveGRPC(gormDB, grpcPort)
+
+		if err != nil {
+			return err
+		}
+		defer server.GracefulStop()
+	}
+
+	if config.WebhooksEnabled {
+		dispatcher := webhooks.NewDispatcher(repositories.NewWebhookRepository(gormDB), config.WebhookMaxAttempts, config.WebhookBackoff, config.WebhookTimeout)
+		go dispatcher.Run(ctx, config.WebhookDispatchInterval)
+	}
+
 	if err := api.Run(fmt.Sprintf(":%d", port)); err != nil {
 		return err
 	}
 	return nil
 }
 
-func importAuthorsFromCSV(_ context.Context, cmd *cli.Command) error {
+// serveGRPC starts the Catalog gRPC service on its own port, next to the REST
+// API.
+func serveGRPC(gormDB *gorm.DB, port int) (*grpc.Server, error) {
+	verifier, err := auth.LoadJWTVerifier()
+
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+
+	if err != nil {
+		return nil, err
+	}
+
+	server := rpc.NewServerFromDB(gormDB, verifier)
+
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Println("GRPC: ", err)
+		}
+	}()
+
+	return server, nil
+}
+
+func importAuthorsFromCSV(ctx context.Context, cmd *cli.Command) error {
 	header := cmd.Bool("header")
 	path := cmd.Args().Get(0)
 
-	authors, err := utils.ParseAuthorsFromCSV(path, header)
+	authors, err := utils.ParseAuthorsFromCSV(path, header, cmd.StringSlice("columns"))
 
 	if err != nil {
 		return err
 	}
 
+	for i := range authors {
+		if err := binding.Validator.ValidateStruct(&authors[i]); err != nil {
+			return fmt.Errorf("author %d: %w", i+1, err)
+		}
+	}
+
 	gormDB, err := db.GetDBConnection()
 
 	if err != nil {
 		return err
 	}
 
-	repository := repositories.NewAuthorRepository(gormDB)
+	repository := repositories.NewAuthorRepository(gormDB).WithContext(audit.WithActor(ctx, cliActor))
 
 	IDs, err := repository.CreateMany(&authors)
 
@@ -84,6 +202,128 @@ func importAuthorsFromCSV(_ context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+func importBooksFromCSV(ctx context.Context, cmd *cli.Command) error {
+	header := cmd.Bool("header")
+	path := cmd.Args().Get(0)
+
+	books, err := utils.ParseBooksFromCSV(path, header, cmd.StringSlice("columns"))
+
+	if err != nil {
+		return err
+	}
+
+	for i := range books {
+		if err := binding.Validator.ValidateStruct(&books[i]); err != nil {
+			return fmt.Errorf("book %d: %w", i+1, err)
+		}
+	}
+
+	gormDB, err := db.GetDBConnection()
+
+	if err != nil {
+		return err
+	}
+
+	repository := repositories.NewBookRepository(gormDB).WithContext(audit.WithActor(ctx, cliActor))
+
+	IDs, err := repository.CreateMany(books)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(fmt.Sprintf("Inserted with IDs: %s", IDs))
+
+	return nil
+}
+
+func createAPIKey(_ context.Context, cmd *cli.Command) error {
+	role, ok := auth.ParseRole(cmd.String("role"))
+
+	if !ok {
+		return fmt.Errorf("role '%s' not supported, use reader, librarian or admin", cmd.String("role"))
+	}
+
+	key, err := auth.GenerateAPIKey()
+
+	if err != nil {
+		return err
+	}
+
+	gormDB, err := db.GetDBConnection()
+
+	if err != nil {
+		return err
+	}
+
+	repository := repositories.NewAPIKeyRepository(gormDB)
+
+	id, err := repository.Create(&models.APIKey{
+		Name:   cmd.String("name"),
+		Prefix: auth.DisplayPrefix(key),
+		Hash:   auth.HashAPIKey(key),
+		Role:   string(role),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(fmt.Sprintf("Created API key %s, store it now, it will not be shown again:\n%s", id, key))
+
+	return nil
+}
+
+func listAPIKeys(_ context.Context, cmd *cli.Command) error {
+	gormDB, err := db.GetDBConnection()
+
+	if err != nil {
+		return err
+	}
+
+	repository := repositories.NewAPIKeyRepository(gormDB)
+
+	keys, err := repository.GetAll()
+
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		status := "active"
+		if key.RevokedAt != nil {
+			status = "revoked"
+		}
+		fmt.Println(fmt.Sprintf("%s\t%s...\t%s\t%s\t%s", key.ID, key.Prefix, key.Name, key.Role, status))
+	}
+
+	return nil
+}
+
+func revokeAPIKey(_ context.Context, cmd *cli.Command) error {
+	id, err := uuid.Parse(cmd.Args().Get(0))
+
+	if err != nil {
+		return err
+	}
+
+	gormDB, err := db.GetDBConnection()
+
+	if err != nil {
+		return err
+	}
+
+	repository := repositories.NewAPIKeyRepository(gormDB)
+
+	if err := repository.Revoke(id); err != nil {
+		return err
+	}
+
+	fmt.Println(fmt.Sprintf("Revoked API key %s", id))
+
+	return nil
+}
+
 func Gen() *cli.Command {
 	cmd := &cli.Command{
 		Commands: []*cli.Command{
@@ -97,6 +337,10 @@ func Gen() *cli.Command {
 						Aliases: []string{"p"},
 						Usage:   "Port that API will run",
 					},
+					&cli.UintFlag{
+						Name:  "grpc-port",
+						Usage: "Port that the gRPC service will run, off unless set here or in GRPC_PORT",
+					},
 				},
 				Action: runAPI,
 			},
@@ -118,6 +362,19 @@ func Gen() *cli.Command {
 						Usage:   "Delete all tables",
 						Action:  deleteTables,
 					},
+					{
+						Name:    "purge",
+						Aliases: []string{"p"},
+						Usage:   "Hard delete soft-deleted books and authors",
+						Flags: []cli.Flag{
+							&cli.DurationFlag{
+								Name:     "older-than",
+								Required: true,
+								Usage:    "Only purge rows deleted longer ago than this, e.g. 720h",
+							},
+						},
+						Action: purgeDeleted,
+					},
 				},
 			},
 			{
@@ -129,8 +386,69 @@ func Gen() *cli.Command {
 						Value: true,
 						Usage: "Define csv has header",
 					},
+					&cli.StringSliceFlag{
+						Name:  "columns",
+						Usage: "Map the csv columns in order, e.g. name,nationality,birth_year. Defaults to the header",
+					},
 				},
 				Action: importAuthorsFromCSV,
+				Commands: []*cli.Command{
+					{
+						Name:      "books",
+						Usage:     "Import books by csv, with their isbn, authors and genres",
+						ArgsUsage: "<path_csv>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "header",
+								Value: true,
+								Usage: "Define csv has header",
+							},
+							&cli.StringSliceFlag{
+								Name:  "columns",
+								Usage: "Map the csv columns in order, e.g. title,edition,publication_year,isbn,author_ids. Defaults to the header",
+							},
+						},
+						Action: importBooksFromCSV,
+					},
+				},
+			},
+			{
+				Name:    "apikey",
+				Aliases: []string{"key"},
+				Usage:   "Manage API keys",
+				Commands: []*cli.Command{
+					{
+						Name:    "create",
+						Aliases: []string{"c"},
+						Usage:   "Create an API key",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "name",
+								Required: true,
+								Usage:    "Who or what the key belongs to",
+							},
+							&cli.StringFlag{
+								Name:  "role",
+								Value: string(auth.RoleReader),
+								Usage: "Role granted to the key: reader, librarian or admin",
+							},
+						},
+						Action: createAPIKey,
+					},
+					{
+						Name:    "list",
+						Aliases: []string{"l"},
+						Usage:   "List API keys",
+						Action:  listAPIKeys,
+					},
+					{
+						Name:      "revoke",
+						Aliases:   []string{"r"},
+						Usage:     "Revoke an API key",
+						ArgsUsage: "<id>",
+						Action:    revokeAPIKey,
+					},
+				},
 			},
 		},
 	}
@@ -5,13 +5,54 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 var (
-	DB_URL  = ""
-	APIPort = 0
+	DB_URL   = ""
+	APIPort  = 0
+	GRPCPort = 0
+
+	TracingEnabled     = false
+	TracingExporter    = "stdout"
+	TracingEndpoint    = ""
+	TracingServiceName = "go_olist_challenge"
+
+	AuthEnabled      = false
+	AuthPublicReads  = true
+	JWTSecret        = ""
+	JWTPublicKeyPath = ""
+	JWTIssuer        = ""
+	JWTAudience      = ""
+
+	RateLimitReads  = 120
+	RateLimitWrites = 30
+	RateLimitWindow = time.Minute
+
+	RequireIfMatch = false
+
+	AuthorSimilarityThreshold = 0.3
+
+	BulkMaxItems = 100
+
+	GraphQLMaxDepth      = 10
+	GraphQLMaxComplexity = 10000
+
+	CacheEnabled    = true
+	CacheTTL        = 30 * time.Second
+	CacheMaxEntries = 1000
+
+	WebhooksEnabled         = true
+	WebhookDispatchInterval = 5 * time.Second
+	WebhookMaxAttempts      = 8
+	WebhookBackoff          = 30 * time.Second
+	WebhookTimeout          = 10 * time.Second
+
+	EventsPollInterval = time.Second
+
+	IdempotencyWindow = 24 * time.Hour
 )
 
 func LoadEnv() {
@@ -34,4 +75,134 @@ func LoadEnv() {
 	if err != nil {
 		log.Fatal("error loading 'API_PORT' in .env file")
 	}
+
+	GRPCPort = getEnvInt("GRPC_PORT", GRPCPort)
+
+	TracingEnabled = getEnvBool("TRACING_ENABLED", TracingEnabled)
+	TracingExporter = getEnv("TRACING_EXPORTER", TracingExporter)
+	TracingEndpoint = getEnv("TRACING_ENDPOINT", TracingEndpoint)
+	TracingServiceName = getEnv("TRACING_SERVICE_NAME", TracingServiceName)
+
+	AuthEnabled = getEnvBool("AUTH_ENABLED", AuthEnabled)
+	AuthPublicReads = getEnvBool("AUTH_PUBLIC_READS", AuthPublicReads)
+	JWTSecret = getEnv("JWT_SECRET", JWTSecret)
+	JWTPublicKeyPath = getEnv("JWT_PUBLIC_KEY_PATH", JWTPublicKeyPath)
+	JWTIssuer = getEnv("JWT_ISSUER", JWTIssuer)
+	JWTAudience = getEnv("JWT_AUDIENCE", JWTAudience)
+
+	RateLimitReads = getEnvInt("RATE_LIMIT_READS", RateLimitReads)
+	RateLimitWrites = getEnvInt("RATE_LIMIT_WRITES", RateLimitWrites)
+	RateLimitWindow = getEnvDuration("RATE_LIMIT_WINDOW", RateLimitWindow)
+
+	RequireIfMatch = getEnvBool("REQUIRE_IF_MATCH", RequireIfMatch)
+
+	AuthorSimilarityThreshold = getEnvFloat("AUTHOR_SIMILARITY_THRESHOLD", AuthorSimilarityThreshold)
+
+	BulkMaxItems = getEnvInt("BULK_MAX_ITEMS", BulkMaxItems)
+
+	GraphQLMaxDepth = getEnvInt("GRAPHQL_MAX_DEPTH", GraphQLMaxDepth)
+	GraphQLMaxComplexity = getEnvInt("GRAPHQL_MAX_COMPLEXITY", GraphQLMaxComplexity)
+
+	CacheEnabled = getEnvBool("CACHE_ENABLED", CacheEnabled)
+	CacheTTL = getEnvDuration("CACHE_TTL", CacheTTL)
+	CacheMaxEntries = getEnvInt("CACHE_MAX_ENTRIES", CacheMaxEntries)
+
+	WebhooksEnabled = getEnvBool("WEBHOOKS_ENABLED", WebhooksEnabled)
+	WebhookDispatchInterval = getEnvDuration("WEBHOOK_DISPATCH_INTERVAL", WebhookDispatchInterval)
+	WebhookMaxAttempts = getEnvInt("WEBHOOK_MAX_ATTEMPTS", WebhookMaxAttempts)
+	WebhookBackoff = getEnvDuration("WEBHOOK_BACKOFF", WebhookBackoff)
+	WebhookTimeout = getEnvDuration("WEBHOOK_TIMEOUT", WebhookTimeout)
+
+	EventsPollInterval = getEnvDuration("EVENTS_POLL_INTERVAL", EventsPollInterval)
+
+	IdempotencyWindow = getEnvDurationAllowZero("IDEMPOTENCY_WINDOW", IdempotencyWindow)
+}
+
+func getEnv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value := getEnv(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseBool(value)
+
+	if err != nil {
+		log.Fatal(fmt.Sprintf("error loading '%s' in .env file", key))
+	}
+
+	return parsed
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := getEnv(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+
+	if err != nil {
+		log.Fatal(fmt.Sprintf("error loading '%s' in .env file", key))
+	}
+
+	return parsed
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value := getEnv(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+
+	if err != nil || parsed < 0 || parsed > 1 {
+		log.Fatal(fmt.Sprintf("error loading '%s' in .env file", key))
+	}
+
+	return parsed
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := getEnv(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+
+	if err != nil || parsed <= 0 {
+		log.Fatal(fmt.Sprintf("error loading '%s' in .env file", key))
+	}
+
+	return parsed
+}
+
+// getEnvDurationAllowZero is getEnvDuration for settings where 0 turns the
+// feature off.
+func getEnvDurationAllowZero(key string, fallback time.Duration) time.Duration {
+	value := getEnv(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+
+	if err != nil || parsed < 0 {
+		log.Fatal(fmt.Sprintf("error loading '%s' in .env file", key))
+	}
+
+	return parsed
 }
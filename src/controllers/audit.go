@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+type AuditController struct {
+	repository repositories.AuditRepository
+}
+
+func NewAuditController(repo repositories.AuditRepository) *AuditController {
+	return &AuditController{repo}
+}
+
+func (ctrl *AuditController) GetAuditLogs(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadAudit) {
+		return
+	}
+
+	var params dto.AuditQueryParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(response.InvalidParam.StatusCode, response.InvalidParam.Message)
+		return
+	}
+
+	var entityID uuid.UUID
+
+	if params.ID != "" {
+		id, err := uuid.Parse(params.ID)
+
+		if err != nil || id == uuid.Nil {
+			ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+			return
+		}
+
+		entityID = id
+	}
+
+	logs, total, err := ctrl.repository.WithContext(ctx.Request.Context()).GetAll(params.Entity, entityID, params.Page, params.PageSize)
+
+	if err != nil {
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"items":     logs,
+		"page":      params.Page,
+		"page_size": params.PageSize,
+		"total":     total,
+	})
+}
@@ -6,9 +6,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
 	"github.com/joaooliveira247/go_olist_challenge/src/dto"
 	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
 	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
 	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
 	"github.com/joaooliveira247/go_olist_challenge/src/response"
 )
@@ -21,7 +23,15 @@ func NewAuthorController(repo repositories.AuthorRepository) *AuthorController {
 	return &AuthorController{repo}
 }
 
+func (ctrl *AuthorController) withContext(ctx *gin.Context) repositories.AuthorRepository {
+	return ctrl.repository.WithContext(ctx.Request.Context())
+}
+
 func (ctrl *AuthorController) CreateAuthor(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.CreateAuthor) {
+		return
+	}
+
 	var author models.Author
 
 	if err := ctx.ShouldBindJSON(&author); err != nil {
@@ -29,7 +39,7 @@ func (ctrl *AuthorController) CreateAuthor(ctx *gin.Context) {
 		return
 	}
 
-	id, err := ctrl.repository.Create(&author)
+	id, err := ctrl.withContext(ctx).Create(&author)
 
 	if err != nil {
 		if errors.Is(err, &custom.AuthorAlreadyExists) {
@@ -44,7 +54,30 @@ func (ctrl *AuthorController) CreateAuthor(ctx *gin.Context) {
 	return
 }
 
+func (ctrl *AuthorController) CreateAuthors(ctx *gin.Context) {
+	authors := ctrl.withContext(ctx)
+
+	serveBulk(ctx, bulkCreate[models.Author]{
+		createAll: func(items []models.Author) ([]uuid.UUID, error) {
+			return authors.CreateMany(&items)
+		},
+		createOne: func(item models.Author) (uuid.UUID, error) {
+			return authors.Create(&item)
+		},
+		failure: func(err error) response.Response {
+			if errors.Is(err, &custom.AuthorAlreadyExists) {
+				return response.AuthorAlreadyExists
+			}
+			return response.UnableCreateEntity
+		},
+	})
+}
+
 func (ctrl *AuthorController) GetAuthors(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCatalog) {
+		return
+	}
+
 	// so codar os tests
 	var params dto.AuthorQueryParams
 
@@ -53,6 +86,16 @@ func (ctrl *AuthorController) GetAuthors(ctx *gin.Context) {
 		return
 	}
 
+	format := listFormat(ctx, params.Format)
+	authors := ctrl.withContext(ctx)
+
+	if params.IncludeDeleted {
+		if !policies.Authorize(ctx, policies.ReadDeleted) {
+			return
+		}
+		authors = authors.Unscoped()
+	}
+
 	if params.ID != "" {
 		id, err := uuid.Parse(params.ID)
 
@@ -61,38 +104,124 @@ func (ctrl *AuthorController) GetAuthors(ctx *gin.Context) {
 			return
 		}
 
-		author, err := ctrl.repository.GetByID(id)
+		author, err := authors.GetByID(id)
 
 		if err != nil {
 			ctx.JSON(response.AuthorNotFound.StatusCode, response.AuthorNotFound.Message)
 			return
 		}
+
+		if format != dto.FormatJSON {
+			respondAuthors(ctx, format, []models.Author{author})
+			return
+		}
+
 		ctx.JSON(http.StatusOK, author)
 		return
 	}
 
+	if params.Nationality != "" {
+		authors = authors.WithNationality(params.Nationality)
+	}
+
+	if params.Name != "" && params.Match == dto.MatchSubstring {
+		if format != dto.FormatJSON {
+			streamAuthors(ctx, format, func(yield func(models.Author) error) error {
+				return authors.EachByName(params.Name, yield)
+			})
+			return
+		}
+
+		found, err := authors.GetByName(params.Name)
+
+		if err != nil {
+			ctx.JSON(response.AuthorNotFound.StatusCode, response.AuthorNotFound.Message)
+			return
+		}
+
+		respondAuthors(ctx, format, found)
+		return
+	}
+
 	if params.Name != "" {
-		authors, err := ctrl.repository.GetByName(params.Name)
+		found, err := authors.GetBySimilarName(params.Name, config.AuthorSimilarityThreshold)
 
 		if err != nil {
 			ctx.JSON(response.AuthorNotFound.StatusCode, response.AuthorNotFound.Message)
 			return
 		}
 
-		ctx.JSON(http.StatusOK, authors)
+		respondAuthorMatches(ctx, format, found)
+		return
+	}
+
+	if format != dto.FormatJSON {
+		streamAuthors(ctx, format, authors.Each)
 		return
 	}
 
-	authors, err := ctrl.repository.GetAll()
+	found, err := authors.GetAll()
 
 	if err != nil {
 		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
 		return
 	}
-	ctx.JSON(http.StatusOK, authors)
+	respondAuthors(ctx, format, found)
+}
+
+func (ctrl *AuthorController) UpdateAuthor(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.UpdateAuthor) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	var author models.AuthorUpdate
+
+	if err := ctx.ShouldBindJSON(&author); err != nil {
+		ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+		return
+	}
+
+	if author.IsEmpty() {
+		ctx.JSON(response.NothingToUpdate.StatusCode, nil)
+		return
+	}
+
+	if err := ctrl.withContext(ctx).Update(id, &author); err != nil {
+		if errors.Is(err, &custom.AuthorNotFound) {
+			ctx.JSON(response.AuthorNotFound.StatusCode, response.AuthorNotFound.Message)
+			return
+		}
+		if errors.Is(err, &custom.AuthorAlreadyExists) {
+			ctx.JSON(response.AuthorAlreadyExists.StatusCode, response.AuthorAlreadyExists.Message)
+			return
+		}
+		if errors.Is(err, &custom.InvalidAuthorYears) {
+			ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+			return
+		}
+		if errors.Is(err, &custom.AuthorNothingToUpdate) {
+			ctx.JSON(response.NothingToUpdate.StatusCode, nil)
+			return
+		}
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
 }
 
 func (ctrl *AuthorController) DeleteAuthor(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.DeleteAuthor) {
+		return
+	}
+
 	id, err := uuid.Parse(ctx.Param("id"))
 
 	if err != nil {
@@ -100,7 +229,7 @@ func (ctrl *AuthorController) DeleteAuthor(ctx *gin.Context) {
 		return
 	}
 
-	if err = ctrl.repository.Delete(id); err != nil {
+	if err = ctrl.withContext(ctx).Delete(id); err != nil {
 		if errors.Is(err, &custom.AuthorNotFound) {
 			ctx.JSON(response.AuthorNotFound.StatusCode, response.AuthorNotFound.Message)
 			return
@@ -111,3 +240,31 @@ func (ctrl *AuthorController) DeleteAuthor(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+func (ctrl *AuthorController) RestoreAuthor(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.RestoreAuthor) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	if err = ctrl.withContext(ctx).Restore(id); err != nil {
+		if errors.Is(err, &custom.AuthorNotFound) {
+			ctx.JSON(response.AuthorNotFound.StatusCode, response.AuthorNotFound.Message)
+			return
+		}
+		if errors.Is(err, &custom.AuthorAlreadyExists) {
+			ctx.JSON(response.AuthorAlreadyExists.StatusCode, response.AuthorAlreadyExists.Message)
+			return
+		}
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
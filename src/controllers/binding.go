@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+// invalidBody answers the response to a body that failed binding. A list
+// repeating an entry answers 400, as each entry becomes a row keyed by it.
+func invalidBody(err error) response.Response {
+	var validationErrors validator.ValidationErrors
+
+	if errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			if fieldError.Tag() == "unique" {
+				return response.DuplicateEntries
+			}
+		}
+	}
+
+	return response.InvalidRequestBody
+}
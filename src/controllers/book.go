@@ -9,48 +9,143 @@ import (
 	"github.com/google/uuid"
 	"github.com/joaooliveira247/go_olist_challenge/src/dto"
 	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/isbn"
+	"github.com/joaooliveira247/go_olist_challenge/src/language"
 	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
 	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
 	"github.com/joaooliveira247/go_olist_challenge/src/response"
 )
 
 type BookController struct {
-	bookRepository       repositories.BookRepository
-	bookAuthorRepository repositories.BookAuthorRepository
+	bookRepository repositories.BookRepository
 }
 
-func NewBookController(bookRepo repositories.BookRepository, bookAuthorRepo repositories.BookAuthorRepository) *BookController {
-	return &BookController{bookRepo, bookAuthorRepo}
+func NewBookController(bookRepo repositories.BookRepository) *BookController {
+	return &BookController{bookRepo}
+}
+
+func (controller *BookController) books(ctx *gin.Context) repositories.BookRepository {
+	return controller.bookRepository.WithContext(ctx.Request.Context())
 }
 
 func (controller *BookController) Create(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.CreateBook) {
+		return
+	}
+
 	var book models.BookIn
 
 	if err := ctx.ShouldBindJSON(&book); err != nil {
-		ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+		failure := invalidBody(err)
+		ctx.JSON(failure.StatusCode, failure.Message)
 		fmt.Println(err)
 		return
 	}
 
-	bookID, err := controller.bookRepository.Create(&book.Book)
+	bookID, err := controller.books(ctx).Create(&book)
 
 	if err != nil {
-		ctx.JSON(response.UnableCreateEntity.StatusCode, response.UnableCreateEntity.Message)
+		failure := createFailure(err)
+		ctx.JSON(failure.StatusCode, failure.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": bookID})
+	return
+}
+
+func (controller *BookController) CreateBooks(ctx *gin.Context) {
+	books := controller.books(ctx)
+
+	serveBulk(ctx, bulkCreate[models.BookIn]{
+		createAll: books.CreateMany,
+		createOne: func(item models.BookIn) (uuid.UUID, error) {
+			ids, err := books.CreateMany([]models.BookIn{item})
+
+			if err != nil {
+				return uuid.Nil, err
+			}
+			return ids[0], nil
+		},
+		failure: createFailure,
+	})
+}
+
+// createFailure answers the response for an error creating a book with its
+// authors and genres.
+func createFailure(err error) response.Response {
+	if errors.Is(err, &custom.BookAlreadyExists) {
+		return response.BookAlreadyExists
+	}
+	if errors.Is(err, &custom.AuthorNotFound) {
+		return response.AuthorNotFound
+	}
+	if errors.Is(err, &custom.PublisherNotFound) {
+		return response.PublisherNotFound
+	}
+	if errors.Is(err, &custom.GenreNotFound) {
+		return response.GenreNotFound
+	}
+	if errors.Is(err, &custom.WorkNotFound) {
+		return response.WorkNotFound
+	}
+	if errors.Is(err, &custom.OriginalBookNotFound) {
+		return response.OriginalBookNotFound
+	}
+	return response.UnableCreateEntity
+}
+
+func (controller *BookController) CreateEdition(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.CreateBook) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	var edition models.EditionIn
+
+	if err := ctx.ShouldBindJSON(&edition); err != nil {
+		ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
 		return
 	}
 
-	for _, author := range book.AuthorsID {
-		if err := controller.bookAuthorRepository.Create(&models.BookAuthor{BookID: bookID, AuthorID: author}); err != nil {
-			ctx.JSON(response.UnableCreateEntity.StatusCode, response.UnableCreateEntity.Message)
+	bookID, err := controller.books(ctx).CreateEdition(id, &edition)
+
+	if err != nil {
+		if errors.Is(err, &custom.BookNotFound) {
+			ctx.JSON(response.BookNotFound.StatusCode, response.BookNotFound.Message)
+			return
+		}
+		if errors.Is(err, &custom.EditionAlreadyExists) {
+			ctx.JSON(response.EditionAlreadyExists.StatusCode, response.EditionAlreadyExists.Message)
+			return
+		}
+		if errors.Is(err, &custom.BookAlreadyExists) {
+			ctx.JSON(response.BookAlreadyExists.StatusCode, response.BookAlreadyExists.Message)
+			return
+		}
+		if errors.Is(err, &custom.PublisherNotFound) {
+			ctx.JSON(response.PublisherNotFound.StatusCode, response.PublisherNotFound.Message)
 			return
 		}
+		ctx.JSON(response.UnableCreateEntity.StatusCode, response.UnableCreateEntity.Message)
+		return
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{"id": bookID})
-	return
 }
 
 func (controller *BookController) GetBooks(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCatalog) {
+		return
+	}
+
 	var bookQuery dto.BookQueryParams
 
 	if err := ctx.ShouldBindQuery(&bookQuery); err != nil {
@@ -58,6 +153,16 @@ func (controller *BookController) GetBooks(ctx *gin.Context) {
 		return
 	}
 
+	format := listFormat(ctx, bookQuery.Format)
+	books := controller.books(ctx)
+
+	if bookQuery.IncludeDeleted {
+		if !policies.Authorize(ctx, policies.ReadDeleted) {
+			return
+		}
+		books = books.Unscoped()
+	}
+
 	if bookQuery.BookID != "" {
 		bookID, err := uuid.Parse(bookQuery.BookID)
 
@@ -66,16 +171,66 @@ func (controller *BookController) GetBooks(ctx *gin.Context) {
 			return
 		}
 
-		book, err := controller.bookRepository.GetBookByID(bookID)
+		book, err := books.GetBookByID(bookID)
+
+		if err != nil {
+			ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+			return
+		}
+
+		if format != dto.FormatJSON {
+			respondBooks(ctx, format, []models.BookOut{book})
+			return
+		}
+
+		body, etag, err := bookBody(book)
 
 		if err != nil {
 			ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
 			return
 		}
-		ctx.JSON(http.StatusOK, book)
+
+		ctx.Header("ETag", etag)
+
+		if matchesIfNoneMatch(ctx.GetHeader("If-None-Match"), etag) {
+			ctx.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+
+		ctx.Data(http.StatusOK, "application/json; charset=utf-8", body)
 		return
 	}
 
+	if bookQuery.ISBN != "" {
+		normalized, ok := isbn.Normalize(bookQuery.ISBN)
+
+		if !ok {
+			ctx.JSON(response.InvalidParam.StatusCode, response.InvalidParam.Message)
+			return
+		}
+		bookQuery.ISBN = normalized
+	}
+
+	if bookQuery.Language != "" {
+		normalized, ok := language.Normalize(bookQuery.Language)
+
+		if !ok {
+			ctx.JSON(response.InvalidParam.StatusCode, response.InvalidParam.Message)
+			return
+		}
+		bookQuery.Language = normalized
+	}
+
+	if bookQuery.PublisherID != "" {
+		publisherID, err := uuid.Parse(bookQuery.PublisherID)
+
+		if err != nil || publisherID == uuid.Nil {
+			ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+			return
+		}
+		bookQuery.PublisherID = publisherID.String()
+	}
+
 	if bookQuery.AuthorID != "" {
 		authorID, err := uuid.Parse(bookQuery.AuthorID)
 
@@ -84,38 +239,99 @@ func (controller *BookController) GetBooks(ctx *gin.Context) {
 			return
 		}
 
-		books, err := controller.bookRepository.GetBooksByAuthorID(authorID)
+		if format != dto.FormatJSON {
+			streamBooks(ctx, format, func(yield func(models.BookOut) error) error {
+				return books.EachBookByAuthorID(authorID, yield)
+			})
+			return
+		}
+
+		found, err := books.GetBooksByAuthorID(authorID)
 
 		if err != nil {
 			ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
 			return
 		}
-		ctx.JSON(http.StatusOK, books)
+		respondBooks(ctx, format, found)
+		return
+	}
+
+	if format != dto.FormatJSON {
+		query, args := bookQuery.AsQuery()
+
+		streamBooks(ctx, format, func(yield func(models.BookOut) error) error {
+			return books.EachBook(query, args, yield)
+		})
 		return
 	}
 
 	if !bookQuery.IsEmpty() {
-		books, err := controller.bookRepository.GetBookByQuery(bookQuery.AsQuery())
+		found, err := books.GetBookByQuery(bookQuery.AsQuery())
 
 		if err != nil {
 			ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
 			return
 		}
-		ctx.JSON(http.StatusOK, books)
+		respondBooks(ctx, format, found)
 		return
 	}
 
-	books, err := controller.bookRepository.GetAll()
+	found, err := books.GetAll()
 
 	if err != nil {
 		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
 		return
 	}
-	ctx.JSON(http.StatusOK, books)
-	return
+	respondBooks(ctx, format, found)
+}
+
+func (controller *BookController) GetTranslations(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCatalog) {
+		return
+	}
+
+	var params dto.FormatQueryParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(response.InvalidParam.StatusCode, response.InvalidParam.Message)
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	if _, err := controller.books(ctx).GetBookByID(id); err != nil {
+		if errors.Is(err, &custom.BookNotFound) {
+			ctx.JSON(response.BookNotFound.StatusCode, response.BookNotFound.Message)
+			return
+		}
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	translations, err := controller.books(ctx).GetTranslations(id)
+
+	if err != nil {
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	if translations == nil {
+		translations = []models.BookOut{}
+	}
+
+	respondBooks(ctx, listFormat(ctx, params.Format), translations)
 }
 
 func (controller *BookController) UpdateBook(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.UpdateBook) {
+		return
+	}
+
 	id, err := uuid.Parse(ctx.Param("id"))
 
 	if err != nil || id == uuid.Nil {
@@ -126,42 +342,99 @@ func (controller *BookController) UpdateBook(ctx *gin.Context) {
 	var bookUpdate models.BookUpdate
 
 	if err := ctx.ShouldBindBodyWithJSON(&bookUpdate); err != nil {
-		ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+		failure := invalidBody(err)
+		ctx.JSON(failure.StatusCode, failure.Message)
+		return
+	}
+
+	version, ok := controller.expectedVersion(ctx, id)
+
+	if !ok {
 		return
 	}
 
-	if !bookUpdate.IsEmpty() {
-		if err := controller.bookRepository.Update(id, &bookUpdate); err != nil {
+	if !bookUpdate.IsEmpty() || len(bookUpdate.BookAuthors(id)) > 0 || len(bookUpdate.GenresID) > 0 {
+		if err := controller.books(ctx).Update(id, &bookUpdate, version); err != nil {
 			if errors.Is(err, &custom.BookNothingToUpdate) {
 				ctx.JSON(response.NothingToUpdate.StatusCode, nil)
 				return
 			}
+			if errors.Is(err, &custom.BookVersionMismatch) {
+				ctx.JSON(response.PreconditionFailed.StatusCode, response.PreconditionFailed.Message)
+				return
+			}
+			if errors.Is(err, &custom.BookAlreadyExists) {
+				ctx.JSON(response.BookAlreadyExists.StatusCode, response.BookAlreadyExists.Message)
+				return
+			}
+			if errors.Is(err, &custom.PublisherNotFound) {
+				ctx.JSON(response.PublisherNotFound.StatusCode, response.PublisherNotFound.Message)
+				return
+			}
+			if errors.Is(err, &custom.OriginalBookNotFound) {
+				ctx.JSON(response.OriginalBookNotFound.StatusCode, response.OriginalBookNotFound.Message)
+				return
+			}
+			if errors.Is(err, &custom.InvalidTranslation) {
+				ctx.JSON(response.InvalidTranslation.StatusCode, response.InvalidTranslation.Message)
+				return
+			}
+			if errors.Is(err, &custom.AuthorNotFound) {
+				ctx.JSON(response.AuthorNotFound.StatusCode, response.AuthorNotFound.Message)
+				return
+			}
+			if errors.Is(err, &custom.GenreNotFound) {
+				ctx.JSON(response.GenreNotFound.StatusCode, response.GenreNotFound.Message)
+				return
+			}
 			ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
 			return
 		}
 	}
 
-	if len(bookUpdate.AuthorsID) > 0 {
-		if err := controller.bookAuthorRepository.Delete(id); err != nil {
-			ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+	ctx.JSON(http.StatusNoContent, nil)
+	return
+}
+
+func (controller *BookController) DeleteBook(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.DeleteBook) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	version, ok := controller.expectedVersion(ctx, id)
+
+	if !ok {
+		return
+	}
+
+	if err := controller.books(ctx).Delete(id, version); err != nil {
+		if errors.Is(err, &custom.BookNotFound) {
+			ctx.JSON(response.NothingToDelete.StatusCode, nil)
 			return
 		}
-
-		for _, authorID := range bookUpdate.AuthorsID {
-			if err := controller.bookAuthorRepository.Create(&models.BookAuthor{
-				BookID: id, AuthorID: authorID,
-			}); err != nil {
-				ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
-				return
-			}
+		if errors.Is(err, &custom.BookVersionMismatch) {
+			ctx.JSON(response.PreconditionFailed.StatusCode, response.PreconditionFailed.Message)
+			return
 		}
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
 	}
-
 	ctx.JSON(http.StatusNoContent, nil)
 	return
 }
 
-func (controller *BookController) DeleteBook(ctx *gin.Context) {
+func (controller *BookController) RestoreBook(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.RestoreBook) {
+		return
+	}
+
 	id, err := uuid.Parse(ctx.Param("id"))
 
 	if err != nil || id == uuid.Nil {
@@ -169,14 +442,18 @@ func (controller *BookController) DeleteBook(ctx *gin.Context) {
 		return
 	}
 
-	if err := controller.bookRepository.Delete(id); err != nil {
+	if err := controller.books(ctx).Restore(id); err != nil {
 		if errors.Is(err, &custom.BookNotFound) {
-			ctx.JSON(response.NothingToDelete.StatusCode, nil)
+			ctx.JSON(response.BookNotFound.StatusCode, response.BookNotFound.Message)
+			return
+		}
+		if errors.Is(err, &custom.BookAlreadyExists) {
+			ctx.JSON(response.BookAlreadyExists.StatusCode, response.BookAlreadyExists.Message)
 			return
 		}
 		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
 		return
 	}
+
 	ctx.JSON(http.StatusNoContent, nil)
-	return
 }
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+// bulkCreate describes how to create a batch of items: createAll runs them in
+// one transaction for atomic mode and createOne runs them one at a time for
+// partial mode. failure maps a repository error to its response.
+type bulkCreate[T any] struct {
+	createAll func(items []T) ([]uuid.UUID, error)
+	createOne func(item T) (uuid.UUID, error)
+	failure   func(err error) response.Response
+}
+
+func serveBulk[T any](ctx *gin.Context, bulk bulkCreate[T]) {
+	if !policies.Authorize(ctx, policies.BulkCreate) {
+		return
+	}
+
+	var params dto.BulkQueryParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(response.InvalidParam.StatusCode, response.InvalidParam.Message)
+		return
+	}
+
+	items, err := decodeBulk[T](ctx.Request.Body, config.BulkMaxItems)
+
+	if errors.Is(err, errTooManyItems) {
+		ctx.JSON(response.TooManyItems.StatusCode, response.TooManyItems.Message)
+		return
+	}
+
+	if err != nil || len(items) < 1 {
+		ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+		return
+	}
+
+	results := make([]models.BulkResult, len(items))
+	valid := true
+
+	for i := range items {
+		results[i].Index = i
+
+		if err := binding.Validator.ValidateStruct(&items[i]); err != nil {
+			results[i] = bulkFailure(i, invalidBody(err))
+			valid = false
+		}
+	}
+
+	if params.Mode == dto.BulkPartial {
+		for i, item := range items {
+			if results[i].Status != 0 {
+				continue
+			}
+
+			id, err := bulk.createOne(item)
+
+			if err != nil {
+				results[i] = bulkFailure(i, bulk.failure(err))
+				continue
+			}
+
+			results[i] = models.BulkResult{Index: i, Status: http.StatusCreated, ID: &id}
+		}
+
+		ctx.JSON(http.StatusMultiStatus, gin.H{"items": results})
+		return
+	}
+
+	if !valid {
+		ctx.JSON(response.InvalidRequestBody.StatusCode, gin.H{
+			"message": response.InvalidRequestBody.Message["message"],
+			"items":   invalidItems(results),
+		})
+		return
+	}
+
+	ids, err := bulk.createAll(items)
+
+	if err != nil {
+		failure := bulk.failure(err)
+		ctx.JSON(failure.StatusCode, failure.Message)
+		return
+	}
+
+	for i := range ids {
+		results[i] = models.BulkResult{Index: i, Status: http.StatusCreated, ID: &ids[i]}
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"items": results})
+}
+
+var (
+	errNotArray     = errors.New("bulk body is not a JSON array")
+	errTooManyItems = errors.New("bulk body has too many items")
+)
+
+// decodeBulk reads the JSON array of body one item at a time and gives up as
+// soon as it holds more than limit, so an oversized batch is never loaded
+// whole.
+func decodeBulk[T any](body io.Reader, limit int) ([]T, error) {
+	decoder := json.NewDecoder(body)
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errNotArray
+	}
+
+	var items []T
+
+	for decoder.More() {
+		if len(items) == limit {
+			return nil, errTooManyItems
+		}
+
+		var item T
+
+		if err := decoder.Decode(&item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func bulkFailure(index int, failure response.Response) models.BulkResult {
+	message, _ := failure.Message["message"].(string)
+	return models.BulkResult{Index: index, Status: failure.StatusCode, Error: message}
+}
+
+func invalidItems(results []models.BulkResult) []models.BulkResult {
+	invalid := []models.BulkResult{}
+
+	for _, result := range results {
+		if result.Status != 0 {
+			invalid = append(invalid, result)
+		}
+	}
+
+	return invalid
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+)
+
+type CacheController struct {
+	cache *cache.Cache
+}
+
+func NewCacheController(readCache *cache.Cache) *CacheController {
+	return &CacheController{readCache}
+}
+
+func (ctrl *CacheController) GetStats(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCacheStats) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctrl.cache.Stats())
+}
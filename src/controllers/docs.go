@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DocsController struct {
+	spec   []byte
+	page   string
+	assets http.FileSystem
+}
+
+func NewDocsController(spec []byte, page string, assets fs.FS) *DocsController {
+	return &DocsController{spec, page, http.FS(assets)}
+}
+
+func (controller *DocsController) GetSpec(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", controller.spec)
+}
+
+func (controller *DocsController) GetUI(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(controller.page))
+}
+
+func (controller *DocsController) GetAsset(ctx *gin.Context) {
+	ctx.FileFromFS(ctx.Param("file"), controller.assets)
+}
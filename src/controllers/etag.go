@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+// bookBody serializes the book as GET /books/?bookID answers it, along with a
+// strong ETag hashed from those bytes. The ETag changes whenever the response
+// does, including when a contributor is renamed or the publisher deleted
+// without the book itself being updated.
+func bookBody(book models.BookOut) ([]byte, string, error) {
+	body, err := json.Marshal(book)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	sum := sha256.Sum256(body)
+
+	return body, `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+func parseETags(header string) []string {
+	var tags []string
+
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+func matchesIfNoneMatch(header string, etag string) bool {
+	for _, tag := range parseETags(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func (controller *BookController) expectedVersion(ctx *gin.Context, id uuid.UUID) (uint, bool) {
+	header := ctx.GetHeader("If-Match")
+
+	if header == "" {
+		if config.RequireIfMatch {
+			ctx.JSON(response.PreconditionRequired.StatusCode, response.PreconditionRequired.Message)
+			return 0, false
+		}
+		return 0, true
+	}
+
+	var tags []string
+
+	for _, tag := range parseETags(header) {
+		if tag == "*" {
+			return 0, true
+		}
+
+		if !strings.HasPrefix(tag, "W/") {
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(tags) > 0 {
+		book, err := controller.books(ctx).GetBookByID(id)
+
+		if err != nil && !errors.Is(err, &custom.BookNotFound) {
+			ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+			return 0, false
+		}
+
+		if err == nil {
+			_, etag, err := bookBody(book)
+
+			if err != nil {
+				ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+				return 0, false
+			}
+
+			// The version read with the ETag makes the write fail if the
+			// book changes before it is locked.
+			if slices.Contains(tags, etag) {
+				return book.Version, true
+			}
+		}
+	}
+
+	ctx.JSON(response.PreconditionFailed.StatusCode, response.PreconditionFailed.Message)
+	return 0, false
+}
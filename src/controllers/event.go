@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	"github.com/joaooliveira247/go_olist_challenge/src/events"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+// heartbeatInterval is how often an idle stream sends a comment, so that
+// proxies keep it open and gone clients are noticed.
+const heartbeatInterval = 15 * time.Second
+
+// replayPageSize is how many missed events are read at a time on resume.
+const replayPageSize = 500
+
+type EventController struct {
+	broker     *events.Broker
+	repository repositories.EventRepository
+}
+
+func NewEventController(broker *events.Broker, repo repositories.EventRepository) *EventController {
+	return &EventController{broker, repo}
+}
+
+// StreamEvents streams the catalog changes as Server-Sent Events, each with
+// the outbox ID of the event as its id. A client reconnecting with
+// Last-Event-ID first receives the events it missed.
+func (ctrl *EventController) StreamEvents(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCatalog) {
+		return
+	}
+
+	var params dto.EventQueryParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(response.InvalidParam.StatusCode, response.InvalidParam.Message)
+		return
+	}
+
+	var (
+		sent   uint64
+		resume bool
+	)
+
+	if header := ctx.GetHeader("Last-Event-ID"); header != "" {
+		last, err := strconv.ParseUint(header, 10, 64)
+
+		if err != nil {
+			ctx.JSON(response.InvalidLastEventID.StatusCode, response.InvalidLastEventID.Message)
+			return
+		}
+
+		sent, resume = last, true
+	}
+
+	subscription, err := ctrl.broker.Subscribe(ctx.Request.Context())
+
+	if err != nil {
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	defer subscription.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	if resume {
+		repository := ctrl.repository.WithContext(ctx.Request.Context())
+
+		for sent < subscription.From {
+			missed, err := repository.GetAfter(sent, subscription.From, params.Entity, replayPageSize)
+
+			if err != nil {
+				return
+			}
+
+			for _, event := range missed {
+				if !writeEvent(ctx, event) {
+					return
+				}
+				sent = event.ID
+			}
+
+			if len(missed) < replayPageSize {
+				break
+			}
+		}
+	}
+
+	sent = max(sent, subscription.From)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := ctx.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+
+			if event.ID <= sent {
+				continue
+			}
+			sent = event.ID
+
+			if len(params.Entity) > 0 && !slices.Contains(params.Entity, eventEntityOf(event)) {
+				continue
+			}
+
+			if !writeEvent(ctx, event) {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(ctx *gin.Context, event models.OutboxEvent) bool {
+	err := sse.Encode(ctx.Writer, sse.Event{Id: strconv.FormatUint(event.ID, 10), Event: event.Type, Data: event})
+
+	if err != nil {
+		return false
+	}
+
+	ctx.Writer.Flush()
+
+	return true
+}
+
+// eventEntityOf answers the entity an event is about, author in
+// author.created.
+func eventEntityOf(event models.OutboxEvent) string {
+	entity, _, _ := strings.Cut(event.Type, ".")
+	return entity
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+	"github.com/joaooliveira247/go_olist_challenge/src/utils"
+)
+
+const (
+	mimeJSON   = "application/json"
+	mimeCSV    = "text/csv"
+	mimeNDJSON = "application/x-ndjson"
+)
+
+// rowsPerFlush is how many rows are written before they are flushed to the
+// client.
+const rowsPerFlush = 100
+
+// listFormat answers the format a listing is answered in: the ?format= override
+// when given, else the one preferred by Accept, JSON by default.
+func listFormat(ctx *gin.Context, override string) string {
+	if override != "" {
+		return override
+	}
+
+	switch ctx.NegotiateFormat(mimeJSON, mimeCSV, mimeNDJSON) {
+	case mimeCSV:
+		return dto.FormatCSV
+	case mimeNDJSON:
+		return dto.FormatNDJSON
+	default:
+		return dto.FormatJSON
+	}
+}
+
+// respondList answers items in format.
+func respondList[T any](ctx *gin.Context, format string, name string, items []T, columns []string, record func(T) []string) {
+	if format == dto.FormatJSON {
+		ctx.JSON(http.StatusOK, items)
+		return
+	}
+
+	streamList(ctx, format, name, columns, record, func(yield func(T) error) error {
+		for _, item := range items {
+			if err := yield(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// streamList answers the rows each yields in the CSV or NDJSON format, writing
+// one record per row as it arrives and flushing them every rowsPerFlush rows,
+// so an export of any size is never held in memory. An error before the first
+// row answers UnableFetchEntity; after it the response is cut short, as its
+// status is already sent.
+func streamList[T any](ctx *gin.Context, format string, name string, columns []string, record func(T) []string, each func(yield func(T) error) error) {
+	var (
+		rows  int
+		write func(T) error
+		flush func() error
+	)
+
+	start := func() {
+		switch format {
+		case dto.FormatCSV:
+			ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+			ctx.Header("Content-Type", mimeCSV+"; charset=utf-8")
+			ctx.Status(http.StatusOK)
+
+			writer := csv.NewWriter(ctx.Writer)
+			writer.Write(columns)
+
+			write = func(item T) error { return writer.Write(record(item)) }
+			flush = func() error {
+				writer.Flush()
+				return writer.Error()
+			}
+		default:
+			ctx.Header("Content-Type", mimeNDJSON)
+			ctx.Status(http.StatusOK)
+
+			encoder := json.NewEncoder(ctx.Writer)
+
+			write = func(item T) error { return encoder.Encode(item) }
+			flush = func() error { return nil }
+		}
+	}
+
+	err := each(func(item T) error {
+		if write == nil {
+			start()
+		}
+
+		if err := write(item); err != nil {
+			return err
+		}
+
+		if rows++; rows%rowsPerFlush == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+		}
+		return nil
+	})
+
+	if write == nil {
+		if err != nil {
+			ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+			return
+		}
+		start()
+	}
+
+	flush()
+}
+
+func respondBooks(ctx *gin.Context, format string, books []models.BookOut) {
+	respondList(ctx, format, "books", books, utils.BookExportColumns, utils.BookRecord)
+}
+
+func streamBooks(ctx *gin.Context, format string, each func(yield func(models.BookOut) error) error) {
+	streamList(ctx, format, "books", utils.BookExportColumns, utils.BookRecord, each)
+}
+
+func respondAuthors(ctx *gin.Context, format string, authors []models.Author) {
+	respondList(ctx, format, "authors", authors, utils.AuthorExportColumns, utils.AuthorRecord)
+}
+
+func streamAuthors(ctx *gin.Context, format string, each func(yield func(models.Author) error) error) {
+	streamList(ctx, format, "authors", utils.AuthorExportColumns, utils.AuthorRecord, each)
+}
+
+// respondAuthorMatches answers authors matched by name with their similarity as
+// the last column.
+func respondAuthorMatches(ctx *gin.Context, format string, matches []models.AuthorMatch) {
+	columns := append(append([]string{}, utils.AuthorExportColumns...), "similarity")
+
+	respondList(ctx, format, "authors", matches, columns, func(match models.AuthorMatch) []string {
+		return append(utils.AuthorRecord(match.Author), fmt.Sprintf("%g", match.Similarity))
+	})
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	"github.com/joaooliveira247/go_olist_challenge/src/gql"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+type GraphQLController struct {
+	schema *gql.Schema
+}
+
+func NewGraphQLController(schema *gql.Schema) *GraphQLController {
+	return &GraphQLController{schema}
+}
+
+// Query runs a GraphQL query. Errors while resolving fields are reported in
+// the errors of the result, next to the data that could be resolved.
+func (ctrl *GraphQLController) Query(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCatalog) {
+		return
+	}
+
+	var request dto.GraphQLRequest
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctrl.schema.Execute(ctx.Request.Context(), request))
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+// NamedController serves the CRUD routes of the entities that only carry a
+// unique name. Each one brings the policies guarding its writes and the
+// responses naming it when it is missing or taken.
+type NamedController[T any] struct {
+	repository    repositories.NamedRepository[T]
+	create        policies.Action
+	update        policies.Action
+	delete        policies.Action
+	alreadyExists response.Response
+	notFound      response.Response
+}
+
+type PublisherController = NamedController[models.Publisher]
+
+type GenreController = NamedController[models.Genre]
+
+func NewPublisherController(repo repositories.PublisherRepository) *PublisherController {
+	return &PublisherController{
+		repo,
+		policies.CreatePublisher,
+		policies.UpdatePublisher,
+		policies.DeletePublisher,
+		response.PublisherAlreadyExists,
+		response.PublisherNotFound,
+	}
+}
+
+func NewGenreController(repo repositories.GenreRepository) *GenreController {
+	return &GenreController{
+		repo,
+		policies.CreateGenre,
+		policies.UpdateGenre,
+		policies.DeleteGenre,
+		response.GenreAlreadyExists,
+		response.GenreNotFound,
+	}
+}
+
+func (ctrl *NamedController[T]) withContext(ctx *gin.Context) repositories.NamedRepository[T] {
+	return ctrl.repository.WithContext(ctx.Request.Context())
+}
+
+// failure answers the NotFound and AlreadyExists the repository reports for
+// its own entity, and fallback for anything else.
+func (ctrl *NamedController[T]) failure(err error, fallback response.Response) response.Response {
+	var (
+		notFound      *custom.NotFound
+		alreadyExists *custom.AlreadyExists
+	)
+
+	if errors.As(err, &notFound) {
+		return ctrl.notFound
+	}
+	if errors.As(err, &alreadyExists) {
+		return ctrl.alreadyExists
+	}
+	return fallback
+}
+
+func (ctrl *NamedController[T]) Create(ctx *gin.Context) {
+	if !policies.Authorize(ctx, ctrl.create) {
+		return
+	}
+
+	var entity T
+
+	if err := ctx.ShouldBindJSON(&entity); err != nil {
+		ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+		return
+	}
+
+	id, err := ctrl.withContext(ctx).Create(&entity)
+
+	if err != nil {
+		failure := ctrl.failure(err, response.UnableCreateEntity)
+		ctx.JSON(failure.StatusCode, failure.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (ctrl *NamedController[T]) GetAll(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCatalog) {
+		return
+	}
+
+	var params dto.NameQueryParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(response.InvalidParam.StatusCode, response.InvalidParam.Message)
+		return
+	}
+
+	repository := ctrl.withContext(ctx)
+
+	var (
+		found []T
+		err   error
+	)
+
+	if params.Name != "" {
+		found, err = repository.GetByName(params.Name)
+	} else {
+		found, err = repository.GetAll()
+	}
+
+	if err != nil {
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, found)
+}
+
+func (ctrl *NamedController[T]) Get(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCatalog) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	entity, err := ctrl.withContext(ctx).GetByID(id)
+
+	if err != nil {
+		failure := ctrl.failure(err, response.UnableFetchEntity)
+		ctx.JSON(failure.StatusCode, failure.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entity)
+}
+
+func (ctrl *NamedController[T]) Update(ctx *gin.Context) {
+	if !policies.Authorize(ctx, ctrl.update) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	var entity T
+
+	if err := ctx.ShouldBindJSON(&entity); err != nil {
+		ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+		return
+	}
+
+	if err := ctrl.withContext(ctx).Update(id, &entity); err != nil {
+		failure := ctrl.failure(err, response.UnableFetchEntity)
+		ctx.JSON(failure.StatusCode, failure.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (ctrl *NamedController[T]) Delete(ctx *gin.Context) {
+	if !policies.Authorize(ctx, ctrl.delete) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	if err := ctrl.withContext(ctx).Delete(id); err != nil {
+		failure := ctrl.failure(err, response.UnableFetchEntity)
+		ctx.JSON(failure.StatusCode, failure.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+type SearchController struct {
+	repository repositories.SearchRepository
+}
+
+func NewSearchController(repo repositories.SearchRepository) *SearchController {
+	return &SearchController{repo}
+}
+
+func (ctrl *SearchController) Search(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCatalog) {
+		return
+	}
+
+	var params dto.SearchQueryParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil || strings.TrimSpace(params.Query) == "" {
+		ctx.JSON(response.InvalidParam.StatusCode, response.InvalidParam.Message)
+		return
+	}
+
+	results, total, err := ctrl.repository.WithContext(ctx.Request.Context()).SearchBooks(params.Query, params.Page, params.PageSize)
+
+	if err != nil {
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"items":     results,
+		"page":      params.Page,
+		"page_size": params.PageSize,
+		"total":     total,
+	})
+}
@@ -0,0 +1,4 @@
+package controllers
+
+// Registers the custom validations of the binding tags on the gin validator.
+import _ "github.com/joaooliveira247/go_olist_challenge/src/validation"
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+	"github.com/joaooliveira247/go_olist_challenge/src/webhooks"
+	"github.com/lib/pq"
+)
+
+type WebhookController struct {
+	repository repositories.WebhookRepository
+}
+
+func NewWebhookController(repo repositories.WebhookRepository) *WebhookController {
+	return &WebhookController{repo}
+}
+
+func (ctrl *WebhookController) withContext(ctx *gin.Context) repositories.WebhookRepository {
+	return ctrl.repository.WithContext(ctx.Request.Context())
+}
+
+// CreateWebhook registers a webhook and answers the secret its deliveries are
+// signed with. It is not shown again.
+func (ctrl *WebhookController) CreateWebhook(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ManageWebhooks) {
+		return
+	}
+
+	var webhook models.WebhookIn
+
+	if err := ctx.ShouldBindJSON(&webhook); err != nil {
+		ctx.JSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+		return
+	}
+
+	secret, err := webhooks.GenerateSecret()
+
+	if err != nil {
+		ctx.JSON(response.UnableCreateEntity.StatusCode, response.UnableCreateEntity.Message)
+		return
+	}
+
+	id, err := ctrl.withContext(ctx).Create(&models.Webhook{URL: webhook.URL, Secret: secret, Events: pq.StringArray(webhook.Events)})
+
+	if err != nil {
+		ctx.JSON(response.UnableCreateEntity.StatusCode, response.UnableCreateEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": id, "secret": secret})
+}
+
+func (ctrl *WebhookController) GetWebhooks(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ManageWebhooks) {
+		return
+	}
+
+	found, err := ctrl.withContext(ctx).GetAll()
+
+	if err != nil {
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, found)
+}
+
+func (ctrl *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ManageWebhooks) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	if err := ctrl.withContext(ctx).Delete(id); err != nil {
+		if errors.Is(err, &custom.WebhookNotFound) {
+			ctx.JSON(response.WebhookNotFound.StatusCode, response.WebhookNotFound.Message)
+			return
+		}
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// GetDeliveries pages through the deliveries, ?status=dead being the dead
+// letters.
+func (ctrl *WebhookController) GetDeliveries(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ManageWebhooks) {
+		return
+	}
+
+	var params dto.DeliveryQueryParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(response.InvalidParam.StatusCode, response.InvalidParam.Message)
+		return
+	}
+
+	deliveries, total, err := ctrl.withContext(ctx).GetDeliveries(params.Status, params.Page, params.PageSize)
+
+	if err != nil {
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"items":     deliveries,
+		"page":      params.Page,
+		"page_size": params.PageSize,
+		"total":     total,
+	})
+}
+
+// RetryDelivery sends a dead delivery again.
+func (ctrl *WebhookController) RetryDelivery(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ManageWebhooks) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	if err := ctrl.withContext(ctx).Retry(id); err != nil {
+		if errors.Is(err, &custom.DeliveryNotFound) {
+			ctx.JSON(response.DeliveryNotFound.StatusCode, response.DeliveryNotFound.Message)
+			return
+		}
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, nil)
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+type WorkController struct {
+	workRepository repositories.WorkRepository
+	bookRepository repositories.BookRepository
+}
+
+func NewWorkController(workRepo repositories.WorkRepository, bookRepo repositories.BookRepository) *WorkController {
+	return &WorkController{workRepo, bookRepo}
+}
+
+func (ctrl *WorkController) GetWork(ctx *gin.Context) {
+	if !policies.Authorize(ctx, policies.ReadCatalog) {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil || id == uuid.Nil {
+		ctx.JSON(response.InvalidID.StatusCode, response.InvalidID.Message)
+		return
+	}
+
+	work, err := ctrl.workRepository.WithContext(ctx.Request.Context()).GetByID(id)
+
+	if err != nil {
+		if errors.Is(err, &custom.WorkNotFound) {
+			ctx.JSON(response.WorkNotFound.StatusCode, response.WorkNotFound.Message)
+			return
+		}
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	editions, err := ctrl.bookRepository.WithContext(ctx.Request.Context()).GetBooksByWorkID(id)
+
+	if err != nil {
+		ctx.JSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+		return
+	}
+
+	if editions == nil {
+		editions = []models.BookOut{}
+	}
+
+	ctx.JSON(http.StatusOK, models.WorkOut{Work: work, Editions: editions})
+}
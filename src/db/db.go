@@ -2,16 +2,23 @@ package db
 
 import (
 	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 func GetDBConnection() (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(config.DB_URL), &gorm.Config{Logger: logger.Default.LogMode(logger.Info)})
+	db, err := gorm.Open(postgres.Open(config.DB_URL), &gorm.Config{Logger: logger.Default.LogMode(logger.Info), TranslateError: true})
 
 	if err != nil {
 		return nil, err
 	}
+
+	if config.TracingEnabled {
+		if err := tracing.Instrument(db); err != nil {
+			return nil, err
+		}
+	}
 	return db, nil
 }
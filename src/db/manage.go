@@ -5,10 +5,36 @@ import (
 	"gorm.io/gorm"
 )
 
+var searchSchema = []string{
+	`ALTER TABLE "books" ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce("title", '')), 'A') || setweight(to_tsvector('portuguese', coalesce("title", '')), 'A')) STORED`,
+	`CREATE INDEX IF NOT EXISTS "idx_books_search_vector" ON "books" USING gin ("search_vector")`,
+	`ALTER TABLE "authors" ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce("name", '')), 'B') || setweight(to_tsvector('portuguese', coalesce("name", '')), 'B')) STORED`,
+	`CREATE INDEX IF NOT EXISTS "idx_authors_search_vector" ON "authors" USING gin ("search_vector")`,
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE EXTENSION IF NOT EXISTS unaccent`,
+	`CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT`,
+	`CREATE INDEX IF NOT EXISTS "idx_authors_name_trgm" ON "authors" USING gin (immutable_unaccent(lower("name")) gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS "idx_publishers_name_trgm" ON "publishers" USING gin (immutable_unaccent(lower("name")) gin_trgm_ops)`,
+}
+
+// workSchema moves the books created before works existed into one work per
+// distinct title.
+var workSchema = []string{
+	`INSERT INTO "works" ("title") SELECT DISTINCT b."title" FROM "books" b WHERE b."work_id" IS NULL AND NOT EXISTS (SELECT 1 FROM "works" w WHERE w."title" = b."title")`,
+	`UPDATE "books" b SET "work_id" = (SELECT w."id" FROM "works" w WHERE w."title" = b."title" ORDER BY w."id" LIMIT 1) WHERE b."work_id" IS NULL`,
+}
+
 func CreateTables(db *gorm.DB) error {
-	if err := db.AutoMigrate(&models.Author{}, &models.BookAuthor{}, &models.Book{}); err != nil {
+	if err := db.AutoMigrate(&models.Author{}, &models.Publisher{}, &models.Work{}, &models.BookAuthor{}, &models.Book{}, &models.Genre{}, &models.BookGenre{}, &models.APIKey{}, &models.AuditLog{}, &models.OutboxEvent{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.IdempotencyKey{}); err != nil {
 		return err
 	}
+
+	for _, statement := range append(searchSchema, workSchema...) {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,33 @@
+package docs
+
+import (
+	_ "embed"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+//go:embed openapi.json
+var OpenAPI []byte
+
+// SwaggerUIAssets holds the Swagger UI build the page loads, served by the
+// API itself so that the docs neither depend on nor trust a CDN.
+var SwaggerUIAssets = swaggerFiles.FS
+
+const SwaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>Olist Library API</title>
+  <link rel="stylesheet" href="/docs/assets/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="/docs/assets/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
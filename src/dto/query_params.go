@@ -1,39 +1,125 @@
 package dto
 
 import (
-	"fmt"
 	"strings"
 )
 
+const (
+	MatchFuzzy     = "fuzzy"
+	MatchSubstring = "substring"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
 type AuthorQueryParams struct {
-	ID   string `form:"authorID"`
+	ID             string `form:"authorID"`
+	Name           string `form:"name"`
+	Match          string `form:"match,default=substring" binding:"oneof=fuzzy substring"`
+	Nationality    string `form:"nationality" binding:"omitempty,iso3166_1_alpha2"`
+	IncludeDeleted bool   `form:"includeDeleted"`
+	Format         string `form:"format" binding:"omitempty,oneof=json csv ndjson"`
+}
+
+const (
+	BulkAtomic  = "atomic"
+	BulkPartial = "partial"
+)
+
+// FormatQueryParams overrides the format negotiated through Accept on the
+// listings that have no other params.
+type FormatQueryParams struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv ndjson"`
+}
+
+// NameQueryParams filters the listings of publishers and genres by name.
+type NameQueryParams struct {
 	Name string `form:"name"`
 }
 
+type BulkQueryParams struct {
+	Mode string `form:"mode,default=atomic" binding:"oneof=atomic partial"`
+}
+
+type AuditQueryParams struct {
+	Entity   string `form:"entity" binding:"omitempty,oneof=author book book_author publisher genre book_genre work"`
+	ID       string `form:"id"`
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"pageSize,default=50" binding:"min=1,max=200"`
+}
+
+type DeliveryQueryParams struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"pageSize,default=50" binding:"min=1,max=200"`
+}
+
+type EventQueryParams struct {
+	Entity []string `form:"entity" binding:"dive,oneof=author book"`
+}
+
+type SearchQueryParams struct {
+	Query    string `form:"q" binding:"required,max=255"`
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
+
 type BookQueryParams struct {
-	AuthorID        string `form:"authorID,omitempty"`
-	BookID          string `form:"bookID,omitempty"`
-	Title           string `form:"title,omitempty"`
-	Edition         uint8  `form:"edition,omitempty"`
-	PublicationYear uint   `form:"publicationYear,omitempty"`
+	AuthorID        string   `form:"authorID,omitempty"`
+	BookID          string   `form:"bookID,omitempty"`
+	Title           string   `form:"title,omitempty"`
+	Edition         uint8    `form:"edition,omitempty"`
+	PublicationYear uint     `form:"publicationYear,omitempty"`
+	ISBN            string   `form:"isbn,omitempty"`
+	PublisherID     string   `form:"publisherID,omitempty"`
+	Genres          []string `form:"genre,omitempty"`
+	Language        string   `form:"language,omitempty"`
+	IncludeDeleted  bool     `form:"includeDeleted,omitempty"`
+	Format          string   `form:"format,omitempty" binding:"omitempty,oneof=json csv ndjson"`
 }
 
-func (query *BookQueryParams) AsQuery() string {
+// AsQuery returns the filters as conditions joined by AND and the values bound
+// to their placeholders, so no value is ever written into the SQL.
+func (query *BookQueryParams) AsQuery() (string, []interface{}) {
 	whereClauses := []string{}
+	args := []interface{}{}
 
 	if query.Title != "" {
-		whereClauses = append(whereClauses, fmt.Sprintf(`b.title = '%s'`, query.Title))
+		whereClauses = append(whereClauses, `b.title = ?`)
+		args = append(args, query.Title)
 	}
 	if query.Edition != 0 {
-		whereClauses = append(whereClauses, fmt.Sprintf(`b.edition = %d`, query.Edition))
+		whereClauses = append(whereClauses, `b.edition = ?`)
+		args = append(args, query.Edition)
 	}
 	if query.PublicationYear != 0 {
-		whereClauses = append(whereClauses, fmt.Sprintf(`b.publication_year = %d`, query.PublicationYear))
+		whereClauses = append(whereClauses, `b.publication_year = ?`)
+		args = append(args, query.PublicationYear)
+	}
+	if query.ISBN != "" {
+		whereClauses = append(whereClauses, `b.isbn = ?`)
+		args = append(args, query.ISBN)
+	}
+	if query.PublisherID != "" {
+		whereClauses = append(whereClauses, `b.publisher_id = ?`)
+		args = append(args, query.PublisherID)
+	}
+	if query.Language != "" {
+		whereClauses = append(whereClauses, `b.language = ?`)
+		args = append(args, query.Language)
+	}
+	for _, genre := range query.Genres {
+		whereClauses = append(whereClauses, `b.id IN (SELECT bg.book_id FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE lower(g.name) = lower(?))`)
+		args = append(args, genre)
 	}
 
-	return strings.Join(whereClauses, " AND ")
+	return strings.Join(whereClauses, " AND "), args
 }
 
 func (query *BookQueryParams) IsEmpty() bool {
-	return query.Title == "" && query.Edition == 0 && query.PublicationYear == 0
+	return query.Title == "" && query.Edition == 0 && query.PublicationYear == 0 && query.ISBN == "" &&
+		query.PublisherID == "" && len(query.Genres) == 0 && query.Language == ""
 }
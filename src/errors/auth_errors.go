@@ -0,0 +1,10 @@
+package errors
+
+type Unauthorized struct {
+	BaseError
+}
+
+var (
+	MissingCredentials = Unauthorized{BaseError{"credentials", "missing"}}
+	InvalidCredentials = Unauthorized{BaseError{"credentials", "invalid"}}
+)
@@ -12,14 +12,38 @@ type NothingToUpdate struct {
 	BaseError
 }
 
+type PreconditionFailed struct {
+	BaseError
+}
+
+type Invalid struct {
+	BaseError
+}
+
 var (
 	AuthorAlreadyExists       = AlreadyExists{BaseError{"author", "already exists"}}
 	AuthorGenericError        = GenericError{BaseError{"author", "generic error"}}
 	BookAuthorGenericError    = GenericError{BaseError{"book_author", "generic error"}}
 	AuthorNotFound            = NotFound{BaseError{"author", "not found"}}
+	AuthorNothingToUpdate     = NothingToUpdate{BaseError{"author", "nothing to update"}}
+	InvalidAuthorYears        = Invalid{BaseError{"author", "death year before birth year"}}
 	RelationshipAlreadyExists = AlreadyExists{BaseError{"relationship", "already exists"}}
 	BookAlreadyExists         = AlreadyExists{BaseError{"book", "already exists"}}
 	BookGenericError          = GenericError{BaseError{"book", "generic error"}}
 	BookNotFound              = NotFound{BaseError{"book", "not found"}}
 	BookNothingToUpdate       = NothingToUpdate{BaseError{"book", "nothing to update"}}
+	BookVersionMismatch       = PreconditionFailed{BaseError{"book", "version mismatch"}}
+	InvalidISBN               = Invalid{BaseError{"isbn", "invalid"}}
+	InvalidLanguage           = Invalid{BaseError{"language", "invalid"}}
+	PublisherAlreadyExists    = AlreadyExists{BaseError{"publisher", "already exists"}}
+	PublisherNotFound         = NotFound{BaseError{"publisher", "not found"}}
+	GenreAlreadyExists        = AlreadyExists{BaseError{"genre", "already exists"}}
+	GenreNotFound             = NotFound{BaseError{"genre", "not found"}}
+	WorkNotFound              = NotFound{BaseError{"work", "not found"}}
+	EditionAlreadyExists      = AlreadyExists{BaseError{"edition", "already exists"}}
+	OriginalBookNotFound      = NotFound{BaseError{"original book", "not found"}}
+	InvalidTranslation        = Invalid{BaseError{"translation", "book can not be a translation of itself"}}
+	APIKeyNotFound            = NotFound{BaseError{"api key", "not found"}}
+	WebhookNotFound           = NotFound{BaseError{"webhook", "not found"}}
+	DeliveryNotFound          = NotFound{BaseError{"delivery", "not found"}}
 )
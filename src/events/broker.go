@@ -0,0 +1,171 @@
+// Package events streams the catalog change events of the outbox to the
+// clients connected to the API as they are committed.
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+)
+
+const (
+	// bufferSize is how many events a subscriber may lag behind before it is
+	// dropped, to resume from its last event.
+	bufferSize = 64
+	// pollLimit is how many events are read from the outbox at a time.
+	pollLimit = 500
+	// gapTimeout is how long a gap in the event IDs is waited on before the
+	// events after it are sent. IDs are taken when an event is written but
+	// it only shows once its transaction commits, so a gap is usually a
+	// transaction about to commit, and only left for good by one that
+	// failed to.
+	gapTimeout = 5 * time.Second
+)
+
+// Subscription receives on Events every event with an ID above From, in
+// order. Events is closed when the subscriber falls too far behind.
+type Subscription struct {
+	Events <-chan models.OutboxEvent
+	From   uint64
+	events chan models.OutboxEvent
+	broker *Broker
+}
+
+func (subscription *Subscription) Close() {
+	subscription.broker.mu.Lock()
+	defer subscription.broker.mu.Unlock()
+
+	subscription.broker.drop(subscription)
+}
+
+// Broker polls the outbox while it has subscribers and sends them the events
+// committed since, so that every client shares the same queries.
+type Broker struct {
+	repository  repositories.EventRepository
+	interval    time.Duration
+	now         func() time.Time
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	cursor      uint64
+	gapSince    time.Time
+	running     bool
+}
+
+func NewBroker(repository repositories.EventRepository, interval time.Duration, now func() time.Time) *Broker {
+	if now == nil {
+		now = time.Now
+	}
+	return &Broker{repository: repository, interval: interval, now: now, subscribers: map[*Subscription]struct{}{}}
+}
+
+// Subscribe starts receiving the events committed from now on. The events
+// before, up to From, are read from the outbox.
+func (broker *Broker) Subscribe(ctx context.Context) (*Subscription, error) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	if !broker.running {
+		last, err := broker.repository.WithContext(ctx).LastID()
+
+		if err != nil {
+			return nil, err
+		}
+
+		broker.cursor = last
+		broker.gapSince = time.Time{}
+		broker.running = true
+
+		go broker.run()
+	}
+
+	events := make(chan models.OutboxEvent, bufferSize)
+	subscription := &Subscription{Events: events, From: broker.cursor, events: events, broker: broker}
+
+	broker.subscribers[subscription] = struct{}{}
+
+	return subscription, nil
+}
+
+func (broker *Broker) run() {
+	ticker := time.NewTicker(broker.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !broker.poll(context.Background()) {
+			return
+		}
+	}
+}
+
+// poll sends the events committed since the last poll to the subscribers,
+// answering false once none is left.
+func (broker *Broker) poll(ctx context.Context) bool {
+	broker.mu.Lock()
+
+	if len(broker.subscribers) < 1 {
+		broker.running = false
+		broker.mu.Unlock()
+		return false
+	}
+
+	cursor := broker.cursor
+	broker.mu.Unlock()
+
+	events, err := broker.repository.WithContext(ctx).GetAfter(cursor, 0, nil, pollLimit)
+
+	if err != nil {
+		log.Println("EVENTS: ", err)
+		return true
+	}
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	ready := 0
+
+	for _, event := range events {
+		if event.ID != cursor+1 {
+			if broker.gapSince.IsZero() {
+				broker.gapSince = broker.now()
+			}
+			if broker.now().Sub(broker.gapSince) < gapTimeout {
+				break
+			}
+		}
+
+		broker.gapSince = time.Time{}
+		cursor = event.ID
+		ready++
+	}
+
+	broker.cursor = cursor
+
+	for subscription := range broker.subscribers {
+		for _, event := range events[:ready] {
+			select {
+			case subscription.events <- event:
+			default:
+				broker.drop(subscription)
+			}
+
+			if _, ok := broker.subscribers[subscription]; !ok {
+				break
+			}
+		}
+	}
+
+	return true
+}
+
+func (broker *Broker) drop(subscription *Subscription) {
+	if _, ok := broker.subscribers[subscription]; !ok {
+		return
+	}
+
+	delete(broker.subscribers, subscription)
+	close(subscription.events)
+}
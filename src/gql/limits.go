@@ -0,0 +1,185 @@
+package gql
+
+import (
+	"errors"
+	"math"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+)
+
+var (
+	errTooDeep    = errors.New("query is too deep")
+	errTooComplex = errors.New("query is too complex")
+)
+
+// cost measures a query before it runs: its depth counts nested fields, and
+// its complexity adds one per field times the page sizes of the lists the
+// field is nested in, as each item of a page resolves the field again.
+type cost struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+// checkLimits rejects queries nested deeper than GRAPHQL_MAX_DEPTH or more
+// complex than GRAPHQL_MAX_COMPLEXITY. Queries that do not parse are left to
+// graphql.Do to report.
+func checkLimits(schema graphql.Schema, request dto.GraphQLRequest) error {
+	document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+
+	if err != nil {
+		return nil
+	}
+
+	measure := cost{schema, map[string]*ast.FragmentDefinition{}, request.Variables, map[string]bool{}}
+
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			measure.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+
+		if !ok || operation.Operation != ast.OperationTypeQuery {
+			continue
+		}
+
+		depth, complexity := measure.selections(schema.QueryType(), operation.SelectionSet, 1)
+
+		if config.GraphQLMaxDepth > 0 && depth > config.GraphQLMaxDepth {
+			return errTooDeep
+		}
+		if config.GraphQLMaxComplexity > 0 && complexity > config.GraphQLMaxComplexity {
+			return errTooComplex
+		}
+	}
+
+	return nil
+}
+
+// selections measures the fields selected on parent, each resolved multiplier
+// times.
+func (measure cost) selections(parent *graphql.Object, set *ast.SelectionSet, multiplier int) (int, int) {
+	if parent == nil || set == nil {
+		return 0, 0
+	}
+
+	depth, complexity := 0, 0
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			definition, ok := parent.Fields()[selection.Name.Value]
+
+			if !ok {
+				continue
+			}
+
+			childDepth, childComplexity := measure.selections(objectOf(definition.Type), selection.SelectionSet, saturate(multiplier*measure.pageSize(definition, selection)))
+			depth = max(depth, childDepth+1)
+			complexity = saturate(complexity + multiplier + childComplexity)
+		case *ast.InlineFragment:
+			on := parent
+			if selection.TypeCondition != nil {
+				on, _ = measure.schema.Type(selection.TypeCondition.Name.Value).(*graphql.Object)
+			}
+
+			childDepth, childComplexity := measure.selections(on, selection.SelectionSet, multiplier)
+			depth = max(depth, childDepth)
+			complexity = saturate(complexity + childComplexity)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := measure.fragments[name]
+
+			// Fragment cycles are rejected by graphql.Do.
+			if !ok || measure.visiting[name] {
+				continue
+			}
+
+			on, _ := measure.schema.Type(fragment.TypeCondition.Name.Value).(*graphql.Object)
+
+			measure.visiting[name] = true
+			childDepth, childComplexity := measure.selections(on, fragment.SelectionSet, multiplier)
+			delete(measure.visiting, name)
+
+			depth = max(depth, childDepth)
+			complexity = saturate(complexity + childComplexity)
+		}
+	}
+
+	return depth, complexity
+}
+
+// pageSize is how many items a paged field answers, or 1 for other fields.
+// Sizes out of range count as the largest page, graphql.Do rejecting them.
+func (measure cost) pageSize(definition *graphql.FieldDefinition, field *ast.Field) int {
+	paged := false
+
+	for _, arg := range definition.Args {
+		if arg.Name() == "pageSize" {
+			paged = true
+		}
+	}
+
+	if !paged {
+		return 1
+	}
+
+	size := defaultPageSize
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "pageSize" {
+			continue
+		}
+
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			size = maxPageSize + 1
+			if parsed, ok := graphql.Int.ParseLiteral(value).(int); ok {
+				size = parsed
+			}
+		case *ast.Variable:
+			switch variable := measure.variables[value.Name.Value].(type) {
+			case float64:
+				size = int(min(variable, maxPageSize+1))
+			case int:
+				size = variable
+			}
+		}
+	}
+
+	if size < 1 || size > maxPageSize {
+		return maxPageSize
+	}
+
+	return size
+}
+
+// objectOf unwraps the object a field answers, if any.
+func objectOf(fieldType graphql.Type) *graphql.Object {
+	for {
+		switch wrapped := fieldType.(type) {
+		case *graphql.NonNull:
+			fieldType = wrapped.OfType
+		case *graphql.List:
+			fieldType = wrapped.OfType
+		case *graphql.Object:
+			return wrapped
+		default:
+			return nil
+		}
+	}
+}
+
+// saturate keeps a measure from overflowing, as it only matters whether it
+// exceeds the limits.
+func saturate(value int) int {
+	return min(value, math.MaxInt32)
+}
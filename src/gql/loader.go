@@ -0,0 +1,69 @@
+package gql
+
+import (
+	"sync"
+)
+
+// loader batches the keys asked for while a level of a query is resolved and
+// fetches all of them with a single call when the first one is needed.
+//
+// Load hands graphql-go a thunk instead of a value. The executor completes
+// every field of a level before calling the thunks it got back, so the keys
+// of all the parents are queued by the time the first thunk runs.
+type loader[K comparable, V any] struct {
+	mutex   sync.Mutex
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	err     error
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, queued: map[K]bool{}, results: map[K]V{}}
+}
+
+// Load queues key and returns a thunk resolving to its value, or to the zero
+// value of V when the fetch did not return it.
+func (loader *loader[K, V]) Load(key K) func() (interface{}, error) {
+	loader.mutex.Lock()
+	if !loader.queued[key] {
+		loader.queued[key] = true
+		loader.pending = append(loader.pending, key)
+	}
+	loader.mutex.Unlock()
+
+	return func() (interface{}, error) {
+		value, err := loader.get(key)
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+func (loader *loader[K, V]) get(key K) (V, error) {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	if len(loader.pending) > 0 {
+		keys := loader.pending
+		loader.pending = nil
+
+		results, err := loader.fetch(keys)
+
+		if err != nil {
+			loader.err = err
+		}
+		for k, v := range results {
+			loader.results[k] = v
+		}
+	}
+
+	if loader.err != nil {
+		var zero V
+		return zero, loader.err
+	}
+
+	return loader.results[key], nil
+}
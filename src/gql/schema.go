@@ -0,0 +1,522 @@
+package gql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/isbn"
+	"github.com/joaooliveira247/go_olist_challenge/src/language"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var (
+	errInvalidID       = errors.New("invalid id")
+	errInvalidArgument = errors.New("invalid argument")
+	errUnableFetch     = errors.New("unable to fetch entity")
+)
+
+// Schema serves the catalog over GraphQL on top of the author and book
+// repositories.
+type Schema struct {
+	schema           graphql.Schema
+	authorRepository repositories.AuthorRepository
+	bookRepository   repositories.BookRepository
+}
+
+// session holds the repositories and loaders of a single request. Loaders
+// cache what they fetched, so they are never shared between requests.
+type session struct {
+	authors       repositories.AuthorRepository
+	books         repositories.BookRepository
+	authorsByID   *loader[uuid.UUID, *models.Author]
+	booksByID     *loader[uuid.UUID, *models.BookOut]
+	booksByAuthor *loader[authorBooks, page]
+}
+
+type sessionKey struct{}
+
+// authorBooks asks for a page of the books of an author.
+type authorBooks struct {
+	authorID uuid.UUID
+	page     int
+	pageSize int
+}
+
+// page is a page of the items of a list query.
+type page struct {
+	Items    interface{}
+	Total    int
+	Page     int
+	PageSize int
+}
+
+func NewSchema(authorRepo repositories.AuthorRepository, bookRepo repositories.BookRepository) (*Schema, error) {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: queryType()})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{schema, authorRepo, bookRepo}, nil
+}
+
+func (schema *Schema) Execute(ctx context.Context, request dto.GraphQLRequest) *graphql.Result {
+	if err := checkLimits(schema.schema, request); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	return graphql.Do(graphql.Params{
+		Schema:         schema.schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        context.WithValue(ctx, sessionKey{}, schema.newSession(ctx)),
+	})
+}
+
+func (schema *Schema) newSession(ctx context.Context) *session {
+	authors := schema.authorRepository.WithContext(ctx)
+	books := schema.bookRepository.WithContext(ctx)
+
+	return &session{
+		authors: authors,
+		books:   books,
+		authorsByID: newLoader(func(ids []uuid.UUID) (map[uuid.UUID]*models.Author, error) {
+			found, err := authors.GetByIDs(ids)
+
+			if err != nil {
+				return nil, errUnableFetch
+			}
+
+			results := map[uuid.UUID]*models.Author{}
+			for i := range found {
+				results[found[i].ID] = &found[i]
+			}
+			return results, nil
+		}),
+		booksByID: newLoader(func(ids []uuid.UUID) (map[uuid.UUID]*models.BookOut, error) {
+			found, err := books.GetBooksByIDs(ids)
+
+			if err != nil {
+				return nil, errUnableFetch
+			}
+
+			results := map[uuid.UUID]*models.BookOut{}
+			for i := range found {
+				results[found[i].ID] = &found[i]
+			}
+			return results, nil
+		}),
+		booksByAuthor: newLoader(func(keys []authorBooks) (map[authorBooks]page, error) {
+			// Authors asked for the same page share a single fetch.
+			var pages [][2]int
+			ids := map[[2]int][]uuid.UUID{}
+			for _, key := range keys {
+				pageArgs := [2]int{key.page, key.pageSize}
+				if _, ok := ids[pageArgs]; !ok {
+					pages = append(pages, pageArgs)
+				}
+				ids[pageArgs] = append(ids[pageArgs], key.authorID)
+			}
+
+			results := map[authorBooks]page{}
+			for _, pageArgs := range pages {
+				found, err := books.GetBooksPageByAuthorIDs(ids[pageArgs], pageArgs[0], pageArgs[1])
+
+				if err != nil {
+					return nil, errUnableFetch
+				}
+
+				for _, id := range ids[pageArgs] {
+					authorPage := found[id]
+					results[authorBooks{id, pageArgs[0], pageArgs[1]}] = newPage(authorPage.Books, authorPage.Total, pageArgs[0], pageArgs[1])
+				}
+			}
+			return results, nil
+		}),
+	}
+}
+
+func sessionFrom(ctx context.Context) *session {
+	return ctx.Value(sessionKey{}).(*session)
+}
+
+func queryType() *graphql.Object {
+	authorType := graphql.NewObject(graphql.ObjectConfig{Name: "Author", Fields: graphql.Fields{}})
+	bookType := graphql.NewObject(graphql.ObjectConfig{Name: "Book", Fields: graphql.Fields{}})
+	bookPageType := pageType("BookPage", bookType)
+
+	pageArgs := graphql.FieldConfigArgument{
+		"page":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+		"pageSize": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+	}
+
+	contributorType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Contributor",
+		Fields: graphql.Fields{
+			"author": &graphql.Field{
+				Type: authorType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					contributor := p.Source.(models.ContributorOut)
+					return sessionFrom(p.Context).authorsByID.Load(contributor.AuthorID), nil
+				},
+			},
+			"role": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	authorType.AddFieldConfig("id", &graphql.Field{Type: graphql.NewNonNull(graphql.ID)})
+	authorType.AddFieldConfig("name", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	authorType.AddFieldConfig("sortName", &graphql.Field{Type: graphql.String})
+	authorType.AddFieldConfig("biography", &graphql.Field{Type: graphql.String})
+	authorType.AddFieldConfig("birthYear", &graphql.Field{Type: graphql.Int})
+	authorType.AddFieldConfig("deathYear", &graphql.Field{Type: graphql.Int})
+	authorType.AddFieldConfig("nationality", &graphql.Field{Type: graphql.String})
+	authorType.AddFieldConfig("website", &graphql.Field{Type: graphql.String})
+	authorType.AddFieldConfig("books", &graphql.Field{
+		Type: graphql.NewNonNull(bookPageType),
+		Args: pageArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			author := p.Source.(*models.Author)
+			number, size, err := pageOf(p.Args)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return sessionFrom(p.Context).booksByAuthor.Load(authorBooks{author.ID, number, size}), nil
+		},
+	})
+
+	bookType.AddFieldConfig("id", bookField(graphql.NewNonNull(graphql.ID), func(book *models.BookOut) interface{} { return book.ID }))
+	bookType.AddFieldConfig("title", bookField(graphql.NewNonNull(graphql.String), func(book *models.BookOut) interface{} { return book.Title }))
+	bookType.AddFieldConfig("edition", bookField(graphql.NewNonNull(graphql.Int), func(book *models.BookOut) interface{} { return book.Edition }))
+	bookType.AddFieldConfig("publicationYear", bookField(graphql.NewNonNull(graphql.Int), func(book *models.BookOut) interface{} { return book.PublicationYear }))
+	bookType.AddFieldConfig("isbn", bookField(graphql.String, func(book *models.BookOut) interface{} { return book.ISBN }))
+	bookType.AddFieldConfig("isbn10", bookField(graphql.String, func(book *models.BookOut) interface{} { return book.ISBN10 }))
+	bookType.AddFieldConfig("publisherId", bookField(graphql.ID, func(book *models.BookOut) interface{} { return book.PublisherID }))
+	bookType.AddFieldConfig("workId", bookField(graphql.ID, func(book *models.BookOut) interface{} { return book.WorkID }))
+	bookType.AddFieldConfig("language", bookField(graphql.String, func(book *models.BookOut) interface{} { return book.Language }))
+	bookType.AddFieldConfig("version", bookField(graphql.NewNonNull(graphql.Int), func(book *models.BookOut) interface{} { return book.Version }))
+	bookType.AddFieldConfig("genres", bookField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))), func(book *models.BookOut) interface{} {
+		if book.GenresName == nil {
+			return []string{}
+		}
+		return []string(book.GenresName)
+	}))
+	bookType.AddFieldConfig("translationOf", &graphql.Field{
+		Type: bookType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			book := p.Source.(*models.BookOut)
+			if book.TranslationOfID == nil {
+				return nil, nil
+			}
+			return sessionFrom(p.Context).booksByID.Load(*book.TranslationOfID), nil
+		},
+	})
+	bookType.AddFieldConfig("contributors", bookField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(contributorType))), func(book *models.BookOut) interface{} {
+		if book.Contributors == nil {
+			return []models.ContributorOut{}
+		}
+		return []models.ContributorOut(book.Contributors)
+	}))
+	bookType.AddFieldConfig("authors", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(authorType)),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			book := p.Source.(*models.BookOut)
+			loaders := sessionFrom(p.Context)
+
+			authors := make([]interface{}, len(book.Contributors))
+			for i, contributor := range book.Contributors {
+				authors[i] = loaders.authorsByID.Load(contributor.AuthorID)
+			}
+			return authors, nil
+		},
+	})
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"author": &graphql.Field{
+				Type: authorType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: resolveAuthor,
+			},
+			"authors": &graphql.Field{
+				Type: pageType("AuthorPage", authorType),
+				Args: withArgs(pageArgs, graphql.FieldConfigArgument{
+					"name":        &graphql.ArgumentConfig{Type: graphql.String},
+					"nationality": &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: resolveAuthors,
+			},
+			"book": &graphql.Field{
+				Type: bookType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: resolveBook,
+			},
+			"books": &graphql.Field{
+				Type: bookPageType,
+				Args: withArgs(pageArgs, graphql.FieldConfigArgument{
+					"authorId":        &graphql.ArgumentConfig{Type: graphql.ID},
+					"title":           &graphql.ArgumentConfig{Type: graphql.String},
+					"edition":         &graphql.ArgumentConfig{Type: graphql.Int},
+					"publicationYear": &graphql.ArgumentConfig{Type: graphql.Int},
+					"isbn":            &graphql.ArgumentConfig{Type: graphql.String},
+					"publisherId":     &graphql.ArgumentConfig{Type: graphql.ID},
+					"genres":          &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+					"language":        &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: resolveBooks,
+			},
+		},
+	})
+}
+
+// bookField resolves a field of the book the field is called on.
+func bookField(fieldType graphql.Output, value func(book *models.BookOut) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return value(p.Source.(*models.BookOut)), nil
+		},
+	}
+}
+
+func pageType(name string, itemType graphql.Output) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name,
+		Fields: graphql.Fields{
+			"items":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType)))},
+			"total":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"page":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"pageSize": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+}
+
+func withArgs(args ...graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	merged := graphql.FieldConfigArgument{}
+	for _, group := range args {
+		for name, arg := range group {
+			merged[name] = arg
+		}
+	}
+	return merged
+}
+
+// pageOf reads the page asked for in the arguments.
+func pageOf(args map[string]interface{}) (int, int, error) {
+	number, _ := args["page"].(int)
+	size, _ := args["pageSize"].(int)
+
+	if number < 1 || size < 1 || size > maxPageSize {
+		return 0, 0, errInvalidArgument
+	}
+
+	return number, size, nil
+}
+
+// newPage wraps the items the repository fetched for a page.
+func newPage[T any](items []T, total int64, number int, size int) page {
+	pageItems := make([]*T, len(items))
+	for i := range items {
+		pageItems[i] = &items[i]
+	}
+
+	return page{Items: pageItems, Total: int(total), Page: number, PageSize: size}
+}
+
+func parseID(raw interface{}) (uuid.UUID, error) {
+	text, _ := raw.(string)
+	id, err := uuid.Parse(text)
+
+	if err != nil || id == uuid.Nil {
+		return uuid.UUID{}, errInvalidID
+	}
+
+	return id, nil
+}
+
+func resolveAuthor(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := sessionFrom(p.Context).authors.GetByID(id)
+
+	if err != nil {
+		if errors.Is(err, &custom.AuthorNotFound) {
+			return nil, nil
+		}
+		return nil, errUnableFetch
+	}
+
+	return &author, nil
+}
+
+func resolveAuthors(p graphql.ResolveParams) (interface{}, error) {
+	authors := sessionFrom(p.Context).authors
+
+	if nationality, ok := p.Args["nationality"].(string); ok && nationality != "" {
+		authors = authors.WithNationality(nationality)
+	}
+
+	number, size, err := pageOf(p.Args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if name, ok := p.Args["name"].(string); ok && name != "" {
+		matches, total, err := authors.GetPageBySimilarName(name, config.AuthorSimilarityThreshold, number, size)
+
+		if err != nil {
+			return nil, errUnableFetch
+		}
+
+		found := make([]models.Author, len(matches))
+		for i, match := range matches {
+			found[i] = match.Author
+		}
+		return newPage(found, total, number, size), nil
+	}
+
+	found, total, err := authors.GetPage(number, size)
+
+	if err != nil {
+		return nil, errUnableFetch
+	}
+
+	return newPage(found, total, number, size), nil
+}
+
+func resolveBook(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := sessionFrom(p.Context).books.GetBookByID(id)
+
+	if err != nil {
+		if errors.Is(err, &custom.BookNotFound) {
+			return nil, nil
+		}
+		return nil, errUnableFetch
+	}
+
+	return &book, nil
+}
+
+// resolveBooks filters the books the same way as GET /books: the author id
+// takes precedence over the other filters.
+func resolveBooks(p graphql.ResolveParams) (interface{}, error) {
+	books := sessionFrom(p.Context).books
+
+	number, size, err := pageOf(p.Args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var condition string
+	var args []interface{}
+
+	if raw, ok := p.Args["authorId"]; ok {
+		authorID, err := parseID(raw)
+
+		if err != nil {
+			return nil, err
+		}
+
+		condition, args = "b.id IN (SELECT book_id FROM book_author WHERE author_id = ?)", []interface{}{authorID}
+	} else {
+		query, err := bookQuery(p.Args)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !query.IsEmpty() {
+			condition, args = query.AsQuery()
+		}
+	}
+
+	found, total, err := books.GetBooksPage(condition, args, number, size)
+
+	if err != nil {
+		return nil, errUnableFetch
+	}
+
+	return newPage(found, total, number, size), nil
+}
+
+func bookQuery(args map[string]interface{}) (dto.BookQueryParams, error) {
+	var query dto.BookQueryParams
+
+	query.Title, _ = args["title"].(string)
+
+	if edition, ok := args["edition"].(int); ok {
+		if edition < 1 || edition > 255 {
+			return query, errInvalidArgument
+		}
+		query.Edition = uint8(edition)
+	}
+	if year, ok := args["publicationYear"].(int); ok {
+		if year < 1 {
+			return query, errInvalidArgument
+		}
+		query.PublicationYear = uint(year)
+	}
+	if raw, ok := args["isbn"].(string); ok && raw != "" {
+		normalized, valid := isbn.Normalize(raw)
+
+		if !valid {
+			return query, errInvalidArgument
+		}
+		query.ISBN = normalized
+	}
+	if raw, ok := args["language"].(string); ok && raw != "" {
+		normalized, valid := language.Normalize(raw)
+
+		if !valid {
+			return query, errInvalidArgument
+		}
+		query.Language = normalized
+	}
+	if raw, ok := args["publisherId"]; ok {
+		publisherID, err := parseID(raw)
+
+		if err != nil {
+			return query, err
+		}
+		query.PublisherID = publisherID.String()
+	}
+	if genres, ok := args["genres"].([]interface{}); ok {
+		for _, genre := range genres {
+			query.Genres = append(query.Genres, genre.(string))
+		}
+	}
+
+	return query, nil
+}
@@ -0,0 +1,107 @@
+package isbn
+
+import (
+	"strings"
+)
+
+// Normalize strips hyphens and spaces from an ISBN-10 or ISBN-13, checks its
+// checksum and returns it as ISBN-13. ok is false when raw is not a valid ISBN.
+func Normalize(raw string) (normalized string, ok bool) {
+	digits := strings.NewReplacer("-", "", " ", "").Replace(raw)
+
+	switch len(digits) {
+	case 10:
+		if !valid10(digits) {
+			return "", false
+		}
+		prefixed := "978" + digits[:9]
+		return prefixed + string(checkDigit13(prefixed)), true
+	case 13:
+		if !valid13(digits) {
+			return "", false
+		}
+		return digits, true
+	}
+
+	return "", false
+}
+
+// To10 converts a normalized ISBN-13 to ISBN-10. ok is false when isbn13 is not
+// prefixed with 978, as only those have an ISBN-10.
+func To10(isbn13 string) (isbn10 string, ok bool) {
+	if len(isbn13) != 13 || !strings.HasPrefix(isbn13, "978") || !valid13(isbn13) {
+		return "", false
+	}
+
+	return isbn13[3:12] + string(checkDigit10(isbn13[3:12])), true
+}
+
+// Valid reports whether raw is an ISBN-10 or ISBN-13 with a correct checksum.
+func Valid(raw string) bool {
+	_, ok := Normalize(raw)
+	return ok
+}
+
+func valid10(digits string) bool {
+	sum := 0
+
+	for i := 0; i < 10; i++ {
+		var value int
+
+		switch c := digits[i]; {
+		case c >= '0' && c <= '9':
+			value = int(c - '0')
+		case (c == 'X' || c == 'x') && i == 9:
+			value = 10
+		default:
+			return false
+		}
+
+		sum += (10 - i) * value
+	}
+
+	return sum%11 == 0
+}
+
+func valid13(digits string) bool {
+	if !isDigits(digits) {
+		return false
+	}
+	return checkDigit13(digits[:12]) == digits[12]
+}
+
+func checkDigit10(first9 string) byte {
+	sum := 0
+
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(first9[i]-'0')
+	}
+
+	if check := (11 - sum%11) % 11; check < 10 {
+		return byte('0' + check)
+	}
+	return 'X'
+}
+
+func checkDigit13(first12 string) byte {
+	sum := 0
+
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(first12[i]-'0')
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
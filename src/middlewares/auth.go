@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+func Authenticate(apiKeys repositories.APIKeyRepository, verifier *auth.JWTVerifier) gin.HandlerFunc {
+	return authenticate(apiKeys, verifier, func() bool { return false })
+}
+
+func AuthenticateReads(apiKeys repositories.APIKeyRepository, verifier *auth.JWTVerifier) gin.HandlerFunc {
+	return authenticate(apiKeys, verifier, func() bool { return config.AuthPublicReads })
+}
+
+func authenticate(apiKeys repositories.APIKeyRepository, verifier *auth.JWTVerifier, public func() bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !config.AuthEnabled {
+			ctx.Next()
+			return
+		}
+
+		principal, err := auth.FromRequest(ctx.Request, apiKeys.WithContext(ctx.Request.Context()), verifier)
+
+		if err != nil {
+			if errors.Is(err, &custom.MissingCredentials) && public() {
+				ctx.Next()
+				return
+			}
+
+			var unauthorized *custom.Unauthorized
+
+			if errors.As(err, &unauthorized) {
+				ctx.Header("WWW-Authenticate", `Bearer realm="go_olist_challenge"`)
+				ctx.AbortWithStatusJSON(response.Unauthorized.StatusCode, response.Unauthorized.Message)
+				return
+			}
+
+			ctx.AbortWithStatusJSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+			return
+		}
+
+		ctx.Set(auth.PrincipalKey, principal)
+		ctx.Request = ctx.Request.WithContext(audit.WithActor(ctx.Request.Context(), principal.Actor()))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+)
+
+// CacheBypass sends the reads of requests with Cache-Control: no-cache or
+// no-store straight to the database, to debug a stale response.
+func CacheBypass() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		directives := strings.ToLower(ctx.GetHeader("Cache-Control"))
+
+		if strings.Contains(directives, "no-cache") || strings.Contains(directives, "no-store") {
+			ctx.Request = ctx.Request.WithContext(cache.WithBypass(ctx.Request.Context()))
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,153 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	ReplayedHeader       = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// idempotencySweepInterval is how often the expired keys of every client
+	// are deleted.
+	idempotencySweepInterval = time.Hour
+)
+
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (recorder *idempotencyRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (recorder *idempotencyRecorder) WriteString(data string) (int, error) {
+	recorder.body.WriteString(data)
+	return recorder.ResponseWriter.WriteString(data)
+}
+
+// Idempotency stores the response to a request sent with an Idempotency-Key
+// for window, and answers it again to the requests of the same client
+// repeating the key, so that a create retried after a timeout happens once.
+// Reusing a key for a different request is rejected. Responses with a 5xx
+// status are not stored, leaving the key free to retry.
+func Idempotency(repository repositories.IdempotencyRepository, window func() time.Duration) gin.HandlerFunc {
+	var (
+		mu        sync.Mutex
+		lastSweep time.Time
+	)
+
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		current := window()
+
+		if key == "" || current <= 0 {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			ctx.AbortWithStatusJSON(response.InvalidIdempotencyKey.StatusCode, response.InvalidIdempotencyKey.Message)
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(response.InvalidRequestBody.StatusCode, response.InvalidRequestBody.Message)
+			return
+		}
+
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// The outcome is recorded even when the client gives up on the request.
+		repository := repository.WithContext(context.WithoutCancel(ctx.Request.Context()))
+		now := time.Now()
+
+		mu.Lock()
+		if now.Sub(lastSweep) >= idempotencySweepInterval {
+			lastSweep = now
+			if _, err := repository.DeleteExpired(); err != nil {
+				log.Println("IDEMPOTENCY: ", err)
+			}
+		}
+		mu.Unlock()
+
+		reserved := &models.IdempotencyKey{
+			Client:      hashOf(clientKey(ctx)),
+			Key:         key,
+			RequestHash: hashOf(ctx.Request.Method + " " + ctx.Request.URL.Path + "\n" + string(body)),
+			ExpiresAt:   now.Add(current),
+		}
+
+		existing, ok, err := repository.Reserve(reserved)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(response.UnableFetchEntity.StatusCode, response.UnableFetchEntity.Message)
+			return
+		}
+
+		if !ok {
+			switch {
+			case existing.RequestHash != reserved.RequestHash:
+				ctx.AbortWithStatusJSON(response.IdempotencyKeyReused.StatusCode, response.IdempotencyKeyReused.Message)
+			case existing.StatusCode == 0:
+				ctx.AbortWithStatusJSON(response.IdempotencyInProgress.StatusCode, response.IdempotencyInProgress.Message)
+			default:
+				ctx.Header(ReplayedHeader, "true")
+				ctx.Data(existing.StatusCode, existing.ContentType, existing.Body)
+				ctx.Abort()
+			}
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+
+		completed := false
+
+		defer func() {
+			if completed {
+				return
+			}
+			if err := repository.Release(reserved.Client, reserved.Key); err != nil {
+				log.Println("IDEMPOTENCY: ", err)
+			}
+		}()
+
+		ctx.Next()
+
+		if recorder.Status() >= 500 {
+			return
+		}
+
+		err = repository.Complete(reserved.Client, reserved.Key, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+
+		if err != nil {
+			log.Println("IDEMPOTENCY: ", err)
+			return
+		}
+
+		completed = true
+	}
+}
+
+func hashOf(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,223 @@
+package middlewares
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type RateLimitStore interface {
+	Take(key string, limit RateLimit) RateLimitDecision
+	Peek(key string, limit RateLimit) RateLimitDecision
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore(now func() time.Time) RateLimitStore {
+	if now == nil {
+		now = time.Now
+	}
+	return &memoryRateLimitStore{buckets: map[string]*bucket{}, now: now, lastSweep: now()}
+}
+
+var (
+	sharedStore     RateLimitStore
+	sharedStoreOnce sync.Once
+)
+
+// SharedRateLimitStore is the rate limit store of the process, so that a
+// client has the same budget over REST and gRPC.
+func SharedRateLimitStore() RateLimitStore {
+	sharedStoreOnce.Do(func() {
+		sharedStore = NewMemoryRateLimitStore(nil)
+	})
+	return sharedStore
+}
+
+func ReadLimit() RateLimit {
+	return RateLimit{Requests: config.RateLimitReads, Window: config.RateLimitWindow}
+}
+
+func WriteLimit() RateLimit {
+	return RateLimit{Requests: config.RateLimitWrites, Window: config.RateLimitWindow}
+}
+
+func (store *memoryRateLimitStore) Take(key string, limit RateLimit) RateLimitDecision {
+	return store.decide(key, limit, true)
+}
+
+// Peek answers whether key could take a token now, without taking it.
+func (store *memoryRateLimitStore) Peek(key string, limit RateLimit) RateLimitDecision {
+	return store.decide(key, limit, false)
+}
+
+func (store *memoryRateLimitStore) decide(key string, limit RateLimit, take bool) RateLimitDecision {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.now()
+	capacity := float64(limit.Requests)
+	perSecond := capacity / limit.Window.Seconds()
+
+	store.sweep(now, limit.Window)
+
+	current, ok := store.buckets[key]
+
+	if !ok {
+		current = &bucket{tokens: capacity, lastSeen: now}
+		store.buckets[key] = current
+	}
+
+	current.tokens = math.Min(capacity, current.tokens+now.Sub(current.lastSeen).Seconds()*perSecond)
+	current.lastSeen = now
+
+	decision := RateLimitDecision{Limit: limit.Requests}
+
+	if current.tokens >= 1 {
+		if take {
+			current.tokens--
+		}
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - current.tokens) / perSecond)
+	}
+
+	decision.Remaining = int(math.Floor(current.tokens))
+	decision.Reset = seconds((capacity - current.tokens) / perSecond)
+
+	return decision
+}
+
+func (store *memoryRateLimitStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(store.lastSweep) < window {
+		return
+	}
+
+	for key, current := range store.buckets {
+		if now.Sub(current.lastSeen) >= window {
+			delete(store.buckets, key)
+		}
+	}
+
+	store.lastSweep = now
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(math.Ceil(value)) * time.Second
+}
+
+func RateLimiter(store RateLimitStore, scope string, limit func() RateLimit) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		current := limit()
+
+		if current.Requests <= 0 {
+			ctx.Next()
+			return
+		}
+
+		decision := store.Take(scope+":"+clientKey(ctx), current)
+
+		ctx.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(int(decision.Reset.Seconds())))
+
+		if !decision.Allowed {
+			tooManyRequests(ctx, decision)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RateLimitFailedAuth goes before the authentication, charging every request
+// it rejects with 401 to a failed-auth bucket of the client IP, apart from the
+// one unauthenticated requests take from. Once that bucket is empty, requests
+// from the IP are rejected before their credentials are checked, so that keys
+// can not be guessed at any rate.
+func RateLimitFailedAuth(store RateLimitStore, scope string, limit func() RateLimit) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		current := limit()
+
+		if current.Requests <= 0 {
+			ctx.Next()
+			return
+		}
+
+		key := FailedAuthKey(scope, ctx.ClientIP())
+
+		if decision := store.Peek(key, current); !decision.Allowed {
+			tooManyRequests(ctx, decision)
+			return
+		}
+
+		ctx.Next()
+
+		if ctx.Writer.Status() == http.StatusUnauthorized {
+			store.Take(key, current)
+		}
+	}
+}
+
+func tooManyRequests(ctx *gin.Context, decision RateLimitDecision) {
+	ctx.Header("Retry-After", strconv.Itoa(int(decision.RetryAfter.Seconds())))
+	ctx.AbortWithStatusJSON(response.TooManyRequests.StatusCode, response.TooManyRequests.Message)
+}
+
+// clientKey answers the verified principal of the request, or its IP when it
+// has none. Credentials that were not verified, such as an X-API-Key sent
+// while authentication is off, are ignored: a client could make a new one up
+// for every request.
+func clientKey(ctx *gin.Context) string {
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		return ClientKey(&principal, ctx.ClientIP())
+	}
+
+	return ClientKey(nil, ctx.ClientIP())
+}
+
+// ClientKey answers the bucket of the client with principal, or of ip when
+// the client has no verified principal.
+func ClientKey(principal *auth.Principal, ip string) string {
+	if principal != nil {
+		return principal.Actor()
+	}
+
+	return "ip:" + ip
+}
+
+// FailedAuthKey answers the bucket the failed authentications of ip are
+// charged to.
+func FailedAuthKey(scope string, ip string) string {
+	return scope + ":authfail:" + ip
+}
@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+)
+
+const maxRequestIDLength = 64
+
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(audit.RequestIDHeader)
+
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Header(audit.RequestIDHeader, requestID)
+		ctx.Request = ctx.Request.WithContext(audit.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Next()
+	}
+}
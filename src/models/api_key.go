@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name      string     `json:"name" gorm:"type:varchar(255);column:name;not null"`
+	Prefix    string     `json:"prefix" gorm:"type:varchar(16);column:prefix;not null"`
+	Hash      string     `json:"-" gorm:"type:char(64);column:hash;unique;not null"`
+	Role      string     `json:"role" gorm:"type:varchar(16);column:role;not null;default:reader"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" gorm:"column:revoked_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
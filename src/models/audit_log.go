@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JSON json.RawMessage
+
+func NewJSON(value interface{}) JSON {
+	if value == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		return nil
+	}
+
+	return JSON(data)
+}
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(value interface{}) error {
+	switch data := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append(JSON{}, data...)
+	case string:
+		*j = JSON(data)
+	default:
+		return fmt.Errorf("cannot scan %T into JSON", value)
+	}
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append(JSON{}, data...)
+	return nil
+}
+
+type AuditLog struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Entity    string    `json:"entity" gorm:"type:varchar(32);not null;index:idx_audit_log_entity,priority:1;column:entity"`
+	EntityID  uuid.UUID `json:"entity_id" gorm:"type:uuid;not null;index:idx_audit_log_entity,priority:2;column:entity_id"`
+	Action    string    `json:"action" gorm:"type:varchar(16);not null;column:action"`
+	Actor     string    `json:"actor" gorm:"type:varchar(255);not null;column:actor"`
+	Before    JSON      `json:"before" gorm:"type:jsonb;column:before"`
+	After     JSON      `json:"after" gorm:"type:jsonb;column:after"`
+	RequestID string    `json:"request_id,omitempty" gorm:"type:varchar(64);column:request_id"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime;index:idx_audit_log_entity,priority:3"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
@@ -1,10 +1,45 @@
 package models
 
 import (
+	"reflect"
+
 	"github.com/google/uuid"
 )
 
 type Author struct {
-	ID   uuid.UUID `json:"id,omitempty" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	Name string    `json:"name,omitempty" binding:"required,min=2" gorm:"type:varchar(255);column:name;unique;not null"`
+	ID          uuid.UUID `json:"id,omitempty" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string    `json:"name,omitempty" binding:"required,min=2" gorm:"type:varchar(255);column:name;not null;uniqueIndex:idx_authors_name,where:deleted_at IS NULL"`
+	SortName    *string   `json:"sort_name,omitempty" binding:"omitempty,min=2,max=255" gorm:"type:varchar(255);column:sort_name"`
+	Biography   *string   `json:"biography,omitempty" binding:"omitempty,max=5000" gorm:"type:text;column:biography"`
+	BirthYear   *uint16   `json:"birth_year,omitempty" binding:"omitempty,gt=0" gorm:"type:smallint;column:birth_year"`
+	DeathYear   *uint16   `json:"death_year,omitempty" binding:"omitempty,gt=0" gorm:"type:smallint;column:death_year"`
+	Nationality *string   `json:"nationality,omitempty" binding:"omitempty,iso3166_1_alpha2" gorm:"type:char(2);column:nationality"`
+	Website     *string   `json:"website,omitempty" binding:"omitempty,url,max=255" gorm:"type:varchar(255);column:website"`
+	DeletedAt   DeletedAt `json:"deleted_at,omitempty" gorm:"index;column:deleted_at"`
+}
+
+// AuthorUpdate holds the fields to change on an author. Empty fields are left
+// as they are.
+type AuthorUpdate struct {
+	Name        string `json:"name,omitempty" binding:"omitempty,min=2"`
+	SortName    string `json:"sort_name,omitempty" binding:"omitempty,min=2,max=255"`
+	Biography   string `json:"biography,omitempty" binding:"omitempty,max=5000"`
+	BirthYear   uint16 `json:"birth_year,omitempty"`
+	DeathYear   uint16 `json:"death_year,omitempty"`
+	Nationality string `json:"nationality,omitempty" binding:"omitempty,iso3166_1_alpha2"`
+	Website     string `json:"website,omitempty" binding:"omitempty,url,max=255"`
+}
+
+type AuthorMatch struct {
+	Author
+	Similarity float64 `json:"similarity" gorm:"column:similarity"`
+}
+
+func (model *AuthorUpdate) IsEmpty() bool {
+	return reflect.DeepEqual(*model, AuthorUpdate{})
+}
+
+// LivedBackwards tells whether the author died before being born.
+func (model *Author) LivedBackwards() bool {
+	return model.BirthYear != nil && model.DeathYear != nil && *model.DeathYear < *model.BirthYear
 }
@@ -4,35 +4,86 @@ import (
 	"reflect"
 
 	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/isbn"
 	"github.com/lib/pq"
 )
 
 type Book struct {
-	ID              uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	Title           string    `json:"title" binding:"required,gt=1" gorm:"type:varchar(255);not null;column:title"`
-	Edition         uint8     `json:"edition" binding:"required,gt=0" gorm:"type:smallint;column:edition"`
-	PublicationYear uint      `json:"publication_year" binding:"required,gt=0" gorm:"type:smallint;column:publication_year"`
+	ID              uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Title           string     `json:"title" binding:"required,gt=1" gorm:"type:varchar(255);not null;column:title"`
+	Edition         uint8      `json:"edition" binding:"required,gt=0" gorm:"type:smallint;column:edition"`
+	PublicationYear uint       `json:"publication_year" binding:"required,gt=0" gorm:"type:smallint;column:publication_year"`
+	ISBN            *string    `json:"isbn,omitempty" binding:"omitempty,isbn_checksum" gorm:"type:varchar(13);column:isbn;uniqueIndex:idx_books_isbn,where:deleted_at IS NULL"`
+	PublisherID     *uuid.UUID `json:"publisher_id,omitempty" gorm:"type:uuid;column:publisher_id"`
+	Publisher       *Publisher `json:"-" binding:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	WorkID          *uuid.UUID `json:"work_id,omitempty" gorm:"type:uuid;column:work_id"`
+	Work            *Work      `json:"-" binding:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Language        *string    `json:"language,omitempty" binding:"omitempty,iso639_1" gorm:"type:varchar(2);column:language"`
+	TranslationOfID *uuid.UUID `json:"translation_of,omitempty" gorm:"type:uuid;column:translation_of_id"`
+	TranslationOf   *Book      `json:"-" binding:"-" gorm:"foreignKey:TranslationOfID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Version         uint       `json:"version" gorm:"type:integer;not null;default:1;column:version"`
+	DeletedAt       DeletedAt  `json:"deleted_at,omitempty" gorm:"index;column:deleted_at"`
 }
 
 type BookIn struct {
 	Book
-	AuthorsID []uuid.UUID `json:"authors" binding:"gt=0,required,dive,uuid" gorm:"-"`
+	AuthorsID    []uuid.UUID   `json:"authors,omitempty" binding:"unique,dive,uuid" gorm:"-"`
+	Contributors []Contributor `json:"contributors,omitempty" binding:"unique=AuthorID,dive" gorm:"-"`
+	GenresID     []uuid.UUID   `json:"genres,omitempty" binding:"unique,dive,uuid" gorm:"-"`
 }
 
 type BookOut struct {
 	Book
-	AuthorsName pq.StringArray `json:"authors" gorm:"type:text[];column:authors"`
+	ISBN10       *string         `json:"isbn_10,omitempty" gorm:"-"`
+	AuthorsName  pq.StringArray  `json:"authors" gorm:"type:text[];column:authors"`
+	Contributors ContributorsOut `json:"contributors" gorm:"type:jsonb;column:contributors"`
+	GenresName   pq.StringArray  `json:"genres" gorm:"type:text[];column:genres"`
+}
+
+// BookPage is one page of a list of books along with how many books the
+// whole list has.
+type BookPage struct {
+	Books []BookOut
+	Total int64
 }
 
 type BookInfo struct {
-	Title           string `json:"title,omitempty"`
-	Edition         uint8  `json:"edition,omitempty"`
-	PublicationYear uint   `json:"publication_year,omitempty"`
+	Title           string    `json:"title,omitempty"`
+	Edition         uint8     `json:"edition,omitempty"`
+	PublicationYear uint      `json:"publication_year,omitempty"`
+	ISBN            string    `json:"isbn,omitempty" binding:"omitempty,isbn_checksum"`
+	PublisherID     uuid.UUID `json:"publisher_id,omitempty"`
+	Language        string    `json:"language,omitempty" binding:"omitempty,iso639_1"`
+	TranslationOfID uuid.UUID `json:"translation_of,omitempty"`
 }
 
 type BookUpdate struct {
 	BookInfo
-	AuthorsID []uuid.UUID `json:"authors,omitempty" binding:"dive,uuid"`
+	AuthorsID    []uuid.UUID   `json:"authors,omitempty" binding:"unique,dive,uuid"`
+	Contributors []Contributor `json:"contributors,omitempty" binding:"unique=AuthorID,dive"`
+	GenresID     []uuid.UUID   `json:"genres,omitempty" binding:"unique,dive,uuid"`
+}
+
+// SetISBN10 derives ISBN10 from the ISBN-13 the book is stored with, leaving it
+// unset when there is none.
+func (model *BookOut) SetISBN10() {
+	model.ISBN10 = nil
+
+	if model.ISBN != nil {
+		if isbn10, ok := isbn.To10(*model.ISBN); ok {
+			model.ISBN10 = &isbn10
+		}
+	}
+}
+
+// BookAuthors lists the contributors of the book in order.
+func (model *BookIn) BookAuthors(bookID uuid.UUID) []BookAuthor {
+	return bookAuthors(bookID, model.AuthorsID, model.Contributors)
+}
+
+// BookAuthors lists the contributors that replace the current ones, if any.
+func (model *BookUpdate) BookAuthors(bookID uuid.UUID) []BookAuthor {
+	return bookAuthors(bookID, model.AuthorsID, model.Contributors)
 }
 
 func (model *BookUpdate) IsEmpty() bool {
@@ -1,14 +1,82 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ContributorAuthor      = "author"
+	ContributorEditor      = "editor"
+	ContributorTranslator  = "translator"
+	ContributorIllustrator = "illustrator"
+)
 
 type BookAuthor struct {
-	BookID   uuid.UUID `gorm:"primaryKey;column:book_id"`
-	Book     Book      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:BookID;"`
-	AuthorID uuid.UUID `gorm:"primaryKey;column:author_id"`
-	Author   Author    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:AuthorID;"`
+	BookID   uuid.UUID `json:"book_id" gorm:"primaryKey;column:book_id"`
+	Book     Book      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:BookID;"`
+	AuthorID uuid.UUID `json:"author_id" gorm:"primaryKey;column:author_id"`
+	Author   Author    `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:AuthorID;"`
+	Role     string    `json:"role" gorm:"type:varchar(16);not null;default:'author';column:role"`
+	Position uint      `json:"position" gorm:"type:smallint;not null;default:0;column:position"`
 }
 
 func (BookAuthor) TableName() string {
 	return "book_author"
 }
+
+// Contributor is an author of a book in a role, as sent on create and
+// update. Its position is its index in the list it was sent in.
+type Contributor struct {
+	AuthorID uuid.UUID `json:"author_id" binding:"required"`
+	Role     string    `json:"role,omitempty" binding:"omitempty,oneof=author editor translator illustrator"`
+}
+
+type ContributorOut struct {
+	AuthorID uuid.UUID `json:"author_id"`
+	Name     string    `json:"name"`
+	Role     string    `json:"role"`
+}
+
+// ContributorsOut scans the json_agg of the contributors of a book.
+type ContributorsOut []ContributorOut
+
+func (contributors *ContributorsOut) Scan(value interface{}) error {
+	switch raw := value.(type) {
+	case nil:
+		*contributors = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(raw, contributors)
+	case string:
+		return json.Unmarshal([]byte(raw), contributors)
+	}
+	return fmt.Errorf("unsupported contributors value %T", value)
+}
+
+// bookAuthors orders the contributors of a book, reading a plain list of
+// author ids as authors when no contributors were sent.
+func bookAuthors(bookID uuid.UUID, authorsID []uuid.UUID, contributors []Contributor) []BookAuthor {
+	if len(contributors) < 1 {
+		for _, authorID := range authorsID {
+			contributors = append(contributors, Contributor{AuthorID: authorID})
+		}
+	}
+
+	relationships := make([]BookAuthor, 0, len(contributors))
+
+	for position, contributor := range contributors {
+		role := contributor.Role
+
+		if role == "" {
+			role = ContributorAuthor
+		}
+		relationships = append(relationships, BookAuthor{
+			BookID: bookID, AuthorID: contributor.AuthorID, Role: role, Position: uint(position),
+		})
+	}
+
+	return relationships
+}
@@ -0,0 +1,10 @@
+package models
+
+import "github.com/google/uuid"
+
+type BulkResult struct {
+	Index  int        `json:"index"`
+	Status int        `json:"status"`
+	ID     *uuid.UUID `json:"id,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// DeletedAt is when a row was soft deleted. It is answered in JSON but never
+// read from it, so that a request body can not create a deleted row: only a
+// delete or a restore changes it.
+type DeletedAt struct {
+	gorm.DeletedAt
+}
+
+func (deleted *DeletedAt) UnmarshalJSON([]byte) error {
+	return nil
+}
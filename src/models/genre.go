@@ -0,0 +1,31 @@
+package models
+
+import "github.com/google/uuid"
+
+type Genre struct {
+	ID   uuid.UUID `json:"id,omitempty" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name string    `json:"name,omitempty" binding:"required,min=2" gorm:"type:varchar(255);column:name;unique;not null"`
+}
+
+func (genre *Genre) GetID() uuid.UUID {
+	return genre.ID
+}
+
+func (genre *Genre) GetName() string {
+	return genre.Name
+}
+
+func (genre *Genre) SetName(name string) {
+	genre.Name = name
+}
+
+type BookGenre struct {
+	BookID  uuid.UUID `json:"book_id" gorm:"primaryKey;column:book_id"`
+	Book    Book      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:BookID;"`
+	GenreID uuid.UUID `json:"genre_id" gorm:"primaryKey;column:genre_id"`
+	Genre   Genre     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:GenreID;"`
+}
+
+func (BookGenre) TableName() string {
+	return "book_genre"
+}
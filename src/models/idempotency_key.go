@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// IdempotencyKey is the first response to a create sent with an
+// Idempotency-Key, replayed for the requests repeating it until ExpiresAt.
+// StatusCode is 0 while the first request is still being handled.
+type IdempotencyKey struct {
+	Client      string    `gorm:"primaryKey;type:char(64);column:client"`
+	Key         string    `gorm:"primaryKey;type:varchar(255);column:key"`
+	RequestHash string    `gorm:"type:char(64);column:request_hash;not null"`
+	StatusCode  int       `gorm:"type:smallint;column:status_code;not null;default:0"`
+	ContentType string    `gorm:"type:varchar(255);column:content_type"`
+	Body        []byte    `gorm:"type:bytea;column:body"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	ExpiresAt   time.Time `gorm:"column:expires_at;not null;index"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
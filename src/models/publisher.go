@@ -0,0 +1,22 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type Publisher struct {
+	ID   uuid.UUID `json:"id,omitempty" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name string    `json:"name,omitempty" binding:"required,min=2" gorm:"type:varchar(255);column:name;unique;not null"`
+}
+
+func (publisher *Publisher) GetID() uuid.UUID {
+	return publisher.ID
+}
+
+func (publisher *Publisher) GetName() string {
+	return publisher.Name
+}
+
+func (publisher *Publisher) SetName(name string) {
+	publisher.Name = name
+}
@@ -0,0 +1,8 @@
+package models
+
+type BookSearchResult struct {
+	BookOut
+	Rank             float64 `json:"rank" gorm:"column:rank"`
+	TitleHighlight   string  `json:"title_highlight" gorm:"column:title_highlight"`
+	AuthorsHighlight string  `json:"authors_highlight" gorm:"column:authors_highlight"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Catalog change events, written to the outbox in the transaction of the
+// change they describe.
+const (
+	EventAuthorCreated  = "author.created"
+	EventAuthorUpdated  = "author.updated"
+	EventAuthorDeleted  = "author.deleted"
+	EventAuthorRestored = "author.restored"
+	EventAuthorPurged   = "author.purged"
+	EventBookCreated    = "book.created"
+	EventBookUpdated    = "book.updated"
+	EventBookDeleted    = "book.deleted"
+	EventBookRestored   = "book.restored"
+	EventBookPurged     = "book.purged"
+)
+
+// OutboxEvent is a catalog change waiting to be delivered. Its ID grows with
+// every event, so it also orders them.
+type OutboxEvent struct {
+	ID           uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Type         string     `json:"type" gorm:"type:varchar(32);not null;column:type"`
+	EntityID     uuid.UUID  `json:"entity_id" gorm:"type:uuid;not null;column:entity_id"`
+	Payload      JSON       `json:"data" gorm:"type:jsonb;not null;column:payload"`
+	CreatedAt    time.Time  `json:"occurred_at" gorm:"column:created_at;autoCreateTime"`
+	DispatchedAt *time.Time `json:"-" gorm:"column:dispatched_at;index"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+// EventData is the payload of an event: the entity before and after the
+// change, and which entity changed, since a book is also updated when its
+// authors or genres are.
+type EventData struct {
+	ID     uuid.UUID `json:"id"`
+	Entity string    `json:"entity"`
+	Before JSON      `json:"before"`
+	After  JSON      `json:"after"`
+}
+
+// Webhook is a URL events are delivered to, signed with its secret. An empty
+// Events receives every event.
+type Webhook struct {
+	ID        uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	URL       string         `json:"url" gorm:"type:varchar(2048);column:url;not null"`
+	Secret    string         `json:"-" gorm:"type:varchar(64);column:secret;not null"`
+	Events    pq.StringArray `json:"events" gorm:"type:text[];column:events"`
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+func (webhook Webhook) Subscribes(event string) bool {
+	if len(webhook.Events) < 1 {
+		return true
+	}
+
+	for _, subscribed := range webhook.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+type WebhookIn struct {
+	URL    string   `json:"url" binding:"required,url,max=2048"`
+	Events []string `json:"events" binding:"dive,oneof=author.created author.updated author.deleted author.restored author.purged book.created book.updated book.deleted book.restored book.purged"`
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookDelivery is an event on its way to a webhook. It is retried until
+// delivered or until it runs out of attempts, when it is dead lettered.
+type WebhookDelivery struct {
+	ID            uuid.UUID   `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	WebhookID     uuid.UUID   `json:"webhook_id" gorm:"type:uuid;not null;column:webhook_id;uniqueIndex:idx_webhook_deliveries_event,priority:1"`
+	Webhook       Webhook     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:WebhookID;"`
+	EventID       uint64      `json:"event_id" gorm:"not null;column:event_id;uniqueIndex:idx_webhook_deliveries_event,priority:2"`
+	Event         OutboxEvent `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:EventID;"`
+	Attempts      int         `json:"attempts" gorm:"type:smallint;not null;default:0;column:attempts"`
+	NextAttemptAt time.Time   `json:"next_attempt_at" gorm:"column:next_attempt_at;not null;index"`
+	LastStatus    *int        `json:"last_status,omitempty" gorm:"type:smallint;column:last_status"`
+	LastError     *string     `json:"last_error,omitempty" gorm:"type:text;column:last_error"`
+	DeliveredAt   *time.Time  `json:"delivered_at,omitempty" gorm:"column:delivered_at"`
+	DeadAt        *time.Time  `json:"dead_at,omitempty" gorm:"column:dead_at"`
+	CreatedAt     time.Time   `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "github.com/google/uuid"
+
+// Work is the abstract book every edition belongs to, so the 1st and 2nd
+// edition of the same title can be listed together.
+type Work struct {
+	ID    uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Title string    `json:"title" gorm:"type:varchar(255);not null;column:title"`
+}
+
+type WorkOut struct {
+	Work
+	Editions []BookOut `json:"editions"`
+}
+
+// EditionIn is a new edition of an existing book. Title and publisher default
+// to the ones of that book.
+type EditionIn struct {
+	Title           string     `json:"title,omitempty" binding:"omitempty,gt=1"`
+	Edition         uint8      `json:"edition" binding:"required,gt=0"`
+	PublicationYear uint       `json:"publication_year" binding:"required,gt=0"`
+	ISBN            *string    `json:"isbn,omitempty" binding:"omitempty,isbn_checksum"`
+	PublisherID     *uuid.UUID `json:"publisher_id,omitempty"`
+}
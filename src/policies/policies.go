@@ -0,0 +1,94 @@
+package policies
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+)
+
+type Action string
+
+const (
+	ReadCatalog     Action = "catalog:read"
+	ReadDeleted     Action = "catalog:read-deleted"
+	CreateAuthor    Action = "author:create"
+	UpdateAuthor    Action = "author:update"
+	DeleteAuthor    Action = "author:delete"
+	RestoreAuthor   Action = "author:restore"
+	CreateBook      Action = "book:create"
+	UpdateBook      Action = "book:update"
+	DeleteBook      Action = "book:delete"
+	RestoreBook     Action = "book:restore"
+	CreatePublisher Action = "publisher:create"
+	UpdatePublisher Action = "publisher:update"
+	DeletePublisher Action = "publisher:delete"
+	CreateGenre     Action = "genre:create"
+	UpdateGenre     Action = "genre:update"
+	DeleteGenre     Action = "genre:delete"
+	BulkCreate      Action = "catalog:bulk"
+	ReadAudit       Action = "audit:read"
+	ReadCacheStats  Action = "cache:read"
+	ManageWebhooks  Action = "webhook:manage"
+)
+
+var (
+	everyone   = []auth.Role{auth.RoleReader, auth.RoleLibrarian, auth.RoleAdmin}
+	librarians = []auth.Role{auth.RoleLibrarian, auth.RoleAdmin}
+	admins     = []auth.Role{auth.RoleAdmin}
+)
+
+var rules = map[Action][]auth.Role{
+	ReadCatalog:     everyone,
+	ReadDeleted:     admins,
+	CreateAuthor:    librarians,
+	UpdateAuthor:    librarians,
+	DeleteAuthor:    admins,
+	RestoreAuthor:   admins,
+	CreateBook:      librarians,
+	UpdateBook:      librarians,
+	DeleteBook:      admins,
+	RestoreBook:     admins,
+	CreatePublisher: librarians,
+	UpdatePublisher: librarians,
+	DeletePublisher: admins,
+	CreateGenre:     librarians,
+	UpdateGenre:     librarians,
+	DeleteGenre:     admins,
+	BulkCreate:      admins,
+	ReadAudit:       admins,
+	ReadCacheStats:  admins,
+	ManageWebhooks:  admins,
+}
+
+func Allows(role auth.Role, action Action) bool {
+	for _, allowed := range rules[action] {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+func Authorize(ctx *gin.Context, action Action) bool {
+	if !config.AuthEnabled {
+		return true
+	}
+
+	principal, ok := auth.PrincipalFrom(ctx)
+
+	if !ok {
+		if action == ReadCatalog && config.AuthPublicReads {
+			return true
+		}
+		ctx.AbortWithStatusJSON(response.Forbidden.StatusCode, response.Forbidden.Message)
+		return false
+	}
+
+	if !Allows(principal.Role, action) {
+		ctx.AbortWithStatusJSON(response.Forbidden.StatusCode, response.Forbidden.Message)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	WithContext(ctx context.Context) APIKeyRepository
+	Create(key *models.APIKey) (uuid.UUID, error)
+	GetAll() ([]models.APIKey, error)
+	GetActiveByHash(hash string) (models.APIKey, error)
+	Revoke(id uuid.UUID) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+func (repository *apiKeyRepository) WithContext(ctx context.Context) APIKeyRepository {
+	return &apiKeyRepository{repository.db.WithContext(ctx)}
+}
+
+func (repository *apiKeyRepository) Create(key *models.APIKey) (uuid.UUID, error) {
+	db, span := startSpan(repository.db, "apiKeyRepository.Create")
+	defer span.End()
+
+	if err := db.Create(&key).Error; err != nil {
+		return uuid.Nil, err
+	}
+
+	return key.ID, nil
+}
+
+func (repository *apiKeyRepository) GetAll() ([]models.APIKey, error) {
+	db, span := startSpan(repository.db, "apiKeyRepository.GetAll")
+	defer span.End()
+
+	var keys []models.APIKey
+
+	if err := db.Order("created_at").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (repository *apiKeyRepository) GetActiveByHash(hash string) (models.APIKey, error) {
+	db, span := startSpan(repository.db, "apiKeyRepository.GetActiveByHash")
+	defer span.End()
+
+	var key models.APIKey
+
+	result := db.First(&key, "hash = ? AND revoked_at IS NULL", hash)
+
+	if err := result.Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.APIKey{}, &custom.APIKeyNotFound
+		}
+		return models.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+func (repository *apiKeyRepository) Revoke(id uuid.UUID) error {
+	db, span := startSpan(repository.db, "apiKeyRepository.Revoke")
+	defer span.End()
+
+	result := db.Model(&models.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now())
+
+	if err := result.Error; err != nil {
+		return err
+	}
+
+	if result.RowsAffected < 1 {
+		return &custom.APIKeyNotFound
+	}
+
+	return nil
+}
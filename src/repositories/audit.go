@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	WithContext(ctx context.Context) AuditRepository
+	GetAll(entity string, entityID uuid.UUID, page int, pageSize int) ([]models.AuditLog, int64, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db}
+}
+
+func (repository *auditRepository) WithContext(ctx context.Context) AuditRepository {
+	return &auditRepository{repository.db.WithContext(ctx)}
+}
+
+func (repository *auditRepository) GetAll(entity string, entityID uuid.UUID, page int, pageSize int) ([]models.AuditLog, int64, error) {
+	db, span := startSpan(repository.db, "auditRepository.GetAll")
+	defer span.End()
+
+	query := db.Model(&models.AuditLog{})
+
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+
+	if entityID != uuid.Nil {
+		query = query.Where("entity_id = ?", entityID)
+	}
+
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	logs := []models.AuditLog{}
+
+	if err := query.Order("created_at DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+func auditLog(entity string, action string, id uuid.UUID, before interface{}, after interface{}) models.AuditLog {
+	return models.AuditLog{
+		Entity:   entity,
+		EntityID: id,
+		Action:   action,
+		Before:   models.NewJSON(before),
+		After:    models.NewJSON(after),
+	}
+}
+
+func record(db *gorm.DB, logs ...models.AuditLog) error {
+	if len(logs) < 1 {
+		return nil
+	}
+
+	ctx := db.Statement.Context
+
+	var events []models.OutboxEvent
+
+	for i := range logs {
+		logs[i].Actor = audit.Actor(ctx)
+		logs[i].RequestID = audit.RequestID(ctx)
+
+		invalidate(db, changedTags(logs[i])...)
+
+		if event, ok := changedEvent(logs[i]); ok {
+			events = append(events, event)
+		}
+	}
+
+	if err := db.Create(&logs).Error; err != nil {
+		return err
+	}
+
+	return publish(db, events...)
+}
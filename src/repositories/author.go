@@ -1,36 +1,88 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
 	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
 	"github.com/joaooliveira247/go_olist_challenge/src/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AuthorRepository interface {
+	WithContext(ctx context.Context) AuthorRepository
+	Unscoped() AuthorRepository
 	Create(author *models.Author) (uuid.UUID, error)
 	CreateMany(authors *[]models.Author) ([]uuid.UUID, error)
 	GetAll() ([]models.Author, error)
+	GetPage(page int, pageSize int) ([]models.Author, int64, error)
 	GetByID(id uuid.UUID) (models.Author, error)
+	GetByIDs(ids []uuid.UUID) ([]models.Author, error)
 	GetByName(name string) ([]models.Author, error)
+	Each(fn func(models.Author) error) error
+	EachByName(name string, fn func(models.Author) error) error
+	GetBySimilarName(name string, threshold float64) ([]models.AuthorMatch, error)
+	GetPageBySimilarName(name string, threshold float64, page int, pageSize int) ([]models.AuthorMatch, int64, error)
+	WithNationality(code string) AuthorRepository
+	Update(id uuid.UUID, author *models.AuthorUpdate) error
 	Delete(id uuid.UUID) error
+	Restore(id uuid.UUID) error
+	Purge(deletedBefore time.Time) (int64, error)
 }
 
 type authorRepository struct {
-	db *gorm.DB
+	db          *gorm.DB
+	unscoped    bool
+	nationality string
 }
 
 func NewAuthorRepository(db *gorm.DB) AuthorRepository {
-	return &authorRepository{db}
+	return &authorRepository{db: db}
+}
+
+func (repository *authorRepository) WithContext(ctx context.Context) AuthorRepository {
+	return &authorRepository{repository.db.WithContext(ctx), repository.unscoped, repository.nationality}
+}
+
+func (repository *authorRepository) Unscoped() AuthorRepository {
+	return &authorRepository{repository.db, true, repository.nationality}
+}
+
+// WithNationality narrows the lookups to authors of the given ISO 3166-1
+// alpha-2 nationality.
+func (repository *authorRepository) WithNationality(code string) AuthorRepository {
+	return &authorRepository{repository.db, repository.unscoped, code}
+}
+
+func (repository *authorRepository) scoped(db *gorm.DB) *gorm.DB {
+	if repository.unscoped {
+		db = db.Unscoped()
+	}
+	if repository.nationality != "" {
+		db = db.Where("nationality = ?", repository.nationality)
+	}
+	return db
 }
 
 func (repository *authorRepository) Create(author *models.Author) (uuid.UUID, error) {
-	result := repository.db.Create(&author)
+	db, span := startSpan(repository.db, "authorRepository.Create")
+	defer span.End()
 
-	if err := result.Error; err != nil {
+	err := transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Create(&author).Error; err != nil {
+			return err
+		}
+
+		return record(tx, auditLog(audit.EntityAuthor, audit.ActionCreate, author.ID, nil, author))
+	})
+
+	if err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			return uuid.UUID{}, &custom.AuthorAlreadyExists
 		}
@@ -41,9 +93,27 @@ func (repository *authorRepository) Create(author *models.Author) (uuid.UUID, er
 }
 
 func (repository *authorRepository) CreateMany(authors *[]models.Author) ([]uuid.UUID, error) {
-	result := repository.db.Create(&authors)
+	db, span := startSpan(repository.db, "authorRepository.CreateMany")
+	defer span.End()
 
-	if err := result.Error; err != nil {
+	err := transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Create(&authors).Error; err != nil {
+			return err
+		}
+
+		var logs []models.AuditLog
+
+		for _, author := range *authors {
+			logs = append(logs, auditLog(audit.EntityAuthor, audit.ActionCreate, author.ID, nil, author))
+		}
+
+		return record(tx, logs...)
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, &custom.AuthorAlreadyExists
+		}
 		return nil, err
 	}
 
@@ -57,9 +127,12 @@ func (repository *authorRepository) CreateMany(authors *[]models.Author) ([]uuid
 }
 
 func (repository *authorRepository) GetAll() ([]models.Author, error) {
+	db, span := startSpan(repository.db, "authorRepository.GetAll")
+	defer span.End()
+
 	var authors []models.Author
 
-	result := repository.db.Find(&authors)
+	result := repository.scoped(db).Find(&authors)
 
 	if err := result.Error; err != nil {
 		return nil, err
@@ -68,10 +141,40 @@ func (repository *authorRepository) GetAll() ([]models.Author, error) {
 	return authors, nil
 }
 
+// GetPage fetches one page of the authors ordered by name, along with how many
+// authors there are.
+func (repository *authorRepository) GetPage(page int, pageSize int) ([]models.Author, int64, error) {
+	db, span := startSpan(repository.db, "authorRepository.GetPage")
+	defer span.End()
+
+	var total int64
+
+	if err := repository.scoped(db).Model(&models.Author{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	authors := []models.Author{}
+
+	if total < 1 {
+		return authors, 0, nil
+	}
+
+	result := repository.scoped(db).Order("name, id").Limit(pageSize).Offset((page - 1) * pageSize).Find(&authors)
+
+	if err := result.Error; err != nil {
+		return nil, 0, err
+	}
+
+	return authors, total, nil
+}
+
 func (repository *authorRepository) GetByID(id uuid.UUID) (models.Author, error) {
+	db, span := startSpan(repository.db, "authorRepository.GetByID")
+	defer span.End()
+
 	var author models.Author
 
-	result := repository.db.First(&author, "id = ?", id)
+	result := repository.scoped(db).First(&author, "id = ?", id)
 
 	if err := result.Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -83,10 +186,30 @@ func (repository *authorRepository) GetByID(id uuid.UUID) (models.Author, error)
 	return author, nil
 }
 
+// GetByIDs fetches the authors with the given ids in a single query. Missing
+// ids are left out.
+func (repository *authorRepository) GetByIDs(ids []uuid.UUID) ([]models.Author, error) {
+	db, span := startSpan(repository.db, "authorRepository.GetByIDs")
+	defer span.End()
+
+	var authors []models.Author
+
+	result := repository.scoped(db).Where("id IN ?", ids).Find(&authors)
+
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
+
 func (repository *authorRepository) GetByName(name string) ([]models.Author, error) {
+	db, span := startSpan(repository.db, "authorRepository.GetByName")
+	defer span.End()
+
 	var authors []models.Author
 
-	result := repository.db.Where("name LIKE ?", fmt.Sprintf("%%%s%%", name)).Find(&authors)
+	result := repository.scoped(db).Where("name LIKE ?", fmt.Sprintf("%%%s%%", name)).Find(&authors)
 
 	if err := result.Error; err != nil {
 		return nil, err
@@ -95,16 +218,253 @@ func (repository *authorRepository) GetByName(name string) ([]models.Author, err
 	return authors, nil
 }
 
+// Each streams every author to fn one row at a time.
+func (repository *authorRepository) Each(fn func(models.Author) error) error {
+	db, span := startSpan(repository.db, "authorRepository.Each")
+	defer span.End()
+
+	return eachRow(repository.scoped(db).Model(&models.Author{}), fn)
+}
+
+// EachByName streams the authors whose name contains name to fn one row at a
+// time.
+func (repository *authorRepository) EachByName(name string, fn func(models.Author) error) error {
+	db, span := startSpan(repository.db, "authorRepository.EachByName")
+	defer span.End()
+
+	return eachRow(repository.scoped(db).Model(&models.Author{}).Where("name LIKE ?", fmt.Sprintf("%%%s%%", name)), fn)
+}
+
+// GetBySimilarName matches names ignoring case and accents and tolerating
+// typos, using pg_trgm word similarity against the unaccented name.
+func (repository *authorRepository) GetBySimilarName(name string, threshold float64) ([]models.AuthorMatch, error) {
+	db, span := startSpan(repository.db, "authorRepository.GetBySimilarName")
+	defer span.End()
+
+	authors := []models.AuthorMatch{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", strconv.FormatFloat(threshold, 'f', -1, 64)).Error; err != nil {
+			return err
+		}
+
+		return repository.scoped(tx).Model(&models.Author{}).
+			Select("*, word_similarity(immutable_unaccent(lower(?)), immutable_unaccent(lower(name))) AS similarity", name).
+			Where("immutable_unaccent(lower(?)) <% immutable_unaccent(lower(name))", name).
+			Order("similarity DESC, name").
+			Find(&authors).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
+
+// GetPageBySimilarName fetches one page of the authors GetBySimilarName
+// matches, along with how many it matches.
+func (repository *authorRepository) GetPageBySimilarName(name string, threshold float64, page int, pageSize int) ([]models.AuthorMatch, int64, error) {
+	db, span := startSpan(repository.db, "authorRepository.GetPageBySimilarName")
+	defer span.End()
+
+	var total int64
+	authors := []models.AuthorMatch{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", strconv.FormatFloat(threshold, 'f', -1, 64)).Error; err != nil {
+			return err
+		}
+
+		similar := "immutable_unaccent(lower(?)) <% immutable_unaccent(lower(name))"
+
+		if err := repository.scoped(tx).Model(&models.Author{}).Where(similar, name).Count(&total).Error; err != nil {
+			return err
+		}
+
+		if total < 1 {
+			return nil
+		}
+
+		return repository.scoped(tx).Model(&models.Author{}).
+			Select("*, word_similarity(immutable_unaccent(lower(?)), immutable_unaccent(lower(name))) AS similarity", name).
+			Where(similar, name).
+			Order("similarity DESC, name, id").
+			Limit(pageSize).
+			Offset((page - 1) * pageSize).
+			Find(&authors).Error
+	})
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return authors, total, nil
+}
+
+func (repository *authorRepository) Update(id uuid.UUID, author *models.AuthorUpdate) error {
+	db, span := startSpan(repository.db, "authorRepository.Update")
+	defer span.End()
+
+	err := transaction(db, func(tx *gorm.DB) error {
+		before, err := lockAuthor(tx, id)
+
+		if err != nil {
+			return err
+		}
+
+		after := before
+		updates := map[string]interface{}{}
+
+		if author.Name != "" {
+			updates["name"] = author.Name
+			after.Name = author.Name
+		}
+		if author.SortName != "" {
+			updates["sort_name"] = author.SortName
+			after.SortName = &author.SortName
+		}
+		if author.Biography != "" {
+			updates["biography"] = author.Biography
+			after.Biography = &author.Biography
+		}
+		if author.BirthYear != 0 {
+			updates["birth_year"] = author.BirthYear
+			after.BirthYear = &author.BirthYear
+		}
+		if author.DeathYear != 0 {
+			updates["death_year"] = author.DeathYear
+			after.DeathYear = &author.DeathYear
+		}
+		if author.Nationality != "" {
+			updates["nationality"] = author.Nationality
+			after.Nationality = &author.Nationality
+		}
+		if author.Website != "" {
+			updates["website"] = author.Website
+			after.Website = &author.Website
+		}
+
+		if len(updates) < 1 {
+			return &custom.AuthorNothingToUpdate
+		}
+
+		if after.LivedBackwards() {
+			return &custom.InvalidAuthorYears
+		}
+
+		if err := tx.Model(&models.Author{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return record(tx, auditLog(audit.EntityAuthor, audit.ActionUpdate, id, before, after))
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return &custom.AuthorAlreadyExists
+	}
+	return err
+}
+
 func (repository *authorRepository) Delete(id uuid.UUID) error {
-	result := repository.db.Delete(models.Author{}, id)
+	db, span := startSpan(repository.db, "authorRepository.Delete")
+	defer span.End()
 
-	if err := result.Error; err != nil {
-		return err
+	return transaction(db, func(tx *gorm.DB) error {
+		before, err := lockAuthor(tx, id)
+
+		if err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Author{}, id)
+
+		if err := result.Error; err != nil {
+			return err
+		}
+
+		if result.RowsAffected < 1 {
+			return &custom.AuthorNotFound
+		}
+
+		return record(tx, auditLog(audit.EntityAuthor, audit.ActionDelete, id, before, nil))
+	})
+}
+
+func (repository *authorRepository) Restore(id uuid.UUID) error {
+	db, span := startSpan(repository.db, "authorRepository.Restore")
+	defer span.End()
+
+	return transaction(db, func(tx *gorm.DB) error {
+		var before models.Author
+
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&before, "id = ? AND deleted_at IS NOT NULL", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &custom.AuthorNotFound
+			}
+			return err
+		}
+
+		// Another author may have taken the name since this one was deleted.
+		if err := tx.Unscoped().Model(&models.Author{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return &custom.AuthorAlreadyExists
+			}
+			return err
+		}
+
+		after := before
+		after.DeletedAt = models.DeletedAt{}
+
+		return record(tx, auditLog(audit.EntityAuthor, audit.ActionRestore, id, before, after))
+	})
+}
+
+func (repository *authorRepository) Purge(deletedBefore time.Time) (int64, error) {
+	db, span := startSpan(repository.db, "authorRepository.Purge")
+	defer span.End()
+
+	var purged []models.Author
+
+	err := transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at < ?", deletedBefore).Find(&purged).Error; err != nil {
+			return err
+		}
+
+		if len(purged) < 1 {
+			return nil
+		}
+
+		var logs []models.AuditLog
+
+		for _, author := range purged {
+			logs = append(logs, auditLog(audit.EntityAuthor, audit.ActionPurge, author.ID, author, nil))
+		}
+
+		if err := tx.Unscoped().Delete(&purged).Error; err != nil {
+			return err
+		}
+
+		return record(tx, logs...)
+	})
+
+	if err != nil {
+		return 0, err
 	}
 
-	if result.RowsAffected < 1 {
-		return &custom.AuthorNotFound
+	return int64(len(purged)), nil
+}
+
+func lockAuthor(tx *gorm.DB, id uuid.UUID) (models.Author, error) {
+	var author models.Author
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&author, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Author{}, &custom.AuthorNotFound
+		}
+		return models.Author{}, err
 	}
 
-	return nil
+	return author, nil
 }
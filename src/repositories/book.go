@@ -1,81 +1,311 @@
 package repositories
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
 	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/isbn"
+	"github.com/joaooliveira247/go_olist_challenge/src/language"
 	"github.com/joaooliveira247/go_olist_challenge/src/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookRepository interface {
-	Create(book *models.Book) (uuid.UUID, error)
+	WithContext(ctx context.Context) BookRepository
+	Unscoped() BookRepository
+	Create(book *models.BookIn) (uuid.UUID, error)
+	CreateMany(books []models.BookIn) ([]uuid.UUID, error)
 	GetAll() ([]models.BookOut, error)
-	GetBookByQuery(query string) ([]models.BookOut, error)
+	GetBookByQuery(query string, args []interface{}) ([]models.BookOut, error)
+	GetBooksPage(query string, args []interface{}, page int, pageSize int) ([]models.BookOut, int64, error)
+	EachBook(query string, args []interface{}, fn func(models.BookOut) error) error
 	GetBookByID(id uuid.UUID) (models.BookOut, error)
+	GetBooksByIDs(ids []uuid.UUID) ([]models.BookOut, error)
 	GetBooksByAuthorID(authorID uuid.UUID) ([]models.BookOut, error)
-	Update(id uuid.UUID, book *models.BookUpdate) error
-	Delete(id uuid.UUID) error
+	EachBookByAuthorID(authorID uuid.UUID, fn func(models.BookOut) error) error
+	GetBooksPageByAuthorIDs(authorIDs []uuid.UUID, page int, pageSize int) (map[uuid.UUID]models.BookPage, error)
+	GetBooksByWorkID(workID uuid.UUID) ([]models.BookOut, error)
+	GetTranslations(id uuid.UUID) ([]models.BookOut, error)
+	CreateEdition(id uuid.UUID, edition *models.EditionIn) (uuid.UUID, error)
+	Update(id uuid.UUID, book *models.BookUpdate, version uint) error
+	Delete(id uuid.UUID, version uint) error
+	Restore(id uuid.UUID) error
+	Purge(deletedBefore time.Time) (int64, error)
 }
 
 type bookRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	unscoped bool
 }
 
+const selectBooks = `SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id`
+
 func NewBookRepository(db *gorm.DB) BookRepository {
-	return &bookRepository{db}
+	return &bookRepository{db: db}
 }
 
-func (repository *bookRepository) Create(book *models.Book) (uuid.UUID, error) {
-	result := repository.db.FirstOrCreate(&book, book)
+func (repository *bookRepository) WithContext(ctx context.Context) BookRepository {
+	return &bookRepository{repository.db.WithContext(ctx), repository.unscoped}
+}
 
-	if err := result.Error; err != nil {
+func (repository *bookRepository) Unscoped() BookRepository {
+	return &bookRepository{repository.db, true}
+}
+
+func (repository *bookRepository) selectBooks(conditions ...string) string {
+	if !repository.unscoped {
+		conditions = append(conditions, "b.deleted_at IS NULL", "a.deleted_at IS NULL")
+	}
+
+	if len(conditions) < 1 {
+		return selectBooks
+	}
+
+	return fmt.Sprintf("%s WHERE %s", selectBooks, strings.Join(conditions, " AND "))
+}
+
+// Create creates the book with its authors and genres in a single
+// transaction.
+func (repository *bookRepository) Create(book *models.BookIn) (uuid.UUID, error) {
+	db, span := startSpan(repository.db, "bookRepository.Create")
+	defer span.End()
+
+	err := transaction(db, func(tx *gorm.DB) error {
+		return createBookIn(tx, book)
+	})
+
+	if err != nil {
 		return uuid.Nil, err
 	}
 
+	return book.ID, nil
+}
+
+// CreateMany creates every book with its authors and genres in a single
+// transaction, so one existing book or unknown author or genre rolls back the
+// whole batch.
+func (repository *bookRepository) CreateMany(books []models.BookIn) ([]uuid.UUID, error) {
+	db, span := startSpan(repository.db, "bookRepository.CreateMany")
+	defer span.End()
+
+	var ids []uuid.UUID
+
+	err := transaction(db, func(tx *gorm.DB) error {
+		for i := range books {
+			if err := createBookIn(tx, &books[i]); err != nil {
+				return err
+			}
+
+			ids = append(ids, books[i].ID)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func createBookIn(tx *gorm.DB, book *models.BookIn) error {
+	if err := createBook(tx, &book.Book); err != nil {
+		return err
+	}
+
+	for _, relationship := range book.BookAuthors(book.ID) {
+		if err := createBookAuthor(tx, &relationship); err != nil {
+			return err
+		}
+	}
+
+	if len(book.GenresID) > 0 {
+		return setBookGenres(tx, book.ID, book.GenresID)
+	}
+	return nil
+}
+
+func createBook(tx *gorm.DB, book *models.Book) error {
+	if book.ISBN != nil {
+		normalized, err := normalizeISBN(*book.ISBN)
+
+		if err != nil {
+			return err
+		}
+		book.ISBN = &normalized
+	}
+
+	if book.Language != nil {
+		normalized, err := normalizeLanguage(*book.Language)
+
+		if err != nil {
+			return err
+		}
+		book.Language = &normalized
+	}
+
+	if book.TranslationOfID != nil {
+		if err := checkOriginal(tx, *book.TranslationOfID); err != nil {
+			return err
+		}
+	}
+
+	if err := assignWork(tx, book); err != nil {
+		return err
+	}
+
+	conditions := models.Book{Title: book.Title, Edition: book.Edition, PublicationYear: book.PublicationYear}
+	book.Version = 1
+
+	result := tx.FirstOrCreate(&book, conditions)
+
+	if err := result.Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return &custom.BookAlreadyExists
+		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return &custom.PublisherNotFound
+		}
+		return err
+	}
+
 	if result.RowsAffected < 1 {
-		return uuid.Nil, &custom.BookAlreadyExists
+		return &custom.BookAlreadyExists
 	}
 
-	return book.ID, nil
+	return record(tx, auditLog(audit.EntityBook, audit.ActionCreate, book.ID, nil, book))
+}
+
+// assignWork checks the work the book joins, or creates a new work for it.
+func assignWork(tx *gorm.DB, book *models.Book) error {
+	if book.WorkID != nil {
+		if err := tx.Select("id").First(&models.Work{}, "id = ?", *book.WorkID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &custom.WorkNotFound
+			}
+			return err
+		}
+		return nil
+	}
+
+	work := models.Work{Title: book.Title}
+
+	if err := tx.Create(&work).Error; err != nil {
+		return err
+	}
+
+	book.WorkID = &work.ID
+
+	return record(tx, auditLog(audit.EntityWork, audit.ActionCreate, work.ID, nil, work))
+}
+
+// checkOriginal checks the book a translation is made from exists.
+func checkOriginal(tx *gorm.DB, id uuid.UUID) error {
+	if err := tx.Select("id").First(&models.Book{}, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &custom.OriginalBookNotFound
+		}
+		return err
+	}
+	return nil
 }
 
 func (repository *bookRepository) GetAll() ([]models.BookOut, error) {
+	db, span := startSpan(repository.db, "bookRepository.GetAll")
+	defer span.End()
+
 	var books []models.BookOut
 
-	result := repository.db.Raw(`select b.id, b.title, b.edition, b.publication_year, array_agg(a.name) as authors from book_author ba inner join books b on ba.book_id = b.id inner join authors a on ba.author_id = a.id group by b.id;`).Scan(&books)
+	result := db.Raw(repository.selectBooks() + " GROUP BY b.id;").Scan(&books)
 
 	if err := result.Error; err != nil {
 		return nil, err
 	}
 
-	return books, nil
+	return withISBN10(books), nil
 }
 
-func (repository *bookRepository) GetBookByQuery(query string) ([]models.BookOut, error) {
-	var books []models.BookOut
-	fmt.Println("****QUERY STRING DEBUG:****", query)
+func (repository *bookRepository) GetBookByQuery(query string, args []interface{}) ([]models.BookOut, error) {
+	db, span := startSpan(repository.db, "bookRepository.GetBookByQuery")
+	defer span.End()
 
-	rawQuery := `
-		SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE %s GROUP BY b.id;`
+	var books []models.BookOut
 
-	rawQuery = fmt.Sprintf(rawQuery, query)
+	rawQuery := repository.selectBooks(query) + " GROUP BY b.id;"
 
-	result := repository.db.Raw(rawQuery).Scan(&books)
+	result := db.Raw(rawQuery, args...).Scan(&books)
 
 	if err := result.Error; err != nil {
 		return nil, err
 	}
 
-	return books, nil
+	return withISBN10(books), nil
+}
+
+// GetBooksPage fetches one page of the books matching query, or of every book
+// when query is empty, along with how many books match.
+func (repository *bookRepository) GetBooksPage(query string, args []interface{}, page int, pageSize int) ([]models.BookOut, int64, error) {
+	db, span := startSpan(repository.db, "bookRepository.GetBooksPage")
+	defer span.End()
+
+	var conditions []string
+
+	if query != "" {
+		conditions = append(conditions, query)
+	}
+
+	rawQuery := repository.selectBooks(conditions...) + " GROUP BY b.id"
+
+	var total int64
+
+	if err := db.Raw("SELECT count(*) FROM ("+rawQuery+") books", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	books := []models.BookOut{}
+
+	if total < 1 {
+		return books, 0, nil
+	}
+
+	pageArgs := append(append([]interface{}{}, args...), pageSize, (page-1)*pageSize)
+
+	if err := db.Raw(rawQuery+" ORDER BY b.id LIMIT ? OFFSET ?;", pageArgs...).Scan(&books).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return withISBN10(books), total, nil
+}
+
+// EachBook streams the books matching query, or every book when query is
+// empty, to fn one row at a time.
+func (repository *bookRepository) EachBook(query string, args []interface{}, fn func(models.BookOut) error) error {
+	db, span := startSpan(repository.db, "bookRepository.EachBook")
+	defer span.End()
+
+	var conditions []string
+
+	if query != "" {
+		conditions = append(conditions, query)
+	}
+
+	return eachRow(db.Raw(repository.selectBooks(conditions...)+" GROUP BY b.id ORDER BY b.id;", args...), withEachISBN10(fn))
 }
 
 func (repository *bookRepository) GetBookByID(id uuid.UUID) (models.BookOut, error) {
+	db, span := startSpan(repository.db, "bookRepository.GetBookByID")
+	defer span.End()
+
 	var book models.BookOut
 
-	result := repository.db.Raw(`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id
-INNER JOIN authors a ON ba.author_id = a.id WHERE ba.book_id = ? GROUP BY b.id ORDER BY b.id LIMIT 1;`, id).Scan(&book)
+	result := db.Raw(repository.selectBooks("ba.book_id = ?")+" GROUP BY b.id ORDER BY b.id LIMIT 1;", id).Scan(&book)
 
 	if err := result.Error; err != nil {
 		return models.BookOut{}, err
@@ -85,46 +315,546 @@ INNER JOIN authors a ON ba.author_id = a.id WHERE ba.book_id = ? GROUP BY b.id O
 		return models.BookOut{}, &custom.BookNotFound
 	}
 
+	book.SetISBN10()
+
 	return book, nil
 }
 
 func (repository *bookRepository) GetBooksByAuthorID(authorID uuid.UUID) ([]models.BookOut, error) {
+	db, span := startSpan(repository.db, "bookRepository.GetBooksByAuthorID")
+	defer span.End()
+
 	var books []models.BookOut
 
-	result := repository.db.Raw(`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id
-		INNER JOIN authors a ON ba.author_id = a.id WHERE ba.author_id = ? GROUP BY b.id ORDER BY b.id;`, authorID).Scan(&books)
+	result := db.Raw(repository.selectBooks("ba.author_id = ?")+" GROUP BY b.id ORDER BY b.id;", authorID).Scan(&books)
 
 	if err := result.Error; err != nil {
 		return nil, err
 	}
 
-	return books, nil
+	return withISBN10(books), nil
 }
 
-func (repository *bookRepository) Update(id uuid.UUID, book *models.BookUpdate) error {
-	result := repository.db.Model(&models.Book{}).Where("id = ?", id).Updates(&models.Book{Title: book.BookInfo.Title, Edition: book.BookInfo.Edition, PublicationYear: book.BookInfo.PublicationYear})
+// EachBookByAuthorID streams the books of the author to fn one row at a time.
+func (repository *bookRepository) EachBookByAuthorID(authorID uuid.UUID, fn func(models.BookOut) error) error {
+	db, span := startSpan(repository.db, "bookRepository.EachBookByAuthorID")
+	defer span.End()
+
+	return eachRow(db.Raw(repository.selectBooks("ba.author_id = ?")+" GROUP BY b.id ORDER BY b.id;", authorID), withEachISBN10(fn))
+}
+
+// GetBooksByIDs fetches the books with the given ids in a single query.
+func (repository *bookRepository) GetBooksByIDs(ids []uuid.UUID) ([]models.BookOut, error) {
+	db, span := startSpan(repository.db, "bookRepository.GetBooksByIDs")
+	defer span.End()
+
+	var books []models.BookOut
+
+	result := db.Raw(repository.selectBooks("b.id IN ?")+" GROUP BY b.id ORDER BY b.id;", ids).Scan(&books)
 
 	if err := result.Error; err != nil {
-		return err
+		return nil, err
 	}
 
-	if result.RowsAffected < 1 {
-		return &custom.BookNothingToUpdate
+	return withISBN10(books), nil
+}
+
+// GetBooksPageByAuthorIDs fetches the same page of the books of each of the
+// given authors, keeping every contributor of each book, in three queries
+// however many authors there are: one counting the books of each author, one
+// picking the ids on the page and one fetching those books.
+func (repository *bookRepository) GetBooksPageByAuthorIDs(authorIDs []uuid.UUID, page int, pageSize int) (map[uuid.UUID]models.BookPage, error) {
+	db, span := startSpan(repository.db, "bookRepository.GetBooksPageByAuthorIDs")
+	defer span.End()
+
+	authored := `WITH authored AS (SELECT DISTINCT ba.author_id, ba.book_id FROM book_author ba INNER JOIN books b ON ba.book_id = b.id WHERE ba.author_id IN @authors`
+
+	if !repository.unscoped {
+		authored += ` AND b.deleted_at IS NULL`
 	}
 
-	return nil
+	authored += `) `
+
+	args := map[string]interface{}{"authors": authorIDs, "limit": pageSize, "offset": (page - 1) * pageSize}
+
+	var totals []struct {
+		AuthorID uuid.UUID
+		Total    int64
+	}
+
+	if err := db.Raw(authored+`SELECT author_id, count(*) AS total FROM authored GROUP BY author_id`, args).Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+
+	pages := make(map[uuid.UUID]models.BookPage, len(authorIDs))
+
+	for _, id := range authorIDs {
+		pages[id] = models.BookPage{Books: []models.BookOut{}}
+	}
+
+	for _, total := range totals {
+		pages[total.AuthorID] = models.BookPage{Books: []models.BookOut{}, Total: total.Total}
+	}
+
+	if len(totals) < 1 {
+		return pages, nil
+	}
+
+	var authorBooks []struct {
+		AuthorID uuid.UUID
+		BookID   uuid.UUID
+	}
+
+	ranked := `SELECT author_id, book_id FROM (SELECT author_id, book_id, row_number() OVER (PARTITION BY author_id ORDER BY book_id) AS position FROM authored) ranked ` +
+		`WHERE position > @offset AND position <= @offset + @limit ORDER BY author_id, position`
+
+	if err := db.Raw(authored+ranked, args).Scan(&authorBooks).Error; err != nil {
+		return nil, err
+	}
+
+	if len(authorBooks) < 1 {
+		return pages, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(authorBooks))
+
+	for _, authorBook := range authorBooks {
+		ids = append(ids, authorBook.BookID)
+	}
+
+	var books []models.BookOut
+
+	if err := db.Raw(repository.selectBooks("b.id IN ?")+" GROUP BY b.id ORDER BY b.id;", ids).Scan(&books).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]models.BookOut, len(books))
+
+	for _, book := range withISBN10(books) {
+		byID[book.ID] = book
+	}
+
+	for _, authorBook := range authorBooks {
+		if book, ok := byID[authorBook.BookID]; ok {
+			authorPage := pages[authorBook.AuthorID]
+			authorPage.Books = append(authorPage.Books, book)
+			pages[authorBook.AuthorID] = authorPage
+		}
+	}
+
+	return pages, nil
+}
+
+// GetBooksByWorkID lists the editions of a work, oldest first.
+func (repository *bookRepository) GetBooksByWorkID(workID uuid.UUID) ([]models.BookOut, error) {
+	db, span := startSpan(repository.db, "bookRepository.GetBooksByWorkID")
+	defer span.End()
+
+	var books []models.BookOut
+
+	result := db.Raw(repository.selectBooks("b.work_id = ?")+" GROUP BY b.id ORDER BY b.edition, b.publication_year;", workID).Scan(&books)
+
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+
+	return withISBN10(books), nil
 }
 
-func (repository *bookRepository) Delete(id uuid.UUID) error {
-	result := repository.db.Delete(&models.Book{}, id)
+// GetTranslations lists the books translated from the book id.
+func (repository *bookRepository) GetTranslations(id uuid.UUID) ([]models.BookOut, error) {
+	db, span := startSpan(repository.db, "bookRepository.GetTranslations")
+	defer span.End()
+
+	var books []models.BookOut
+
+	result := db.Raw(repository.selectBooks("b.translation_of_id = ?")+" GROUP BY b.id ORDER BY b.language, b.publication_year;", id).Scan(&books)
 
 	if err := result.Error; err != nil {
+		return nil, err
+	}
+
+	return withISBN10(books), nil
+}
+
+// CreateEdition creates a new edition in the work of the book id, with the
+// same contributors and genres.
+func (repository *bookRepository) CreateEdition(id uuid.UUID, edition *models.EditionIn) (uuid.UUID, error) {
+	db, span := startSpan(repository.db, "bookRepository.CreateEdition")
+	defer span.End()
+
+	var book models.Book
+
+	err := transaction(db, func(tx *gorm.DB) error {
+		source, err := lockBook(tx, id, 0)
+
+		if err != nil {
+			return err
+		}
+
+		if source.WorkID == nil {
+			if err := assignWork(tx, &source); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Book{}).Where("id = ?", id).Update("work_id", source.WorkID).Error; err != nil {
+				return err
+			}
+			invalidate(tx, bookTag(id))
+		}
+
+		var editions int64
+
+		if err := tx.Model(&models.Book{}).Where("work_id = ? AND edition = ?", source.WorkID, edition.Edition).Count(&editions).Error; err != nil {
+			return err
+		}
+
+		if editions > 0 {
+			return &custom.EditionAlreadyExists
+		}
+
+		book = models.Book{
+			Title:           source.Title,
+			Edition:         edition.Edition,
+			PublicationYear: edition.PublicationYear,
+			ISBN:            edition.ISBN,
+			PublisherID:     source.PublisherID,
+			WorkID:          source.WorkID,
+			Language:        source.Language,
+			TranslationOfID: source.TranslationOfID,
+		}
+
+		if edition.Title != "" {
+			book.Title = edition.Title
+		}
+		if edition.PublisherID != nil {
+			book.PublisherID = edition.PublisherID
+		}
+
+		if err := createBook(tx, &book); err != nil {
+			return err
+		}
+
+		var contributors []models.BookAuthor
+
+		if err := tx.Where("book_id = ?", id).Order("position").Find(&contributors).Error; err != nil {
+			return err
+		}
+
+		for _, contributor := range contributors {
+			contributor.BookID = book.ID
+
+			if err := createBookAuthor(tx, &contributor); err != nil {
+				return err
+			}
+		}
+
+		var genres []models.BookGenre
+
+		if err := tx.Where("book_id = ?", id).Find(&genres).Error; err != nil {
+			return err
+		}
+
+		if len(genres) < 1 {
+			return nil
+		}
+
+		var genreIDs []uuid.UUID
+
+		for _, genre := range genres {
+			genreIDs = append(genreIDs, genre.GenreID)
+		}
+
+		return setBookGenres(tx, book.ID, genreIDs)
+	})
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return book.ID, nil
+}
+
+// Update changes the book and, when given, replaces its contributors and
+// genres in the same transaction.
+func (repository *bookRepository) Update(id uuid.UUID, book *models.BookUpdate, version uint) error {
+	db, span := startSpan(repository.db, "bookRepository.Update")
+	defer span.End()
+
+	updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+
+	return transaction(db, func(tx *gorm.DB) error {
+		before, err := lockBook(tx, id, version)
+
+		if err != nil {
+			if errors.Is(err, &custom.BookNotFound) {
+				return &custom.BookNothingToUpdate
+			}
+			return err
+		}
+
+		after := before
+		after.Version++
+
+		if book.BookInfo.Title != "" {
+			updates["title"] = book.BookInfo.Title
+			after.Title = book.BookInfo.Title
+		}
+		if book.BookInfo.Edition != 0 {
+			updates["edition"] = book.BookInfo.Edition
+			after.Edition = book.BookInfo.Edition
+		}
+		if book.BookInfo.PublicationYear != 0 {
+			updates["publication_year"] = book.BookInfo.PublicationYear
+			after.PublicationYear = book.BookInfo.PublicationYear
+		}
+		if book.BookInfo.ISBN != "" {
+			normalized, err := normalizeISBN(book.BookInfo.ISBN)
+
+			if err != nil {
+				return err
+			}
+			updates["isbn"] = normalized
+			after.ISBN = &normalized
+		}
+		if book.BookInfo.PublisherID != uuid.Nil {
+			updates["publisher_id"] = book.BookInfo.PublisherID
+			after.PublisherID = &book.BookInfo.PublisherID
+		}
+		if book.BookInfo.Language != "" {
+			normalized, err := normalizeLanguage(book.BookInfo.Language)
+
+			if err != nil {
+				return err
+			}
+			updates["language"] = normalized
+			after.Language = &normalized
+		}
+		if book.BookInfo.TranslationOfID != uuid.Nil {
+			if book.BookInfo.TranslationOfID == id {
+				return &custom.InvalidTranslation
+			}
+			if err := checkOriginal(tx, book.BookInfo.TranslationOfID); err != nil {
+				return err
+			}
+			updates["translation_of_id"] = book.BookInfo.TranslationOfID
+			after.TranslationOfID = &book.BookInfo.TranslationOfID
+		}
+
+		result := tx.Model(&models.Book{}).Where("id = ?", id).Updates(updates)
+
+		if err := result.Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return &custom.BookAlreadyExists
+			}
+			if errors.Is(err, gorm.ErrForeignKeyViolated) {
+				return &custom.PublisherNotFound
+			}
+			return err
+		}
+
+		if result.RowsAffected < 1 {
+			return &custom.BookNothingToUpdate
+		}
+
+		if err := record(tx, auditLog(audit.EntityBook, audit.ActionUpdate, id, before, after)); err != nil {
+			return err
+		}
+
+		if contributors := book.BookAuthors(id); len(contributors) > 0 {
+			if err := replaceBookAuthors(tx, id, contributors); err != nil {
+				return err
+			}
+		}
+
+		if len(book.GenresID) > 0 {
+			return setBookGenres(tx, id, book.GenresID)
+		}
+		return nil
+	})
+}
+
+func setBookGenres(tx *gorm.DB, bookID uuid.UUID, genreIDs []uuid.UUID) error {
+	var before []models.BookGenre
+
+	if err := tx.Where("book_id = ?", bookID).Find(&before).Error; err != nil {
 		return err
 	}
 
-	if result.RowsAffected < 1 {
-		return &custom.BookNotFound
+	if len(before) > 0 {
+		if err := tx.Delete(&models.BookGenre{}, "book_id = ?", bookID).Error; err != nil {
+			return err
+		}
 	}
 
-	return nil
+	var after []models.BookGenre
+	seen := map[uuid.UUID]bool{}
+
+	for _, genreID := range genreIDs {
+		if seen[genreID] {
+			continue
+		}
+		seen[genreID] = true
+		after = append(after, models.BookGenre{BookID: bookID, GenreID: genreID})
+	}
+
+	if len(after) > 0 {
+		if err := tx.Create(&after).Error; err != nil {
+			if errors.Is(err, gorm.ErrForeignKeyViolated) {
+				return &custom.GenreNotFound
+			}
+			return err
+		}
+	}
+
+	if len(before) < 1 && len(after) < 1 {
+		return nil
+	}
+
+	return record(tx, auditLog(audit.EntityBookGenre, audit.ActionUpdate, bookID, before, after))
+}
+
+func (repository *bookRepository) Delete(id uuid.UUID, version uint) error {
+	db, span := startSpan(repository.db, "bookRepository.Delete")
+	defer span.End()
+
+	return transaction(db, func(tx *gorm.DB) error {
+		before, err := lockBook(tx, id, version)
+
+		if err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Book{}, id)
+
+		if err := result.Error; err != nil {
+			return err
+		}
+
+		if result.RowsAffected < 1 {
+			return &custom.BookNotFound
+		}
+
+		return record(tx, auditLog(audit.EntityBook, audit.ActionDelete, id, before, nil))
+	})
+}
+
+func (repository *bookRepository) Restore(id uuid.UUID) error {
+	db, span := startSpan(repository.db, "bookRepository.Restore")
+	defer span.End()
+
+	return transaction(db, func(tx *gorm.DB) error {
+		var before models.Book
+
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&before, "id = ? AND deleted_at IS NOT NULL", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &custom.BookNotFound
+			}
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Book{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			// Another book may have taken the ISBN since this one was deleted.
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return &custom.BookAlreadyExists
+			}
+			return err
+		}
+
+		after := before
+		after.DeletedAt = models.DeletedAt{}
+		after.Version++
+
+		return record(tx, auditLog(audit.EntityBook, audit.ActionRestore, id, before, after))
+	})
+}
+
+func (repository *bookRepository) Purge(deletedBefore time.Time) (int64, error) {
+	db, span := startSpan(repository.db, "bookRepository.Purge")
+	defer span.End()
+
+	var purged []models.Book
+
+	err := transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at < ?", deletedBefore).Find(&purged).Error; err != nil {
+			return err
+		}
+
+		if len(purged) < 1 {
+			return nil
+		}
+
+		var logs []models.AuditLog
+
+		for _, book := range purged {
+			logs = append(logs, auditLog(audit.EntityBook, audit.ActionPurge, book.ID, book, nil))
+		}
+
+		if err := tx.Unscoped().Delete(&purged).Error; err != nil {
+			return err
+		}
+
+		return record(tx, logs...)
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(purged)), nil
+}
+
+// withISBN10 sets the ISBN-10 of books read, derived from their ISBN-13.
+func withISBN10(books []models.BookOut) []models.BookOut {
+	for i := range books {
+		books[i].SetISBN10()
+	}
+
+	return books
+}
+
+func withEachISBN10(fn func(models.BookOut) error) func(models.BookOut) error {
+	return func(book models.BookOut) error {
+		book.SetISBN10()
+		return fn(book)
+	}
+}
+
+// normalizeISBN stores every ISBN as ISBN-13 without hyphens, so the unique
+// constraint also catches the same book sent as ISBN-10.
+func normalizeISBN(raw string) (string, error) {
+	normalized, ok := isbn.Normalize(raw)
+
+	if !ok {
+		return "", &custom.InvalidISBN
+	}
+	return normalized, nil
+}
+
+// normalizeLanguage stores every language as a lowercase ISO 639-1 code.
+func normalizeLanguage(raw string) (string, error) {
+	normalized, ok := language.Normalize(raw)
+
+	if !ok {
+		return "", &custom.InvalidLanguage
+	}
+	return normalized, nil
+}
+
+func lockBook(tx *gorm.DB, id uuid.UUID, version uint) (models.Book, error) {
+	var book models.Book
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Book{}, &custom.BookNotFound
+		}
+		return models.Book{}, err
+	}
+
+	if version != 0 && book.Version != version {
+		return models.Book{}, &custom.BookVersionMismatch
+	}
+
+	return book, nil
 }
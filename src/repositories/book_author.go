@@ -1,16 +1,19 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
 	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
 	"github.com/joaooliveira247/go_olist_challenge/src/models"
 )
 
 type BookAuthorRepository interface {
+	WithContext(ctx context.Context) BookAuthorRepository
 	Create(relationship *models.BookAuthor) error
 	Delete(bookID uuid.UUID) error
 }
@@ -23,19 +26,70 @@ func NewBookAuthorRepository(db *gorm.DB) BookAuthorRepository {
 	return &bookAuthorRepository{db}
 }
 
+func (repository *bookAuthorRepository) WithContext(ctx context.Context) BookAuthorRepository {
+	return &bookAuthorRepository{repository.db.WithContext(ctx)}
+}
+
 func (repository *bookAuthorRepository) Create(relationship *models.BookAuthor) error {
-	if err := repository.db.Create(&relationship).Error; err != nil {
+	db, span := startSpan(repository.db, "bookAuthorRepository.Create")
+	defer span.End()
+
+	return transaction(db, func(tx *gorm.DB) error {
+		return createBookAuthor(tx, relationship)
+	})
+}
+
+func createBookAuthor(tx *gorm.DB, relationship *models.BookAuthor) error {
+	if err := tx.Create(&relationship).Error; err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			return &custom.RelationshipAlreadyExists
 		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return &custom.AuthorNotFound
+		}
 		return err
 	}
-	return nil
+
+	return record(tx, auditLog(audit.EntityBookAuthor, audit.ActionCreate, relationship.BookID, nil, relationship))
 }
 
 func (repository *bookAuthorRepository) Delete(bookID uuid.UUID) error {
-	if err := repository.db.Delete(&models.BookAuthor{}, "book_id = ?", bookID).Error; err != nil {
+	db, span := startSpan(repository.db, "bookAuthorRepository.Delete")
+	defer span.End()
+
+	return transaction(db, func(tx *gorm.DB) error {
+		return deleteBookAuthors(tx, bookID)
+	})
+}
+
+func deleteBookAuthors(tx *gorm.DB, bookID uuid.UUID) error {
+	var before []models.BookAuthor
+
+	if err := tx.Where("book_id = ?", bookID).Find(&before).Error; err != nil {
 		return err
 	}
+
+	if len(before) < 1 {
+		return nil
+	}
+
+	if err := tx.Delete(&models.BookAuthor{}, "book_id = ?", bookID).Error; err != nil {
+		return err
+	}
+
+	return record(tx, auditLog(audit.EntityBookAuthor, audit.ActionDelete, bookID, before, nil))
+}
+
+// replaceBookAuthors swaps the contributors of the book for relationships.
+func replaceBookAuthors(tx *gorm.DB, bookID uuid.UUID, relationships []models.BookAuthor) error {
+	if err := deleteBookAuthors(tx, bookID); err != nil {
+		return err
+	}
+
+	for _, relationship := range relationships {
+		if err := createBookAuthor(tx, &relationship); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,418 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+)
+
+// Tags of the cached reads. An entry is tagged with every entity it was built
+// from, and lists also with the collection whose membership they depend on.
+const (
+	tagAuthors = "authors"
+	tagBooks   = "books"
+	tagGenres  = "genres"
+)
+
+func authorTag(id uuid.UUID) string {
+	return "author:" + id.String()
+}
+
+func bookTag(id uuid.UUID) string {
+	return "book:" + id.String()
+}
+
+func publisherTag(id uuid.UUID) string {
+	return "publisher:" + id.String()
+}
+
+// changedTags answers the tags of the entries a write recorded in the audit log
+// makes stale.
+func changedTags(log models.AuditLog) []string {
+	switch log.Entity {
+	case audit.EntityAuthor:
+		if log.Action == audit.ActionRestore {
+			return []string{authorTag(log.EntityID), tagAuthors, tagBooks}
+		}
+		return []string{authorTag(log.EntityID), tagAuthors}
+	case audit.EntityBook, audit.EntityBookAuthor, audit.EntityBookGenre:
+		return []string{bookTag(log.EntityID), tagBooks}
+	case audit.EntityGenre:
+		return []string{tagGenres}
+	case audit.EntityPublisher:
+		return []string{publisherTag(log.EntityID)}
+	default:
+		return nil
+	}
+}
+
+// invalidate defers the invalidation of tags to the commit of the transaction
+// of db, invalidating them right away outside of one.
+func invalidate(db *gorm.DB, tags ...string) {
+	if pending, ok := pendingOf(db); ok {
+		pending.tags = append(pending.tags, tags...)
+		return
+	}
+
+	cache.Shared().Invalidate(tags...)
+}
+
+func authorTags(authors ...models.Author) []string {
+	var tags []string
+
+	for _, author := range authors {
+		tags = append(tags, authorTag(author.ID))
+	}
+
+	return tags
+}
+
+func bookTags(books ...models.BookOut) []string {
+	var tags []string
+
+	for _, book := range books {
+		tags = append(tags, bookTag(book.ID))
+
+		for _, contributor := range book.Contributors {
+			tags = append(tags, authorTag(contributor.AuthorID))
+		}
+		if book.PublisherID != nil {
+			tags = append(tags, publisherTag(*book.PublisherID))
+		}
+		if len(book.GenresName) > 0 {
+			tags = append(tags, tagGenres)
+		}
+	}
+
+	return tags
+}
+
+func bookListTags(books []models.BookOut) []string {
+	return append(bookTags(books...), tagBooks, tagGenres)
+}
+
+type cachedAuthorRepository struct {
+	AuthorRepository
+	cache       *cache.Cache
+	ctx         context.Context
+	nationality string
+}
+
+// NewCachedAuthorRepository caches the reads of repository. Its writes go
+// straight to repository, which invalidates the entries they make stale.
+func NewCachedAuthorRepository(repository AuthorRepository, readCache *cache.Cache) AuthorRepository {
+	if readCache == nil {
+		return repository
+	}
+	return &cachedAuthorRepository{AuthorRepository: repository, cache: readCache, ctx: context.Background()}
+}
+
+func (repository *cachedAuthorRepository) WithContext(ctx context.Context) AuthorRepository {
+	return &cachedAuthorRepository{repository.AuthorRepository.WithContext(ctx), repository.cache, ctx, repository.nationality}
+}
+
+// Unscoped reads skip the cache, as they serve the rare lookups of deleted
+// authors.
+func (repository *cachedAuthorRepository) Unscoped() AuthorRepository {
+	return repository.AuthorRepository.Unscoped()
+}
+
+func (repository *cachedAuthorRepository) WithNationality(code string) AuthorRepository {
+	return &cachedAuthorRepository{repository.AuthorRepository.WithNationality(code), repository.cache, repository.ctx, code}
+}
+
+func (repository *cachedAuthorRepository) key(method string, args ...interface{}) string {
+	return fmt.Sprintf("author.%s:%s:%v", method, repository.nationality, args)
+}
+
+func (repository *cachedAuthorRepository) GetAll() ([]models.Author, error) {
+	return cachedList(repository.ctx, repository.cache, repository.key("GetAll"), repository.AuthorRepository.GetAll, func(authors []models.Author) []string {
+		return append(authorTags(authors...), tagAuthors)
+	}, cloneAuthor)
+}
+
+func (repository *cachedAuthorRepository) GetPage(page int, pageSize int) ([]models.Author, int64, error) {
+	return cachedPage(repository.ctx, repository.cache, repository.key("GetPage", page, pageSize), func() ([]models.Author, int64, error) {
+		return repository.AuthorRepository.GetPage(page, pageSize)
+	}, func(authors []models.Author) []string {
+		return append(authorTags(authors...), tagAuthors)
+	}, cloneAuthor)
+}
+
+func (repository *cachedAuthorRepository) GetByID(id uuid.UUID) (models.Author, error) {
+	author, err := cache.Fetch(repository.ctx, repository.cache, repository.key("GetByID", id), func() (models.Author, error) {
+		return repository.AuthorRepository.GetByID(id)
+	}, func(author models.Author) []string {
+		return []string{authorTag(id)}
+	})
+
+	return cloneAuthor(author), err
+}
+
+func (repository *cachedAuthorRepository) GetByIDs(ids []uuid.UUID) ([]models.Author, error) {
+	return cachedList(repository.ctx, repository.cache, repository.key("GetByIDs", ids), func() ([]models.Author, error) {
+		return repository.AuthorRepository.GetByIDs(ids)
+	}, func([]models.Author) []string {
+		var tags []string
+
+		for _, id := range ids {
+			tags = append(tags, authorTag(id))
+		}
+
+		return tags
+	}, cloneAuthor)
+}
+
+func (repository *cachedAuthorRepository) GetByName(name string) ([]models.Author, error) {
+	return cachedList(repository.ctx, repository.cache, repository.key("GetByName", name), func() ([]models.Author, error) {
+		return repository.AuthorRepository.GetByName(name)
+	}, func(authors []models.Author) []string {
+		return append(authorTags(authors...), tagAuthors)
+	}, cloneAuthor)
+}
+
+func (repository *cachedAuthorRepository) GetBySimilarName(name string, threshold float64) ([]models.AuthorMatch, error) {
+	return cachedList(repository.ctx, repository.cache, repository.key("GetBySimilarName", name, threshold), func() ([]models.AuthorMatch, error) {
+		return repository.AuthorRepository.GetBySimilarName(name, threshold)
+	}, func(matches []models.AuthorMatch) []string {
+		tags := []string{tagAuthors}
+
+		for _, match := range matches {
+			tags = append(tags, authorTag(match.ID))
+		}
+
+		return tags
+	}, func(match models.AuthorMatch) models.AuthorMatch {
+		match.Author = cloneAuthor(match.Author)
+		return match
+	})
+}
+
+func (repository *cachedAuthorRepository) GetPageBySimilarName(name string, threshold float64, page int, pageSize int) ([]models.AuthorMatch, int64, error) {
+	return cachedPage(repository.ctx, repository.cache, repository.key("GetPageBySimilarName", name, threshold, page, pageSize), func() ([]models.AuthorMatch, int64, error) {
+		return repository.AuthorRepository.GetPageBySimilarName(name, threshold, page, pageSize)
+	}, func(matches []models.AuthorMatch) []string {
+		tags := []string{tagAuthors}
+
+		for _, match := range matches {
+			tags = append(tags, authorTag(match.ID))
+		}
+
+		return tags
+	}, func(match models.AuthorMatch) models.AuthorMatch {
+		match.Author = cloneAuthor(match.Author)
+		return match
+	})
+}
+
+type cachedBookRepository struct {
+	BookRepository
+	cache *cache.Cache
+	ctx   context.Context
+}
+
+// NewCachedBookRepository caches the reads of repository. Its writes go
+// straight to repository, which invalidates the entries they make stale.
+func NewCachedBookRepository(repository BookRepository, readCache *cache.Cache) BookRepository {
+	if readCache == nil {
+		return repository
+	}
+	return &cachedBookRepository{repository, readCache, context.Background()}
+}
+
+func (repository *cachedBookRepository) WithContext(ctx context.Context) BookRepository {
+	return &cachedBookRepository{repository.BookRepository.WithContext(ctx), repository.cache, ctx}
+}
+
+// Unscoped reads skip the cache, as they serve the rare lookups of deleted
+// books.
+func (repository *cachedBookRepository) Unscoped() BookRepository {
+	return repository.BookRepository.Unscoped()
+}
+
+func (repository *cachedBookRepository) list(key string, load func() ([]models.BookOut, error)) ([]models.BookOut, error) {
+	return cachedList(repository.ctx, repository.cache, key, load, bookListTags, cloneBook)
+}
+
+func (repository *cachedBookRepository) GetAll() ([]models.BookOut, error) {
+	return repository.list("book.GetAll", repository.BookRepository.GetAll)
+}
+
+func (repository *cachedBookRepository) GetBookByQuery(query string, args []interface{}) ([]models.BookOut, error) {
+	return repository.list(fmt.Sprintf("book.GetBookByQuery:%s:%#v", query, args), func() ([]models.BookOut, error) {
+		return repository.BookRepository.GetBookByQuery(query, args)
+	})
+}
+
+func (repository *cachedBookRepository) GetBooksPage(query string, args []interface{}, page int, pageSize int) ([]models.BookOut, int64, error) {
+	return cachedPage(repository.ctx, repository.cache, fmt.Sprintf("book.GetBooksPage:%s:%#v:%d:%d", query, args, page, pageSize), func() ([]models.BookOut, int64, error) {
+		return repository.BookRepository.GetBooksPage(query, args, page, pageSize)
+	}, bookListTags, cloneBook)
+}
+
+func (repository *cachedBookRepository) GetBookByID(id uuid.UUID) (models.BookOut, error) {
+	book, err := cache.Fetch(repository.ctx, repository.cache, "book.GetBookByID:"+id.String(), func() (models.BookOut, error) {
+		return repository.BookRepository.GetBookByID(id)
+	}, func(book models.BookOut) []string {
+		return bookTags(book)
+	})
+
+	return cloneBook(book), err
+}
+
+func (repository *cachedBookRepository) GetBooksByIDs(ids []uuid.UUID) ([]models.BookOut, error) {
+	return cachedList(repository.ctx, repository.cache, fmt.Sprintf("book.GetBooksByIDs:%v", ids), func() ([]models.BookOut, error) {
+		return repository.BookRepository.GetBooksByIDs(ids)
+	}, func(books []models.BookOut) []string {
+		tags := bookTags(books...)
+
+		for _, id := range ids {
+			tags = append(tags, bookTag(id))
+		}
+
+		return tags
+	}, cloneBook)
+}
+
+func (repository *cachedBookRepository) GetBooksByAuthorID(authorID uuid.UUID) ([]models.BookOut, error) {
+	return repository.list("book.GetBooksByAuthorID:"+authorID.String(), func() ([]models.BookOut, error) {
+		return repository.BookRepository.GetBooksByAuthorID(authorID)
+	})
+}
+
+func (repository *cachedBookRepository) GetBooksPageByAuthorIDs(authorIDs []uuid.UUID, page int, pageSize int) (map[uuid.UUID]models.BookPage, error) {
+	pages, err := cache.Fetch(repository.ctx, repository.cache, fmt.Sprintf("book.GetBooksPageByAuthorIDs:%v:%d:%d", authorIDs, page, pageSize), func() (map[uuid.UUID]models.BookPage, error) {
+		return repository.BookRepository.GetBooksPageByAuthorIDs(authorIDs, page, pageSize)
+	}, func(pages map[uuid.UUID]models.BookPage) []string {
+		var books []models.BookOut
+
+		for _, authorPage := range pages {
+			books = append(books, authorPage.Books...)
+		}
+
+		return bookListTags(books)
+	})
+
+	if err != nil || pages == nil {
+		return nil, err
+	}
+
+	copied := make(map[uuid.UUID]models.BookPage, len(pages))
+
+	for id, authorPage := range pages {
+		books := make([]models.BookOut, len(authorPage.Books))
+
+		for i, book := range authorPage.Books {
+			books[i] = cloneBook(book)
+		}
+
+		copied[id] = models.BookPage{Books: books, Total: authorPage.Total}
+	}
+
+	return copied, nil
+}
+
+func (repository *cachedBookRepository) GetBooksByWorkID(workID uuid.UUID) ([]models.BookOut, error) {
+	return repository.list("book.GetBooksByWorkID:"+workID.String(), func() ([]models.BookOut, error) {
+		return repository.BookRepository.GetBooksByWorkID(workID)
+	})
+}
+
+func (repository *cachedBookRepository) GetTranslations(id uuid.UUID) ([]models.BookOut, error) {
+	return repository.list("book.GetTranslations:"+id.String(), func() ([]models.BookOut, error) {
+		return repository.BookRepository.GetTranslations(id)
+	})
+}
+
+// cachedList answers a copy of the cached list with every item copied by clone,
+// so callers changing it leave the cached one untouched.
+func cachedList[T any](ctx context.Context, readCache *cache.Cache, key string, load func() ([]T, error), tags func([]T) []string, clone func(T) T) ([]T, error) {
+	list, err := cache.Fetch(ctx, readCache, key, load, tags)
+
+	if err != nil || list == nil {
+		return nil, err
+	}
+
+	copied := make([]T, len(list))
+
+	for i, item := range list {
+		copied[i] = clone(item)
+	}
+
+	return copied, nil
+}
+
+// cachedPage caches a page of a list along with the size of the whole list,
+// answering copies of its items like cachedList.
+func cachedPage[T any](ctx context.Context, readCache *cache.Cache, key string, load func() ([]T, int64, error), tags func([]T) []string, clone func(T) T) ([]T, int64, error) {
+	type page struct {
+		items []T
+		total int64
+	}
+
+	cached, err := cache.Fetch(ctx, readCache, key, func() (page, error) {
+		items, total, err := load()
+		return page{items, total}, err
+	}, func(cached page) []string {
+		return tags(cached.items)
+	})
+
+	if err != nil || cached.items == nil {
+		return nil, 0, err
+	}
+
+	copied := make([]T, len(cached.items))
+
+	for i, item := range cached.items {
+		copied[i] = clone(item)
+	}
+
+	return copied, cached.total, nil
+}
+
+// clonePointer answers a pointer to a copy of the value of pointer.
+func clonePointer[T any](pointer *T) *T {
+	if pointer == nil {
+		return nil
+	}
+
+	copied := *pointer
+
+	return &copied
+}
+
+// cloneAuthor answers author with its optional fields copied, so it shares
+// nothing with the cached one.
+func cloneAuthor(author models.Author) models.Author {
+	author.SortName = clonePointer(author.SortName)
+	author.Biography = clonePointer(author.Biography)
+	author.BirthYear = clonePointer(author.BirthYear)
+	author.DeathYear = clonePointer(author.DeathYear)
+	author.Nationality = clonePointer(author.Nationality)
+	author.Website = clonePointer(author.Website)
+
+	return author
+}
+
+// cloneBook answers book with its optional fields and its authors,
+// contributors and genres copied, so it shares nothing with the cached one.
+// The Publisher, Work and TranslationOf associations are never loaded by reads.
+func cloneBook(book models.BookOut) models.BookOut {
+	book.ISBN = clonePointer(book.ISBN)
+	book.ISBN10 = clonePointer(book.ISBN10)
+	book.PublisherID = clonePointer(book.PublisherID)
+	book.WorkID = clonePointer(book.WorkID)
+	book.Language = clonePointer(book.Language)
+	book.TranslationOfID = clonePointer(book.TranslationOfID)
+	book.AuthorsName = slices.Clone(book.AuthorsName)
+	book.Contributors = slices.Clone(book.Contributors)
+	book.GenresName = slices.Clone(book.GenresName)
+
+	return book
+}
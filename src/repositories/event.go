@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+)
+
+// EventRepository reads the catalog change events of the outbox in the order
+// of their IDs.
+type EventRepository interface {
+	WithContext(ctx context.Context) EventRepository
+	GetAfter(after uint64, until uint64, entities []string, limit int) ([]models.OutboxEvent, error)
+	LastID() (uint64, error)
+}
+
+type eventRepository struct {
+	db *gorm.DB
+}
+
+func NewEventRepository(db *gorm.DB) EventRepository {
+	return &eventRepository{db}
+}
+
+func (repository *eventRepository) WithContext(ctx context.Context) EventRepository {
+	return &eventRepository{repository.db.WithContext(ctx)}
+}
+
+// GetAfter answers up to limit events with an ID above after, and up to until
+// unless it is 0, about any of entities, or every entity when it is empty.
+func (repository *eventRepository) GetAfter(after uint64, until uint64, entities []string, limit int) ([]models.OutboxEvent, error) {
+	db, span := startSpan(repository.db, "eventRepository.GetAfter")
+	defer span.End()
+
+	query := db.Where("id > ?", after)
+
+	if until > 0 {
+		query = query.Where("id <= ?", until)
+	}
+
+	if len(entities) > 0 {
+		query = query.Where("split_part(type, '.', 1) IN ?", entities)
+	}
+
+	events := []models.OutboxEvent{}
+
+	if err := query.Order("id").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// LastID answers the ID of the newest event, 0 without any.
+func (repository *eventRepository) LastID() (uint64, error) {
+	db, span := startSpan(repository.db, "eventRepository.LastID")
+	defer span.End()
+
+	var last uint64
+
+	if err := db.Model(&models.OutboxEvent{}).Select("coalesce(max(id), 0)").Scan(&last).Error; err != nil {
+		return 0, err
+	}
+
+	return last, nil
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository keeps the responses to the creates sent with an
+// Idempotency-Key.
+type IdempotencyRepository interface {
+	WithContext(ctx context.Context) IdempotencyRepository
+	Reserve(key *models.IdempotencyKey) (*models.IdempotencyKey, bool, error)
+	Complete(client string, key string, statusCode int, contentType string, body []byte) error
+	Release(client string, key string) error
+	DeleteExpired() (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db}
+}
+
+func (repository *idempotencyRepository) WithContext(ctx context.Context) IdempotencyRepository {
+	return &idempotencyRepository{repository.db.WithContext(ctx)}
+}
+
+// Reserve takes key for the request handling it, answering true, or answers
+// the unexpired key already taken by an earlier request and false.
+func (repository *idempotencyRepository) Reserve(key *models.IdempotencyKey) (*models.IdempotencyKey, bool, error) {
+	db, span := startSpan(repository.db, "idempotencyRepository.Reserve")
+	defer span.End()
+
+	if err := db.Where("client = ? AND key = ? AND expires_at <= ?", key.Client, key.Key, time.Now()).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, false, err
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+
+	if err := result.Error; err != nil {
+		return nil, false, err
+	}
+
+	if result.RowsAffected > 0 {
+		return key, true, nil
+	}
+
+	var existing models.IdempotencyKey
+
+	if err := db.Where("client = ? AND key = ?", key.Client, key.Key).First(&existing).Error; err != nil {
+		return nil, false, err
+	}
+
+	return &existing, false, nil
+}
+
+// Complete stores the response to the request that reserved key.
+func (repository *idempotencyRepository) Complete(client string, key string, statusCode int, contentType string, body []byte) error {
+	db, span := startSpan(repository.db, "idempotencyRepository.Complete")
+	defer span.End()
+
+	return db.Model(&models.IdempotencyKey{}).Where("client = ? AND key = ?", client, key).Updates(map[string]interface{}{
+		"status_code":  statusCode,
+		"content_type": contentType,
+		"body":         body,
+	}).Error
+}
+
+// Release frees a key whose request failed without a response worth
+// replaying, so that it can be retried.
+func (repository *idempotencyRepository) Release(client string, key string) error {
+	db, span := startSpan(repository.db, "idempotencyRepository.Release")
+	defer span.End()
+
+	return db.Where("client = ? AND key = ? AND status_code = 0", client, key).Delete(&models.IdempotencyKey{}).Error
+}
+
+func (repository *idempotencyRepository) DeleteExpired() (int64, error) {
+	db, span := startSpan(repository.db, "idempotencyRepository.DeleteExpired")
+	defer span.End()
+
+	result := db.Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyKey{})
+
+	return result.RowsAffected, result.Error
+}
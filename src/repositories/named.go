@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NamedRepository is the CRUD shared by the entities that only carry a
+// unique name, like publishers and genres.
+type NamedRepository[T any] interface {
+	WithContext(ctx context.Context) NamedRepository[T]
+	Create(entity *T) (uuid.UUID, error)
+	GetAll() ([]T, error)
+	GetByID(id uuid.UUID) (T, error)
+	GetByName(name string) ([]T, error)
+	Update(id uuid.UUID, entity *T) error
+	Delete(id uuid.UUID) error
+}
+
+type PublisherRepository = NamedRepository[models.Publisher]
+
+type GenreRepository = NamedRepository[models.Genre]
+
+type named[T any] interface {
+	*T
+	GetID() uuid.UUID
+	GetName() string
+	SetName(name string)
+}
+
+type namedRepository[T any, P named[T]] struct {
+	db            *gorm.DB
+	entity        string
+	alreadyExists error
+	notFound      error
+}
+
+// NewPublisherRepository deletes publishers without their books, which lose
+// the publisher through the ON DELETE SET NULL foreign key.
+func NewPublisherRepository(db *gorm.DB) PublisherRepository {
+	return &namedRepository[models.Publisher, *models.Publisher]{
+		db, audit.EntityPublisher, &custom.PublisherAlreadyExists, &custom.PublisherNotFound,
+	}
+}
+
+// NewGenreRepository deletes genres with their book_genre rows, which go
+// through the ON DELETE CASCADE foreign key.
+func NewGenreRepository(db *gorm.DB) GenreRepository {
+	return &namedRepository[models.Genre, *models.Genre]{
+		db, audit.EntityGenre, &custom.GenreAlreadyExists, &custom.GenreNotFound,
+	}
+}
+
+func (repository *namedRepository[T, P]) WithContext(ctx context.Context) NamedRepository[T] {
+	scoped := *repository
+	scoped.db = repository.db.WithContext(ctx)
+	return &scoped
+}
+
+func (repository *namedRepository[T, P]) Create(entity *T) (uuid.UUID, error) {
+	db, span := startSpan(repository.db, repository.entity+"Repository.Create")
+	defer span.End()
+
+	err := transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Create(entity).Error; err != nil {
+			return err
+		}
+
+		return record(tx, auditLog(repository.entity, audit.ActionCreate, P(entity).GetID(), nil, entity))
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return uuid.Nil, repository.alreadyExists
+		}
+		return uuid.Nil, err
+	}
+
+	return P(entity).GetID(), nil
+}
+
+func (repository *namedRepository[T, P]) GetAll() ([]T, error) {
+	db, span := startSpan(repository.db, repository.entity+"Repository.GetAll")
+	defer span.End()
+
+	var entities []T
+
+	if err := db.Order("name").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+func (repository *namedRepository[T, P]) GetByID(id uuid.UUID) (T, error) {
+	db, span := startSpan(repository.db, repository.entity+"Repository.GetByID")
+	defer span.End()
+
+	var entity T
+
+	if err := db.First(&entity, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return *new(T), repository.notFound
+		}
+		return *new(T), err
+	}
+
+	return entity, nil
+}
+
+// GetByName matches the entities whose name contains name, ignoring case and
+// accents.
+func (repository *namedRepository[T, P]) GetByName(name string) ([]T, error) {
+	db, span := startSpan(repository.db, repository.entity+"Repository.GetByName")
+	defer span.End()
+
+	var entities []T
+
+	result := db.Where(
+		"immutable_unaccent(lower(name)) LIKE immutable_unaccent(lower(?))", fmt.Sprintf("%%%s%%", name),
+	).Order("name").Find(&entities)
+
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+func (repository *namedRepository[T, P]) Update(id uuid.UUID, entity *T) error {
+	db, span := startSpan(repository.db, repository.entity+"Repository.Update")
+	defer span.End()
+
+	name := P(entity).GetName()
+
+	err := transaction(db, func(tx *gorm.DB) error {
+		before, err := repository.lock(tx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(new(T)).Where("id = ?", id).Update("name", name).Error; err != nil {
+			return err
+		}
+
+		after := before
+		P(&after).SetName(name)
+
+		return record(tx, auditLog(repository.entity, audit.ActionUpdate, id, before, after))
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return repository.alreadyExists
+	}
+	return err
+}
+
+func (repository *namedRepository[T, P]) Delete(id uuid.UUID) error {
+	db, span := startSpan(repository.db, repository.entity+"Repository.Delete")
+	defer span.End()
+
+	return transaction(db, func(tx *gorm.DB) error {
+		before, err := repository.lock(tx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(new(T), "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return record(tx, auditLog(repository.entity, audit.ActionDelete, id, before, nil))
+	})
+}
+
+func (repository *namedRepository[T, P]) lock(tx *gorm.DB, id uuid.UUID) (T, error) {
+	var entity T
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entity, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return *new(T), repository.notFound
+		}
+		return *new(T), err
+	}
+
+	return entity, nil
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"encoding/json"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+)
+
+var eventVerbs = map[string]string{
+	audit.ActionCreate:  "created",
+	audit.ActionUpdate:  "updated",
+	audit.ActionDelete:  "deleted",
+	audit.ActionRestore: "restored",
+	audit.ActionPurge:   "purged",
+}
+
+// changedEvent answers the event a write recorded in the audit log publishes.
+// Changes to the authors or genres of a book update the book.
+func changedEvent(log models.AuditLog) (models.OutboxEvent, bool) {
+	var eventType string
+
+	switch log.Entity {
+	case audit.EntityAuthor, audit.EntityBook:
+		eventType = log.Entity + "." + eventVerbs[log.Action]
+	case audit.EntityBookAuthor, audit.EntityBookGenre:
+		eventType = models.EventBookUpdated
+	default:
+		return models.OutboxEvent{}, false
+	}
+
+	data := models.EventData{ID: log.EntityID, Entity: log.Entity, Before: log.Before, After: log.After}
+
+	return models.OutboxEvent{Type: eventType, EntityID: log.EntityID, Payload: models.NewJSON(data)}, true
+}
+
+// publish defers events to the commit of the transaction of db, writing them
+// right away outside of one.
+func publish(db *gorm.DB, events ...models.OutboxEvent) error {
+	if pending, ok := pendingOf(db); ok {
+		for _, event := range events {
+			pending.events = mergeEvent(pending.events, event)
+		}
+		return nil
+	}
+
+	return writeOutbox(db, events)
+}
+
+// mergeEvent adds event to events unless it only tells that a book changed
+// again in the same transaction. The change of the book itself is kept over
+// the changes of its authors or genres.
+func mergeEvent(events []models.OutboxEvent, event models.OutboxEvent) []models.OutboxEvent {
+	if event.Type != models.EventBookUpdated {
+		return append(events, event)
+	}
+
+	for i, published := range events {
+		if published.EntityID != event.EntityID {
+			continue
+		}
+
+		switch published.Type {
+		case models.EventBookCreated, models.EventBookDeleted, models.EventBookRestored, models.EventBookPurged:
+			return events
+		case models.EventBookUpdated:
+			if eventEntity(event) == audit.EntityBook {
+				events[i] = event
+			}
+			return events
+		}
+	}
+
+	return append(events, event)
+}
+
+func eventEntity(event models.OutboxEvent) string {
+	var data models.EventData
+
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		return ""
+	}
+
+	return data.Entity
+}
+
+func writeOutbox(db *gorm.DB, events []models.OutboxEvent) error {
+	if len(events) < 1 {
+		return nil
+	}
+
+	return db.Create(&events).Error
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// eachRow runs query on a cursor and hands its rows to fn one at a time, so
+// that listings of any size are never held in memory. It stops at the first
+// error, from the database or from fn.
+func eachRow[T any](query *gorm.DB, fn func(T) error) error {
+	rows, err := query.Rows()
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+
+		if err := query.ScanRows(rows, &row); err != nil {
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+)
+
+type SearchRepository interface {
+	WithContext(ctx context.Context) SearchRepository
+	SearchBooks(query string, page int, pageSize int) ([]models.BookSearchResult, int64, error)
+}
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+// Titles and author names are indexed with both the English and the
+// Portuguese dictionaries, so the query is parsed with both as well.
+const (
+	searchWith = `WITH q AS (SELECT english, portuguese, english || portuguese AS query FROM ` +
+		`(SELECT websearch_to_tsquery('english', @query) AS english, websearch_to_tsquery('portuguese', @query) AS portuguese) parsed), ` +
+		`matches AS (SELECT b.id FROM books b, q WHERE b.search_vector @@ q.query UNION ` +
+		`SELECT ba.book_id FROM book_author ba INNER JOIN authors a ON ba.author_id = a.id, q WHERE a.deleted_at IS NULL AND a.search_vector @@ q.query) `
+
+	headlineOptions = `'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'`
+)
+
+var searchBooks = `SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, array_agg(a.name ORDER BY ba.position, a.name) AS authors, ` +
+	`json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ` +
+	`ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres, ` +
+	`ts_rank(b.search_vector, q.query) + max(ts_rank(a.search_vector, q.query)) AS rank, ` +
+	headline(`b.title`) + ` AS title_highlight, ` +
+	headline(`string_agg(a.name, ', ' ORDER BY ba.position, a.name)`) + ` AS authors_highlight ` +
+	`FROM q, book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id ` +
+	`WHERE b.deleted_at IS NULL AND a.deleted_at IS NULL AND b.id IN (SELECT id FROM matches) GROUP BY b.id, q.english, q.portuguese, q.query`
+
+// headline marks the words of text matched by the query, stemming them with
+// each dictionary the query was parsed with. The text is HTML-escaped first,
+// so <mark> is the only markup in it. A word matched by both dictionaries is
+// marked twice, so the doubled marks are collapsed.
+func headline(text string) string {
+	escaped := `replace(replace(replace(` + text + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+	marked := `ts_headline('portuguese', ts_headline('english', ` + escaped + `, q.english, ` + headlineOptions + `), q.portuguese, ` + headlineOptions + `)`
+
+	return `replace(replace(` + marked + `, '<mark><mark>', '<mark>'), '</mark></mark>', '</mark>')`
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db}
+}
+
+func (repository *searchRepository) WithContext(ctx context.Context) SearchRepository {
+	return &searchRepository{repository.db.WithContext(ctx)}
+}
+
+func (repository *searchRepository) SearchBooks(query string, page int, pageSize int) ([]models.BookSearchResult, int64, error) {
+	db, span := startSpan(repository.db, "searchRepository.SearchBooks")
+	defer span.End()
+
+	var total int64
+
+	if err := db.Raw(searchWith+`SELECT count(*) FROM (`+searchBooks+`) results`, map[string]interface{}{"query": query}).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	results := []models.BookSearchResult{}
+
+	if total < 1 {
+		return results, 0, nil
+	}
+
+	args := map[string]interface{}{"query": query, "limit": pageSize, "offset": (page - 1) * pageSize}
+
+	if err := db.Raw(searchWith+searchBooks+` ORDER BY rank DESC, b.title, b.id LIMIT @limit OFFSET @offset`, args).Scan(&results).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range results {
+		results[i].SetISBN10()
+	}
+
+	return results, total, nil
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"github.com/joaooliveira247/go_olist_challenge/src/tracing"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+func startSpan(db *gorm.DB, name string) (*gorm.DB, trace.Span) {
+	ctx, span := tracing.Tracer().Start(db.Statement.Context, name)
+	return db.WithContext(ctx), span
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+)
+
+// pendingChanges collects what the writes of a transaction owe once it is
+// about to commit.
+type pendingChanges struct {
+	tags   []string
+	events []models.OutboxEvent
+}
+
+type pendingChangesKey struct{}
+
+func pendingOf(db *gorm.DB) (*pendingChanges, bool) {
+	pending, ok := db.Statement.Context.Value(pendingChangesKey{}).(*pendingChanges)
+	return pending, ok
+}
+
+// transaction runs fn in a transaction. The events of its writes are written to
+// the outbox right before it commits, and the cache entries they made stale are
+// invalidated once it has, so that no read caches the rows being replaced in
+// between.
+func transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	pending := &pendingChanges{}
+
+	err := db.WithContext(context.WithValue(db.Statement.Context, pendingChangesKey{}, pending)).Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		return writeOutbox(tx, pending.events)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	cache.Shared().Invalidate(pending.tags...)
+
+	return nil
+}
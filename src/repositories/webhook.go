@@ -0,0 +1,265 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	WithContext(ctx context.Context) WebhookRepository
+	Create(webhook *models.Webhook) (uuid.UUID, error)
+	GetAll() ([]models.Webhook, error)
+	Delete(id uuid.UUID) error
+	FanOut(limit int) (int, error)
+	ClaimDeliveries(lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	MarkDelivered(id uuid.UUID, status int) error
+	MarkFailed(id uuid.UUID, status int, reason string, retryAt *time.Time) error
+	GetDeliveries(status string, page int, pageSize int) ([]models.WebhookDelivery, int64, error)
+	Retry(id uuid.UUID) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db}
+}
+
+func (repository *webhookRepository) WithContext(ctx context.Context) WebhookRepository {
+	return &webhookRepository{repository.db.WithContext(ctx)}
+}
+
+func (repository *webhookRepository) Create(webhook *models.Webhook) (uuid.UUID, error) {
+	db, span := startSpan(repository.db, "webhookRepository.Create")
+	defer span.End()
+
+	if err := db.Create(&webhook).Error; err != nil {
+		return uuid.Nil, err
+	}
+
+	return webhook.ID, nil
+}
+
+func (repository *webhookRepository) GetAll() ([]models.Webhook, error) {
+	db, span := startSpan(repository.db, "webhookRepository.GetAll")
+	defer span.End()
+
+	webhooks := []models.Webhook{}
+
+	if err := db.Order("created_at").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// Delete removes the webhook with its deliveries, dead or pending.
+func (repository *webhookRepository) Delete(id uuid.UUID) error {
+	db, span := startSpan(repository.db, "webhookRepository.Delete")
+	defer span.End()
+
+	result := db.Delete(&models.Webhook{}, "id = ?", id)
+
+	if err := result.Error; err != nil {
+		return err
+	}
+
+	if result.RowsAffected < 1 {
+		return &custom.WebhookNotFound
+	}
+
+	return nil
+}
+
+// FanOut turns up to limit undispatched outbox events into a delivery for
+// every webhook subscribed to them, and answers how many events it took.
+// Events taken by another dispatcher are skipped.
+func (repository *webhookRepository) FanOut(limit int) (int, error) {
+	db, span := startSpan(repository.db, "webhookRepository.FanOut")
+	defer span.End()
+
+	var events []models.OutboxEvent
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL").Order("id").Limit(limit).Find(&events).Error; err != nil {
+			return err
+		}
+
+		if len(events) < 1 {
+			return nil
+		}
+
+		var webhooks []models.Webhook
+
+		if err := tx.Find(&webhooks).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		ids := make([]uint64, 0, len(events))
+		var deliveries []models.WebhookDelivery
+
+		for _, event := range events {
+			ids = append(ids, event.ID)
+
+			for _, webhook := range webhooks {
+				if webhook.Subscribes(event.Type) {
+					deliveries = append(deliveries, models.WebhookDelivery{WebhookID: webhook.ID, EventID: event.ID, NextAttemptAt: now})
+				}
+			}
+		}
+
+		if len(deliveries) > 0 {
+			if err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("dispatched_at", now).Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+// ClaimDeliveries answers up to limit deliveries due now with their webhook and
+// event, and holds them for lease so that no other dispatcher sends them in
+// the meantime.
+func (repository *webhookRepository) ClaimDeliveries(lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	db, span := startSpan(repository.db, "webhookRepository.ClaimDeliveries")
+	defer span.End()
+
+	var deliveries []models.WebhookDelivery
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var ids []uuid.UUID
+
+		if err := tx.Model(&models.WebhookDelivery{}).Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delivered_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", now).
+			Order("next_attempt_at").Limit(limit).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		if len(ids) < 1 {
+			return nil
+		}
+
+		if err := tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return err
+		}
+
+		return tx.Preload("Webhook").Preload("Event").Order("next_attempt_at").Find(&deliveries, "id IN ?", ids).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (repository *webhookRepository) MarkDelivered(id uuid.UUID, status int) error {
+	db, span := startSpan(repository.db, "webhookRepository.MarkDelivered")
+	defer span.End()
+
+	return db.Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_status":  status,
+		"last_error":   nil,
+		"delivered_at": time.Now(),
+	}).Error
+}
+
+// MarkFailed records a failed attempt, answered with status or with none when
+// it is 0. The delivery is tried again at retryAt, or dead lettered without
+// one.
+func (repository *webhookRepository) MarkFailed(id uuid.UUID, status int, reason string, retryAt *time.Time) error {
+	db, span := startSpan(repository.db, "webhookRepository.MarkFailed")
+	defer span.End()
+
+	updates := map[string]interface{}{
+		"attempts":    gorm.Expr("attempts + 1"),
+		"last_status": nil,
+		"last_error":  reason,
+	}
+
+	if status > 0 {
+		updates["last_status"] = status
+	}
+
+	if retryAt != nil {
+		updates["next_attempt_at"] = *retryAt
+	} else {
+		updates["dead_at"] = time.Now()
+	}
+
+	return db.Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// GetDeliveries answers a page of the deliveries in status, pending, delivered
+// or dead, or of every delivery when it is empty, newest first.
+func (repository *webhookRepository) GetDeliveries(status string, page int, pageSize int) ([]models.WebhookDelivery, int64, error) {
+	db, span := startSpan(repository.db, "webhookRepository.GetDeliveries")
+	defer span.End()
+
+	query := db.Model(&models.WebhookDelivery{})
+
+	switch status {
+	case models.DeliveryPending:
+		query = query.Where("delivered_at IS NULL AND dead_at IS NULL")
+	case models.DeliveryDelivered:
+		query = query.Where("delivered_at IS NOT NULL")
+	case models.DeliveryDead:
+		query = query.Where("dead_at IS NOT NULL")
+	}
+
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+
+	if err := query.Preload("Event").Order("created_at DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// Retry sends a dead delivery again from its first attempt.
+func (repository *webhookRepository) Retry(id uuid.UUID) error {
+	db, span := startSpan(repository.db, "webhookRepository.Retry")
+	defer span.End()
+
+	result := db.Model(&models.WebhookDelivery{}).Where("id = ? AND dead_at IS NOT NULL", id).Updates(map[string]interface{}{
+		"attempts":        0,
+		"dead_at":         nil,
+		"next_attempt_at": time.Now(),
+	})
+
+	if err := result.Error; err != nil {
+		return err
+	}
+
+	if result.RowsAffected < 1 {
+		return &custom.DeliveryNotFound
+	}
+
+	return nil
+}
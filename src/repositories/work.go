@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"gorm.io/gorm"
+)
+
+type WorkRepository interface {
+	WithContext(ctx context.Context) WorkRepository
+	GetByID(id uuid.UUID) (models.Work, error)
+}
+
+type workRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkRepository(db *gorm.DB) WorkRepository {
+	return &workRepository{db}
+}
+
+func (repository *workRepository) WithContext(ctx context.Context) WorkRepository {
+	return &workRepository{repository.db.WithContext(ctx)}
+}
+
+func (repository *workRepository) GetByID(id uuid.UUID) (models.Work, error) {
+	db, span := startSpan(repository.db, "workRepository.GetByID")
+	defer span.End()
+
+	var work models.Work
+
+	if err := db.First(&work, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Work{}, &custom.WorkNotFound
+		}
+		return models.Work{}, err
+	}
+
+	return work, nil
+}
@@ -12,14 +12,37 @@ type Response struct {
 }
 
 var (
-	InvalidRequestBody    = Response{http.StatusUnprocessableEntity, gin.H{"message": "request body invalid"}}
-	InvalidID             = Response{http.StatusBadRequest, gin.H{"message": "invalid id"}}
-	InvalidParam          = Response{http.StatusBadRequest, gin.H{"message": "invalid query param"}}
-	AuthorAlreadyExists   = Response{http.StatusConflict, gin.H{"message": "author already exists"}}
-	AuthorNotFound        = Response{http.StatusNotFound, gin.H{"message": "author not found"}}
-	UnableConnectDatabase = Response{http.StatusInternalServerError, gin.H{"message": "unable to connect to database"}}
-	UnableCreateEntity    = Response{http.StatusInternalServerError, gin.H{"message": "unable to create entity"}}
-	UnableFetchEntity     = Response{http.StatusInternalServerError, gin.H{"message": "unable to fetch entity"}}
-	NothingToUpdate       = Response{http.StatusNotModified, gin.H{"message": "nothing to update"}}
-	NothingToDelete       = Response{http.StatusNotModified, gin.H{}}
+	InvalidRequestBody     = Response{http.StatusUnprocessableEntity, gin.H{"message": "request body invalid"}}
+	InvalidID              = Response{http.StatusBadRequest, gin.H{"message": "invalid id"}}
+	InvalidParam           = Response{http.StatusBadRequest, gin.H{"message": "invalid query param"}}
+	InvalidLastEventID     = Response{http.StatusBadRequest, gin.H{"message": "invalid Last-Event-ID header"}}
+	InvalidIdempotencyKey  = Response{http.StatusBadRequest, gin.H{"message": "invalid Idempotency-Key header"}}
+	DuplicateEntries       = Response{http.StatusBadRequest, gin.H{"message": "authors, contributors and genres can not repeat an entry"}}
+	AuthorAlreadyExists    = Response{http.StatusConflict, gin.H{"message": "author already exists"}}
+	AuthorNotFound         = Response{http.StatusNotFound, gin.H{"message": "author not found"}}
+	BookAlreadyExists      = Response{http.StatusConflict, gin.H{"message": "book already exists"}}
+	BookNotFound           = Response{http.StatusNotFound, gin.H{"message": "book not found"}}
+	PublisherAlreadyExists = Response{http.StatusConflict, gin.H{"message": "publisher already exists"}}
+	PublisherNotFound      = Response{http.StatusNotFound, gin.H{"message": "publisher not found"}}
+	GenreAlreadyExists     = Response{http.StatusConflict, gin.H{"message": "genre already exists"}}
+	GenreNotFound          = Response{http.StatusNotFound, gin.H{"message": "genre not found"}}
+	WorkNotFound           = Response{http.StatusNotFound, gin.H{"message": "work not found"}}
+	WebhookNotFound        = Response{http.StatusNotFound, gin.H{"message": "webhook not found"}}
+	DeliveryNotFound       = Response{http.StatusNotFound, gin.H{"message": "dead delivery not found"}}
+	EditionAlreadyExists   = Response{http.StatusConflict, gin.H{"message": "edition already exists"}}
+	OriginalBookNotFound   = Response{http.StatusNotFound, gin.H{"message": "original book not found"}}
+	InvalidTranslation     = Response{http.StatusUnprocessableEntity, gin.H{"message": "book can not be a translation of itself"}}
+	UnableConnectDatabase  = Response{http.StatusInternalServerError, gin.H{"message": "unable to connect to database"}}
+	UnableCreateEntity     = Response{http.StatusInternalServerError, gin.H{"message": "unable to create entity"}}
+	UnableFetchEntity      = Response{http.StatusInternalServerError, gin.H{"message": "unable to fetch entity"}}
+	NothingToUpdate        = Response{http.StatusNotModified, gin.H{"message": "nothing to update"}}
+	NothingToDelete        = Response{http.StatusNotModified, gin.H{}}
+	Unauthorized           = Response{http.StatusUnauthorized, gin.H{"message": "unauthorized"}}
+	Forbidden              = Response{http.StatusForbidden, gin.H{"message": "forbidden"}}
+	TooManyRequests        = Response{http.StatusTooManyRequests, gin.H{"message": "too many requests"}}
+	PreconditionFailed     = Response{http.StatusPreconditionFailed, gin.H{"message": "version does not match"}}
+	PreconditionRequired   = Response{http.StatusPreconditionRequired, gin.H{"message": "If-Match header required"}}
+	IdempotencyKeyReused   = Response{http.StatusUnprocessableEntity, gin.H{"message": "Idempotency-Key already used for a different request"}}
+	IdempotencyInProgress  = Response{http.StatusConflict, gin.H{"message": "a request with this Idempotency-Key is in progress"}}
+	TooManyItems           = Response{http.StatusRequestEntityTooLarge, gin.H{"message": "too many items"}}
 )
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
+)
+
+func AuditRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	auditRepository := repositories.NewAuditRepository(gormDB)
+
+	controller := controllers.NewAuditController(auditRepository)
+
+	eng.GET("/audit", guard.Read(controller.GetAuditLogs)...)
+}
@@ -1,29 +1,25 @@
 package routes
 
 import (
-	"log"
-
 	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
 	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
-	"github.com/joaooliveira247/go_olist_challenge/src/db"
 	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
 )
 
-func AuthorRoutes(eng *gin.Engine) {
-	gormDB, err := db.GetDBConnection()
-
-	if err != nil {
-		log.Fatal("DATABASE: ", err)
-	}
-
-	authorRepository := repositories.NewAuthorRepository(gormDB)
+func AuthorRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	authorRepository := repositories.NewCachedAuthorRepository(repositories.NewAuthorRepository(gormDB), cache.Shared())
 
 	controller := controllers.NewAuthorController(authorRepository)
 
 	authorRouter := eng.Group("/authors")
 	{
-		authorRouter.POST("/", controller.CreateAuthor)
-		authorRouter.GET("/", controller.GetAuthors)
-		authorRouter.DELETE("/:id", controller.DeleteAuthor)
+		authorRouter.POST("/", guard.Create(controller.CreateAuthor)...)
+		authorRouter.POST("/bulk", guard.Create(controller.CreateAuthors)...)
+		authorRouter.GET("/", guard.Read(controller.GetAuthors)...)
+		authorRouter.PUT("/:id", guard.Write(controller.UpdateAuthor)...)
+		authorRouter.DELETE("/:id", guard.Write(controller.DeleteAuthor)...)
+		authorRouter.POST("/:id/restore", guard.Write(controller.RestoreAuthor)...)
 	}
 }
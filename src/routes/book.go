@@ -1,32 +1,27 @@
 package routes
 
 import (
-	"log"
-
 	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
 	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
-	"github.com/joaooliveira247/go_olist_challenge/src/db"
 	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
 )
 
-func BookRoutes(eng *gin.Engine) {
-	gormDB, err := db.GetDBConnection()
-
-	if err != nil {
-		log.Fatal(err)
-		return
-	}
-
-	bookRepository := repositories.NewBookRepository(gormDB)
-	bookAuthorRepository := repositories.NewBookAuthorRepository(gormDB)
+func BookRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	bookRepository := repositories.NewCachedBookRepository(repositories.NewBookRepository(gormDB), cache.Shared())
 
-	controller := controllers.NewBookController(bookRepository, bookAuthorRepository)
+	controller := controllers.NewBookController(bookRepository)
 
 	bookGroup := eng.Group("/books")
 	{
-		bookGroup.POST("/", controller.Create)
-		bookGroup.GET("/", controller.GetBooks)
-		bookGroup.PUT("/:id", controller.UpdateBook)
-		bookGroup.DELETE("/:id", controller.DeleteBook)
+		bookGroup.POST("/", guard.Create(controller.Create)...)
+		bookGroup.POST("/bulk", guard.Create(controller.CreateBooks)...)
+		bookGroup.GET("/", guard.Read(controller.GetBooks)...)
+		bookGroup.PUT("/:id", guard.Write(controller.UpdateBook)...)
+		bookGroup.DELETE("/:id", guard.Write(controller.DeleteBook)...)
+		bookGroup.POST("/:id/restore", guard.Write(controller.RestoreBook)...)
+		bookGroup.POST("/:id/editions", guard.Create(controller.CreateEdition)...)
+		bookGroup.GET("/:id/translations", guard.Read(controller.GetTranslations)...)
 	}
 }
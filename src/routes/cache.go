@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+)
+
+func CacheRoutes(eng *gin.Engine, guard Guards) {
+	controller := controllers.NewCacheController(cache.Shared())
+
+	eng.GET("/cache/stats", guard.Read(controller.GetStats)...)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/docs"
+)
+
+func DocsRoutes(eng *gin.Engine) {
+	controller := controllers.NewDocsController(docs.OpenAPI, docs.SwaggerUI, docs.SwaggerUIAssets)
+
+	eng.GET("/openapi.json", controller.GetSpec)
+	eng.GET("/docs", controller.GetUI)
+	eng.GET("/docs/assets/:file", controller.GetAsset)
+}
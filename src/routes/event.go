@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/events"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
+)
+
+func EventRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	eventRepository := repositories.NewEventRepository(gormDB)
+	broker := events.NewBroker(eventRepository, config.EventsPollInterval, nil)
+
+	controller := controllers.NewEventController(broker, eventRepository)
+
+	eng.GET("/events", guard.Read(controller.StreamEvents)...)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
+)
+
+func GenreRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	genreRepository := repositories.NewGenreRepository(gormDB)
+
+	controller := controllers.NewGenreController(genreRepository)
+
+	genreRouter := eng.Group("/genres")
+	{
+		genreRouter.POST("/", guard.Create(controller.Create)...)
+		genreRouter.GET("/", guard.Read(controller.GetAll)...)
+		genreRouter.GET("/:id", guard.Read(controller.Get)...)
+		genreRouter.PUT("/:id", guard.Write(controller.Update)...)
+		genreRouter.DELETE("/:id", guard.Write(controller.Delete)...)
+	}
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/gql"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
+)
+
+func GraphQLRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	authorRepository := repositories.NewCachedAuthorRepository(repositories.NewAuthorRepository(gormDB), cache.Shared())
+	bookRepository := repositories.NewCachedBookRepository(repositories.NewBookRepository(gormDB), cache.Shared())
+
+	schema, err := gql.NewSchema(authorRepository, bookRepository)
+
+	if err != nil {
+		log.Fatal("GRAPHQL: ", err)
+	}
+
+	controller := controllers.NewGraphQLController(schema)
+
+	eng.POST("/graphql", guard.Read(controller.Query)...)
+}
@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
+)
+
+type Guards struct {
+	reads   gin.HandlersChain
+	writes  gin.HandlersChain
+	creates gin.HandlersChain
+}
+
+func NewGuards(gormDB *gorm.DB) Guards {
+	verifier, err := auth.LoadJWTVerifier()
+
+	if err != nil {
+		log.Fatal("AUTH: ", err)
+	}
+
+	apiKeyRepository := repositories.NewAPIKeyRepository(gormDB)
+	rateLimitStore := middlewares.SharedRateLimitStore()
+
+	writes := gin.HandlersChain{
+		middlewares.RateLimitFailedAuth(rateLimitStore, "write", middlewares.WriteLimit),
+		middlewares.Authenticate(apiKeyRepository, verifier),
+		middlewares.RateLimiter(rateLimitStore, "write", middlewares.WriteLimit),
+	}
+
+	return Guards{
+		reads: gin.HandlersChain{
+			middlewares.RateLimitFailedAuth(rateLimitStore, "read", middlewares.ReadLimit),
+			middlewares.AuthenticateReads(apiKeyRepository, verifier),
+			middlewares.RateLimiter(rateLimitStore, "read", middlewares.ReadLimit),
+		},
+		writes: writes,
+		creates: append(slices.Clone(writes), middlewares.Idempotency(repositories.NewIdempotencyRepository(gormDB), func() time.Duration {
+			return config.IdempotencyWindow
+		})),
+	}
+}
+
+func (guard Guards) Read(handler gin.HandlerFunc) gin.HandlersChain {
+	return append(slices.Clone(guard.reads), handler)
+}
+
+func (guard Guards) Write(handler gin.HandlerFunc) gin.HandlersChain {
+	return append(slices.Clone(guard.writes), handler)
+}
+
+// Create guards handler as a write that may be retried with an
+// Idempotency-Key.
+func (guard Guards) Create(handler gin.HandlerFunc) gin.HandlersChain {
+	return append(slices.Clone(guard.creates), handler)
+}
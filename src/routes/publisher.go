@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
+)
+
+func PublisherRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	publisherRepository := repositories.NewPublisherRepository(gormDB)
+
+	controller := controllers.NewPublisherController(publisherRepository)
+
+	publisherRouter := eng.Group("/publishers")
+	{
+		publisherRouter.POST("/", guard.Create(controller.Create)...)
+		publisherRouter.GET("/", guard.Read(controller.GetAll)...)
+		publisherRouter.GET("/:id", guard.Read(controller.Get)...)
+		publisherRouter.PUT("/:id", guard.Write(controller.Update)...)
+		publisherRouter.DELETE("/:id", guard.Write(controller.Delete)...)
+	}
+}
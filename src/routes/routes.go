@@ -1,8 +1,26 @@
 package routes
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"gorm.io/gorm"
+)
 
-func RegistryRoutes(eng *gin.Engine) {
-	AuthorRoutes(eng)
-	BookRoutes(eng)
+func RegistryRoutes(eng *gin.Engine, db *gorm.DB) {
+	eng.Use(middlewares.RequestID(), middlewares.CacheBypass())
+
+	guard := NewGuards(db)
+
+	AuthorRoutes(eng, db, guard)
+	BookRoutes(eng, db, guard)
+	PublisherRoutes(eng, db, guard)
+	GenreRoutes(eng, db, guard)
+	WorkRoutes(eng, db, guard)
+	SearchRoutes(eng, db, guard)
+	GraphQLRoutes(eng, db, guard)
+	AuditRoutes(eng, db, guard)
+	CacheRoutes(eng, guard)
+	WebhookRoutes(eng, db, guard)
+	EventRoutes(eng, db, guard)
+	DocsRoutes(eng)
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
+)
+
+func SearchRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	searchRepository := repositories.NewSearchRepository(gormDB)
+
+	controller := controllers.NewSearchController(searchRepository)
+
+	eng.GET("/search", guard.Read(controller.Search)...)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
+)
+
+func WebhookRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	webhookRepository := repositories.NewWebhookRepository(gormDB)
+
+	controller := controllers.NewWebhookController(webhookRepository)
+
+	webhookRouter := eng.Group("/webhooks")
+	{
+		webhookRouter.POST("/", guard.Create(controller.CreateWebhook)...)
+		webhookRouter.GET("/", guard.Read(controller.GetWebhooks)...)
+		webhookRouter.DELETE("/:id", guard.Write(controller.DeleteWebhook)...)
+		webhookRouter.GET("/deliveries", guard.Read(controller.GetDeliveries)...)
+		webhookRouter.POST("/deliveries/:id/retry", guard.Write(controller.RetryDelivery)...)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"gorm.io/gorm"
+)
+
+func WorkRoutes(eng *gin.Engine, gormDB *gorm.DB, guard Guards) {
+	workRepository := repositories.NewWorkRepository(gormDB)
+	bookRepository := repositories.NewCachedBookRepository(repositories.NewBookRepository(gormDB), cache.Shared())
+
+	controller := controllers.NewWorkController(workRepository, bookRepository)
+
+	workRouter := eng.Group("/works")
+	{
+		workRouter.GET("/:id", guard.Read(controller.GetWork)...)
+	}
+}
@@ -0,0 +1,140 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/policies"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+	"github.com/joaooliveira247/go_olist_challenge/src/rpc/catalogpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var actions = map[string]policies.Action{
+	catalogpb.Catalog_CreateAuthor_FullMethodName:  policies.CreateAuthor,
+	catalogpb.Catalog_GetAuthor_FullMethodName:     policies.ReadCatalog,
+	catalogpb.Catalog_ListAuthors_FullMethodName:   policies.ReadCatalog,
+	catalogpb.Catalog_UpdateAuthor_FullMethodName:  policies.UpdateAuthor,
+	catalogpb.Catalog_DeleteAuthor_FullMethodName:  policies.DeleteAuthor,
+	catalogpb.Catalog_CreateBook_FullMethodName:    policies.CreateBook,
+	catalogpb.Catalog_GetBook_FullMethodName:       policies.ReadCatalog,
+	catalogpb.Catalog_ListBooks_FullMethodName:     policies.ReadCatalog,
+	catalogpb.Catalog_UpdateBook_FullMethodName:    policies.UpdateBook,
+	catalogpb.Catalog_DeleteBook_FullMethodName:    policies.DeleteBook,
+	catalogpb.Catalog_ExportAuthors_FullMethodName: policies.ReadCatalog,
+	catalogpb.Catalog_ExportBooks_FullMethodName:   policies.ReadCatalog,
+}
+
+type principalKey struct{}
+
+// principalFrom answers the principal authorize verified for the call of ctx.
+func principalFrom(ctx context.Context) (*auth.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(auth.Principal)
+
+	if !ok {
+		return nil, false
+	}
+
+	return &principal, true
+}
+
+// scopeOf answers the rate limit scope of method, which reads share with the
+// REST reads and everything else with the REST writes.
+func scopeOf(method string) string {
+	if actions[method] == policies.ReadCatalog {
+		return "read"
+	}
+
+	return "write"
+}
+
+type authenticator struct {
+	apiKeys  repositories.APIKeyRepository
+	verifier *auth.JWTVerifier
+}
+
+// authorize authenticates the credentials in the metadata of ctx and checks
+// their role against the policy of method, as the auth middleware and the
+// policies do over HTTP.
+func (authenticator authenticator) authorize(ctx context.Context, method string) (context.Context, error) {
+	if !config.AuthEnabled {
+		return ctx, nil
+	}
+
+	action, ok := actions[method]
+
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, message(response.Forbidden))
+	}
+
+	header := http.Header{}
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	principal, err := auth.FromHeader(header, authenticator.apiKeys.WithContext(ctx), authenticator.verifier)
+
+	if err != nil {
+		if errors.Is(err, &custom.MissingCredentials) && action == policies.ReadCatalog && config.AuthPublicReads {
+			return ctx, nil
+		}
+
+		var unauthorized *custom.Unauthorized
+
+		if errors.As(err, &unauthorized) {
+			return nil, status.Error(codes.Unauthenticated, message(response.Unauthorized))
+		}
+
+		return nil, status.Error(codes.Internal, message(response.UnableFetchEntity))
+	}
+
+	if !policies.Allows(principal.Role, action) {
+		return nil, status.Error(codes.PermissionDenied, message(response.Forbidden))
+	}
+
+	return context.WithValue(audit.WithActor(ctx, principal.Actor()), principalKey{}, principal), nil
+}
+
+func (authenticator authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := authenticator.authorize(ctx, info.FullMethod)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (authenticator authenticator) stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := authenticator.authorize(stream.Context(), info.FullMethod)
+
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authorizedStream{stream, ctx})
+}
+
+// authorizedStream carries the context with the audit actor to the handler of
+// a stream.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authorizedStream) Context() context.Context {
+	return stream.ctx
+}
@@ -0,0 +1,315 @@
+package rpc
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	"github.com/joaooliveira247/go_olist_challenge/src/isbn"
+	"github.com/joaooliveira247/go_olist_challenge/src/language"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/rpc/catalogpb"
+)
+
+func parseID(raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(raw)
+
+	if err != nil || id == uuid.Nil {
+		return uuid.UUID{}, errInvalidID
+	}
+
+	return id, nil
+}
+
+func parseOptionalID(raw *string) (*uuid.UUID, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	id, err := parseID(*raw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+func parseIDs(raw []string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	for _, value := range raw {
+		id, err := parseID(value)
+
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func optionalID(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+
+	value := id.String()
+	return &value
+}
+
+func optionalYear(year *uint32) (*uint16, error) {
+	if year == nil {
+		return nil, nil
+	}
+
+	if *year > math.MaxUint16 {
+		return nil, errInvalidArgument
+	}
+
+	value := uint16(*year)
+	return &value, nil
+}
+
+func edition(value uint32) (uint8, error) {
+	if value > math.MaxUint8 {
+		return 0, errInvalidArgument
+	}
+
+	return uint8(value), nil
+}
+
+func contributors(inputs []*catalogpb.ContributorInput) ([]models.Contributor, error) {
+	var result []models.Contributor
+
+	for _, input := range inputs {
+		authorID, err := parseID(input.GetAuthorId())
+
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models.Contributor{AuthorID: authorID, Role: input.GetRole()})
+	}
+
+	return result, nil
+}
+
+func authorMessage(author *models.Author) *catalogpb.Author {
+	message := &catalogpb.Author{
+		Id:          author.ID.String(),
+		Name:        author.Name,
+		SortName:    author.SortName,
+		Biography:   author.Biography,
+		Nationality: author.Nationality,
+		Website:     author.Website,
+	}
+
+	if author.BirthYear != nil {
+		year := uint32(*author.BirthYear)
+		message.BirthYear = &year
+	}
+	if author.DeathYear != nil {
+		year := uint32(*author.DeathYear)
+		message.DeathYear = &year
+	}
+
+	return message
+}
+
+func authorModel(message *catalogpb.Author) (models.Author, error) {
+	birthYear, err := optionalYear(message.BirthYear)
+
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	deathYear, err := optionalYear(message.DeathYear)
+
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return models.Author{
+		Name:        message.GetName(),
+		SortName:    message.SortName,
+		Biography:   message.Biography,
+		BirthYear:   birthYear,
+		DeathYear:   deathYear,
+		Nationality: message.Nationality,
+		Website:     message.Website,
+	}, nil
+}
+
+func authorUpdate(request *catalogpb.UpdateAuthorRequest) (models.AuthorUpdate, error) {
+	if request.GetBirthYear() > math.MaxUint16 || request.GetDeathYear() > math.MaxUint16 {
+		return models.AuthorUpdate{}, errInvalidArgument
+	}
+
+	return models.AuthorUpdate{
+		Name:        request.GetName(),
+		SortName:    request.GetSortName(),
+		Biography:   request.GetBiography(),
+		BirthYear:   uint16(request.GetBirthYear()),
+		DeathYear:   uint16(request.GetDeathYear()),
+		Nationality: request.GetNationality(),
+		Website:     request.GetWebsite(),
+	}, nil
+}
+
+func bookMessage(book *models.BookOut) *catalogpb.Book {
+	message := &catalogpb.Book{
+		Id:              book.ID.String(),
+		Title:           book.Title,
+		Edition:         uint32(book.Edition),
+		PublicationYear: uint32(book.PublicationYear),
+		Isbn:            book.ISBN,
+		PublisherId:     optionalID(book.PublisherID),
+		WorkId:          optionalID(book.WorkID),
+		Language:        book.Language,
+		TranslationOf:   optionalID(book.TranslationOfID),
+		Version:         uint32(book.Version),
+		Genres:          book.GenresName,
+	}
+
+	for _, contributor := range book.Contributors {
+		message.Contributors = append(message.Contributors, &catalogpb.Contributor{
+			AuthorId: contributor.AuthorID.String(),
+			Name:     contributor.Name,
+			Role:     contributor.Role,
+		})
+	}
+
+	return message
+}
+
+func bookIn(request *catalogpb.CreateBookRequest) (models.BookIn, error) {
+	bookEdition, err := edition(request.GetEdition())
+
+	if err != nil {
+		return models.BookIn{}, err
+	}
+
+	publisherID, err := parseOptionalID(request.PublisherId)
+
+	if err != nil {
+		return models.BookIn{}, err
+	}
+
+	workID, err := parseOptionalID(request.WorkId)
+
+	if err != nil {
+		return models.BookIn{}, err
+	}
+
+	translationOf, err := parseOptionalID(request.TranslationOf)
+
+	if err != nil {
+		return models.BookIn{}, err
+	}
+
+	bookContributors, err := contributors(request.GetContributors())
+
+	if err != nil {
+		return models.BookIn{}, err
+	}
+
+	genres, err := parseIDs(request.GetGenreIds())
+
+	if err != nil {
+		return models.BookIn{}, err
+	}
+
+	return models.BookIn{
+		Book: models.Book{
+			Title:           request.GetTitle(),
+			Edition:         bookEdition,
+			PublicationYear: uint(request.GetPublicationYear()),
+			ISBN:            request.Isbn,
+			PublisherID:     publisherID,
+			WorkID:          workID,
+			Language:        request.Language,
+			TranslationOfID: translationOf,
+		},
+		Contributors: bookContributors,
+		GenresID:     genres,
+	}, nil
+}
+
+func bookUpdate(request *catalogpb.UpdateBookRequest) (models.BookUpdate, error) {
+	var update models.BookUpdate
+
+	bookEdition, err := edition(request.GetEdition())
+
+	if err != nil {
+		return update, err
+	}
+
+	update.Title = request.GetTitle()
+	update.Edition = bookEdition
+	update.PublicationYear = uint(request.GetPublicationYear())
+	update.ISBN = request.GetIsbn()
+	update.Language = request.GetLanguage()
+
+	if request.GetPublisherId() != "" {
+		if update.PublisherID, err = parseID(request.GetPublisherId()); err != nil {
+			return update, err
+		}
+	}
+	if request.GetTranslationOf() != "" {
+		if update.TranslationOfID, err = parseID(request.GetTranslationOf()); err != nil {
+			return update, err
+		}
+	}
+	if update.Contributors, err = contributors(request.GetContributors()); err != nil {
+		return update, err
+	}
+	if update.GenresID, err = parseIDs(request.GetGenreIds()); err != nil {
+		return update, err
+	}
+
+	return update, nil
+}
+
+// bookQuery reads a filter the same way as the query params of GET /books/.
+func bookQuery(filter *catalogpb.BookFilter) (dto.BookQueryParams, error) {
+	var query dto.BookQueryParams
+
+	bookEdition, err := edition(filter.GetEdition())
+
+	if err != nil {
+		return query, err
+	}
+
+	query.Title = filter.GetTitle()
+	query.Edition = bookEdition
+	query.PublicationYear = uint(filter.GetPublicationYear())
+	query.Genres = filter.GetGenres()
+
+	if raw := filter.GetIsbn(); raw != "" {
+		normalized, ok := isbn.Normalize(raw)
+
+		if !ok {
+			return query, errInvalidArgument
+		}
+		query.ISBN = normalized
+	}
+	if raw := filter.GetLanguage(); raw != "" {
+		normalized, ok := language.Normalize(raw)
+
+		if !ok {
+			return query, errInvalidArgument
+		}
+		query.Language = normalized
+	}
+	if raw := filter.GetPublisherId(); raw != "" {
+		publisherID, err := parseID(raw)
+
+		if err != nil {
+			return query, err
+		}
+		query.PublisherID = publisherID.String()
+	}
+
+	return query, nil
+}
@@ -0,0 +1,145 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// limiter applies the rate limits of the REST guards to the calls, on the
+// same store, so that a client has one budget over both APIs.
+type limiter struct {
+	store middlewares.RateLimitStore
+}
+
+func limitOf(scope string) middlewares.RateLimit {
+	if scope == "read" {
+		return middlewares.ReadLimit()
+	}
+
+	return middlewares.WriteLimit()
+}
+
+// clientIP answers the host of the peer of ctx.
+func clientIP(ctx context.Context) string {
+	client, ok := peer.FromContext(ctx)
+
+	if !ok || client.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(client.Addr.String())
+
+	if err != nil {
+		return client.Addr.String()
+	}
+
+	return host
+}
+
+func tooManyRequests(decision middlewares.RateLimitDecision) (metadata.MD, error) {
+	header := metadata.Pairs("retry-after", strconv.Itoa(int(decision.RetryAfter.Seconds())))
+
+	return header, status.Error(codes.ResourceExhausted, message(response.TooManyRequests))
+}
+
+// checkFailedAuth rejects the call while the failed authentications of its
+// IP used up their bucket, as middlewares.RateLimitFailedAuth does.
+func (limiter limiter) checkFailedAuth(ctx context.Context, method string) (string, middlewares.RateLimit, metadata.MD, error) {
+	scope := scopeOf(method)
+	limit := limitOf(scope)
+	key := middlewares.FailedAuthKey(scope, clientIP(ctx))
+
+	if limit.Requests <= 0 {
+		return "", limit, nil, nil
+	}
+
+	if decision := limiter.store.Peek(key, limit); !decision.Allowed {
+		header, err := tooManyRequests(decision)
+		return "", limit, header, err
+	}
+
+	return key, limit, nil, nil
+}
+
+// chargeFailedAuth takes a token from the failed-auth bucket key when err
+// rejected the credentials of the call.
+func (limiter limiter) chargeFailedAuth(key string, limit middlewares.RateLimit, err error) {
+	if key != "" && status.Code(err) == codes.Unauthenticated {
+		limiter.store.Take(key, limit)
+	}
+}
+
+// take charges the call to the bucket of its principal, or of its IP when it
+// has none, as middlewares.RateLimiter does.
+func (limiter limiter) take(ctx context.Context, method string) (metadata.MD, error) {
+	scope := scopeOf(method)
+	limit := limitOf(scope)
+
+	if limit.Requests <= 0 {
+		return nil, nil
+	}
+
+	principal, _ := principalFrom(ctx)
+	decision := limiter.store.Take(scope+":"+middlewares.ClientKey(principal, clientIP(ctx)), limit)
+
+	if !decision.Allowed {
+		return tooManyRequests(decision)
+	}
+
+	return nil, nil
+}
+
+func (limiter limiter) unaryFailedAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	key, limit, header, err := limiter.checkFailedAuth(ctx, info.FullMethod)
+
+	if err != nil {
+		grpc.SetHeader(ctx, header)
+		return nil, err
+	}
+
+	resp, err := handler(ctx, req)
+	limiter.chargeFailedAuth(key, limit, err)
+
+	return resp, err
+}
+
+func (limiter limiter) streamFailedAuth(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	key, limit, header, err := limiter.checkFailedAuth(stream.Context(), info.FullMethod)
+
+	if err != nil {
+		stream.SetHeader(header)
+		return err
+	}
+
+	err = handler(srv, stream)
+	limiter.chargeFailedAuth(key, limit, err)
+
+	return err
+}
+
+func (limiter limiter) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if header, err := limiter.take(ctx, info.FullMethod); err != nil {
+		grpc.SetHeader(ctx, header)
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (limiter limiter) stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if header, err := limiter.take(stream.Context(), info.FullMethod); err != nil {
+		stream.SetHeader(header)
+		return err
+	}
+
+	return handler(srv, stream)
+}
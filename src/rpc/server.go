@@ -0,0 +1,438 @@
+// Package rpc serves the Catalog gRPC service defined in catalogpb, on the
+// same repositories as the REST controllers.
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/dto"
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+	"github.com/joaooliveira247/go_olist_challenge/src/rpc/catalogpb"
+	"github.com/joaooliveira247/go_olist_challenge/src/validation"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type CatalogService struct {
+	catalogpb.UnimplementedCatalogServer
+	authorRepository repositories.AuthorRepository
+	bookRepository   repositories.BookRepository
+}
+
+func NewCatalogService(authorRepo repositories.AuthorRepository, bookRepo repositories.BookRepository) *CatalogService {
+	return &CatalogService{authorRepository: authorRepo, bookRepository: bookRepo}
+}
+
+// NewServer registers service on a gRPC server that authenticates,
+// authorizes and rate limits every call as the REST API does, taking from the
+// buckets of store.
+func NewServer(service catalogpb.CatalogServer, apiKeys repositories.APIKeyRepository, verifier *auth.JWTVerifier, store middlewares.RateLimitStore) *grpc.Server {
+	authenticator := authenticator{apiKeys, verifier}
+	limiter := limiter{store}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(limiter.unaryFailedAuth, authenticator.unary, limiter.unary),
+		grpc.ChainStreamInterceptor(limiter.streamFailedAuth, authenticator.stream, limiter.stream),
+	)
+	catalogpb.RegisterCatalogServer(server, service)
+
+	return server
+}
+
+// NewServerFromDB builds the Catalog server on the repositories of gormDB.
+func NewServerFromDB(gormDB *gorm.DB, verifier *auth.JWTVerifier) *grpc.Server {
+	service := NewCatalogService(
+		repositories.NewCachedAuthorRepository(repositories.NewAuthorRepository(gormDB), cache.Shared()),
+		repositories.NewCachedBookRepository(repositories.NewBookRepository(gormDB), cache.Shared()),
+	)
+
+	return NewServer(service, repositories.NewAPIKeyRepository(gormDB), verifier, middlewares.SharedRateLimitStore())
+}
+
+func (service *CatalogService) CreateAuthor(ctx context.Context, request *catalogpb.CreateAuthorRequest) (*catalogpb.Author, error) {
+	author, err := authorModel(request.GetAuthor())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(&author); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	if _, err := service.authorRepository.WithContext(ctx).Create(&author); err != nil {
+		return nil, statusOf(err, response.UnableCreateEntity)
+	}
+
+	return authorMessage(&author), nil
+}
+
+func (service *CatalogService) GetAuthor(ctx context.Context, request *catalogpb.GetAuthorRequest) (*catalogpb.Author, error) {
+	id, err := parseID(request.GetId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := service.authorRepository.WithContext(ctx).GetByID(id)
+
+	if err != nil {
+		return nil, statusOf(err, response.UnableFetchEntity)
+	}
+
+	return authorMessage(&author), nil
+}
+
+func (service *CatalogService) ListAuthors(ctx context.Context, request *catalogpb.ListAuthorsRequest) (*catalogpb.ListAuthorsResponse, error) {
+	page, pageSize, err := pagination(request.GetPage(), request.GetPageSize())
+
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := service.listAuthors(ctx, request.GetFilter())
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &catalogpb.ListAuthorsResponse{Total: uint32(len(authors)), Page: page, PageSize: pageSize}
+
+	for _, author := range pageOf(authors, page, pageSize) {
+		result.Authors = append(result.Authors, authorMessage(&author))
+	}
+
+	return result, nil
+}
+
+func (service *CatalogService) ExportAuthors(request *catalogpb.ExportAuthorsRequest, stream catalogpb.Catalog_ExportAuthorsServer) error {
+	authors, err := service.listAuthors(stream.Context(), request.GetFilter())
+
+	if err != nil {
+		return err
+	}
+
+	for _, author := range authors {
+		if err := stream.Send(authorMessage(&author)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listAuthors filters the authors the same way as GET /authors/?match=fuzzy.
+func (service *CatalogService) listAuthors(ctx context.Context, filter *catalogpb.AuthorFilter) ([]models.Author, error) {
+	params := dto.AuthorQueryParams{Name: filter.GetName(), Match: dto.MatchFuzzy, Nationality: filter.GetNationality()}
+
+	if err := validation.Struct(&params); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	authors := service.authorRepository.WithContext(ctx)
+
+	if params.Nationality != "" {
+		authors = authors.WithNationality(params.Nationality)
+	}
+
+	if params.Name == "" {
+		found, err := authors.GetAll()
+
+		if err != nil {
+			return nil, statusOf(err, response.UnableFetchEntity)
+		}
+		return found, nil
+	}
+
+	matches, err := authors.GetBySimilarName(params.Name, config.AuthorSimilarityThreshold)
+
+	if err != nil {
+		return nil, statusOf(err, response.UnableFetchEntity)
+	}
+
+	var found []models.Author
+
+	for _, match := range matches {
+		found = append(found, match.Author)
+	}
+
+	return found, nil
+}
+
+func (service *CatalogService) UpdateAuthor(ctx context.Context, request *catalogpb.UpdateAuthorRequest) (*catalogpb.Author, error) {
+	id, err := parseID(request.GetId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := authorUpdate(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(&update); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	authors := service.authorRepository.WithContext(ctx)
+
+	if !update.IsEmpty() {
+		if err := authors.Update(id, &update); err != nil && !errors.Is(err, &custom.AuthorNothingToUpdate) {
+			return nil, statusOf(err, response.UnableFetchEntity)
+		}
+	}
+
+	author, err := authors.GetByID(id)
+
+	if err != nil {
+		return nil, statusOf(err, response.UnableFetchEntity)
+	}
+
+	return authorMessage(&author), nil
+}
+
+func (service *CatalogService) DeleteAuthor(ctx context.Context, request *catalogpb.DeleteAuthorRequest) (*emptypb.Empty, error) {
+	id, err := parseID(request.GetId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.authorRepository.WithContext(ctx).Delete(id); err != nil {
+		return nil, statusOf(err, response.UnableFetchEntity)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// CreateBook creates the book with its contributors and genres in a single
+// transaction.
+func (service *CatalogService) CreateBook(ctx context.Context, request *catalogpb.CreateBookRequest) (*catalogpb.Book, error) {
+	book, err := bookIn(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(&book); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	books := service.bookRepository.WithContext(ctx)
+
+	ids, err := books.CreateMany([]models.BookIn{book})
+
+	if err != nil {
+		return nil, statusOf(err, response.UnableCreateEntity)
+	}
+
+	return service.getBook(books, ids[0])
+}
+
+func (service *CatalogService) GetBook(ctx context.Context, request *catalogpb.GetBookRequest) (*catalogpb.Book, error) {
+	id, err := parseID(request.GetId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return service.getBook(service.bookRepository.WithContext(ctx), id)
+}
+
+func (service *CatalogService) getBook(books repositories.BookRepository, id uuid.UUID) (*catalogpb.Book, error) {
+	book, err := books.GetBookByID(id)
+
+	if err != nil {
+		return nil, statusOf(err, response.UnableFetchEntity)
+	}
+
+	return bookMessage(&book), nil
+}
+
+func (service *CatalogService) ListBooks(ctx context.Context, request *catalogpb.ListBooksRequest) (*catalogpb.ListBooksResponse, error) {
+	page, pageSize, err := pagination(request.GetPage(), request.GetPageSize())
+
+	if err != nil {
+		return nil, err
+	}
+
+	books, err := service.listBooks(ctx, request.GetFilter())
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &catalogpb.ListBooksResponse{Total: uint32(len(books)), Page: page, PageSize: pageSize}
+
+	for _, book := range pageOf(books, page, pageSize) {
+		result.Books = append(result.Books, bookMessage(&book))
+	}
+
+	return result, nil
+}
+
+func (service *CatalogService) ExportBooks(request *catalogpb.ExportBooksRequest, stream catalogpb.Catalog_ExportBooksServer) error {
+	books, err := service.listBooks(stream.Context(), request.GetFilter())
+
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		if err := stream.Send(bookMessage(&book)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listBooks filters the books the same way as GET /books/: the author id
+// takes precedence over the other filters.
+func (service *CatalogService) listBooks(ctx context.Context, filter *catalogpb.BookFilter) ([]models.BookOut, error) {
+	books := service.bookRepository.WithContext(ctx)
+
+	if filter.GetAuthorId() != "" {
+		authorID, err := parseID(filter.GetAuthorId())
+
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := books.GetBooksByAuthorID(authorID)
+
+		if err != nil {
+			return nil, statusOf(err, response.UnableFetchEntity)
+		}
+		return found, nil
+	}
+
+	query, err := bookQuery(filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var found []models.BookOut
+
+	if query.IsEmpty() {
+		found, err = books.GetAll()
+	} else {
+		found, err = books.GetBookByQuery(query.AsQuery())
+	}
+
+	if err != nil {
+		return nil, statusOf(err, response.UnableFetchEntity)
+	}
+
+	return found, nil
+}
+
+// UpdateBook changes a book the same way as PUT /books/{id}, with the version
+// standing in for If-Match, and answers the updated book. The book, its
+// contributors and its genres change in a single transaction.
+func (service *CatalogService) UpdateBook(ctx context.Context, request *catalogpb.UpdateBookRequest) (*catalogpb.Book, error) {
+	id, err := parseID(request.GetId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := bookUpdate(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(&update); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	version, err := expectedVersion(request.GetVersion())
+
+	if err != nil {
+		return nil, err
+	}
+
+	books := service.bookRepository.WithContext(ctx)
+
+	if !update.IsEmpty() || len(update.BookAuthors(id)) > 0 || len(update.GenresID) > 0 {
+		if err := books.Update(id, &update, version); err != nil {
+			// The lock of the update finds no book to change.
+			if errors.Is(err, &custom.BookNothingToUpdate) {
+				err = &custom.BookNotFound
+			}
+			return nil, statusOf(err, response.UnableFetchEntity)
+		}
+	}
+
+	return service.getBook(books, id)
+}
+
+func (service *CatalogService) DeleteBook(ctx context.Context, request *catalogpb.DeleteBookRequest) (*emptypb.Empty, error) {
+	id, err := parseID(request.GetId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := expectedVersion(request.GetVersion())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.bookRepository.WithContext(ctx).Delete(id, version); err != nil {
+		return nil, statusOf(err, response.UnableFetchEntity)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// expectedVersion stands in for If-Match: zero skips the version check unless
+// REQUIRE_IF_MATCH is set.
+func expectedVersion(version uint32) (uint, error) {
+	if version == 0 && config.RequireIfMatch {
+		return 0, errVersionRequired
+	}
+
+	return uint(version), nil
+}
+
+func pagination(page uint32, pageSize uint32) (uint32, uint32, error) {
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		return 0, 0, errInvalidArgument
+	}
+
+	return page, pageSize, nil
+}
+
+func pageOf[T any](items []T, page uint32, pageSize uint32) []T {
+	start := min(int(page-1)*int(pageSize), len(items))
+	end := min(start+int(pageSize), len(items))
+
+	return items[start:end]
+}
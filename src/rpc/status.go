@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"errors"
+
+	custom "github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/response"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errInvalidID       = status.Error(codes.InvalidArgument, message(response.InvalidID))
+	errInvalidArgument = status.Error(codes.InvalidArgument, "invalid argument")
+	errVersionRequired = status.Error(codes.FailedPrecondition, "version required")
+)
+
+func message(res response.Response) string {
+	return res.Message["message"].(string)
+}
+
+var statuses = []struct {
+	err      error
+	code     codes.Code
+	response response.Response
+}{
+	{&custom.AuthorNotFound, codes.NotFound, response.AuthorNotFound},
+	{&custom.BookNotFound, codes.NotFound, response.BookNotFound},
+	{&custom.PublisherNotFound, codes.NotFound, response.PublisherNotFound},
+	{&custom.GenreNotFound, codes.NotFound, response.GenreNotFound},
+	{&custom.WorkNotFound, codes.NotFound, response.WorkNotFound},
+	{&custom.OriginalBookNotFound, codes.NotFound, response.OriginalBookNotFound},
+	{&custom.AuthorAlreadyExists, codes.AlreadyExists, response.AuthorAlreadyExists},
+	{&custom.BookAlreadyExists, codes.AlreadyExists, response.BookAlreadyExists},
+	{&custom.InvalidTranslation, codes.InvalidArgument, response.InvalidTranslation},
+	{&custom.BookVersionMismatch, codes.FailedPrecondition, response.PreconditionFailed},
+}
+
+// statusOf maps an error of the repositories to a gRPC status with the message
+// of its REST response, answering the fallback response for unexpected errors
+// as the REST handlers do.
+func statusOf(err error, fallback response.Response) error {
+	for _, known := range statuses {
+		if errors.Is(err, known.err) {
+			return status.Error(known.code, message(known.response))
+		}
+	}
+
+	var invalid *custom.Invalid
+
+	if errors.As(err, &invalid) {
+		return errInvalidArgument
+	}
+
+	return status.Error(codes.Internal, message(fallback))
+}
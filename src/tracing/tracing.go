@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
+)
+
+const instrumentationName = "github.com/joaooliveira247/go_olist_challenge"
+
+type ShutdownFunc func(ctx context.Context) error
+
+func Setup(ctx context.Context) (ShutdownFunc, error) {
+	noop := func(context.Context) error { return nil }
+
+	if !config.TracingEnabled {
+		return noop, nil
+	}
+
+	exporter, err := newExporter(ctx)
+
+	if err != nil {
+		return noop, err
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(config.TracingServiceName)),
+	)
+
+	if err != nil {
+		return noop, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch config.TracingExporter {
+	case "otlp":
+		var opts []otlptracehttp.Option
+		if config.TracingEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(config.TracingEndpoint))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("tracing exporter '%s' not supported", config.TracingExporter)
+	}
+}
+
+func Middleware() gin.HandlerFunc {
+	return otelgin.Middleware(config.TracingServiceName)
+}
+
+func Instrument(db *gorm.DB) error {
+	return db.Use(gormtracing.NewPlugin(gormtracing.WithoutQueryVariables(), gormtracing.WithoutMetrics()))
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
@@ -2,19 +2,52 @@ package utils
 
 import (
 	"encoding/csv"
+	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/joaooliveira247/go_olist_challenge/src/models"
 )
 
-func ParseAuthorsFromCSV(path string, header bool) ([]models.Author, error) {
+// AuthorColumns are the CSV columns an author can be imported from.
+var AuthorColumns = []string{"name", "sort_name", "biography", "birth_year", "death_year", "nationality", "website"}
+
+// ParseAuthorsFromCSV reads one author per line. The columns are taken from
+// columns when given, else from the header, else the only column is the name.
+// Empty cells leave the field unset.
+func ParseAuthorsFromCSV(path string, header bool, columns []string) ([]models.Author, error) {
+	return parseCSV(path, header, columns, AuthorColumns, []string{"name"}, setAuthorColumn)
+}
+
+// BookColumns are the CSV columns a book can be imported from. author_ids and
+// genre_ids hold ids separated by ';'.
+var BookColumns = []string{"title", "edition", "publication_year", "isbn", "publisher_id", "language", "author_ids", "genre_ids"}
+
+// ParseBooksFromCSV reads one book per line. The columns are taken from
+// columns when given, else from the header, else they are BookColumns in order.
+// Empty cells leave the field unset.
+func ParseBooksFromCSV(path string, header bool, columns []string) ([]models.BookIn, error) {
+	return parseCSV(path, header, columns, BookColumns, BookColumns, setBookColumn)
+}
+
+// parseCSV reads one T per line, setting each cell with set under its column.
+// The columns are taken from columns when given, else from the header, else
+// they are fallback.
+func parseCSV[T any](path string, header bool, columns []string, known []string, fallback []string, set func(*T, string, string) error) ([]T, error) {
 	file, err := os.Open(path)
 
 	if err != nil {
 		return nil, err
 	}
 
+	defer file.Close()
+
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
 
 	lines, err := reader.ReadAll()
 
@@ -22,16 +55,239 @@ func ParseAuthorsFromCSV(path string, header bool) ([]models.Author, error) {
 		return nil, err
 	}
 
-	if header {
+	if header && len(lines) > 0 {
+		if len(columns) < 1 {
+			columns = lines[0]
+		}
 		lines = lines[1:]
 	}
 
-	var authors []models.Author
+	if len(columns) < 1 {
+		columns = slices.Clone(fallback)
+	}
+
+	for i, column := range columns {
+		columns[i] = strings.ToLower(strings.TrimSpace(column))
+
+		if !slices.Contains(known, columns[i]) {
+			return nil, fmt.Errorf("unknown column '%s', use %s", column, strings.Join(known, ", "))
+		}
+	}
+
+	var items []T
+
+	for n, line := range lines {
+		var item T
+
+		for i, cell := range line {
+			if i >= len(columns) {
+				break
+			}
+			if err := set(&item, columns[i], strings.TrimSpace(cell)); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func setAuthorColumn(author *models.Author, column string, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch column {
+	case "name":
+		author.Name = value
+	case "sort_name":
+		author.SortName = &value
+	case "biography":
+		author.Biography = &value
+	case "birth_year", "death_year":
+		year, err := strconv.ParseUint(value, 10, 16)
+
+		if err != nil {
+			return fmt.Errorf("%s '%s' is not a year", column, value)
+		}
+
+		parsed := uint16(year)
+
+		if column == "birth_year" {
+			author.BirthYear = &parsed
+		} else {
+			author.DeathYear = &parsed
+		}
+	case "nationality":
+		code := strings.ToUpper(value)
+		author.Nationality = &code
+	case "website":
+		author.Website = &value
+	}
+
+	return nil
+}
+
+func setBookColumn(book *models.BookIn, column string, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch column {
+	case "title":
+		book.Title = value
+	case "edition":
+		edition, err := strconv.ParseUint(value, 10, 8)
+
+		if err != nil {
+			return fmt.Errorf("edition '%s' is not a number", value)
+		}
+		book.Edition = uint8(edition)
+	case "publication_year":
+		year, err := strconv.ParseUint(value, 10, 16)
+
+		if err != nil {
+			return fmt.Errorf("publication_year '%s' is not a year", value)
+		}
+		book.PublicationYear = uint(year)
+	case "isbn":
+		book.ISBN = &value
+	case "publisher_id":
+		id, err := uuid.Parse(value)
+
+		if err != nil {
+			return fmt.Errorf("publisher_id '%s' is not an id", value)
+		}
+		book.PublisherID = &id
+	case "language":
+		book.Language = &value
+	case "author_ids", "genre_ids":
+		ids, err := parseIDs(value)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", column, err)
+		}
+
+		if column == "author_ids" {
+			book.AuthorsID = ids
+		} else {
+			book.GenresID = ids
+		}
+	}
+
+	return nil
+}
+
+// parseIDs reads ids separated by ';'.
+func parseIDs(value string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	for _, raw := range strings.Split(value, ";") {
+		id, err := uuid.Parse(strings.TrimSpace(raw))
+
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not an id", raw)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// AuthorExportColumns are the CSV columns an author is exported with. Past the
+// id they are the columns it can be imported from.
+var AuthorExportColumns = slices.Concat([]string{"id"}, AuthorColumns, []string{"deleted_at"})
+
+// BookExportColumns are the CSV columns a book is exported with. Authors,
+// contributors and genres are joined with "; ", contributors as "name (role)".
+var BookExportColumns = []string{
+	"id", "title", "edition", "publication_year", "isbn", "isbn_10", "publisher_id", "work_id", "language",
+	"translation_of", "version", "authors", "contributors", "genres", "deleted_at",
+}
+
+// AuthorRecord lays out author in the AuthorExportColumns, leaving unset
+// fields empty.
+func AuthorRecord(author models.Author) []string {
+	return neutralizeFormulas([]string{
+		author.ID.String(),
+		author.Name,
+		optional(author.SortName),
+		optional(author.Biography),
+		optionalYear(author.BirthYear),
+		optionalYear(author.DeathYear),
+		optional(author.Nationality),
+		optional(author.Website),
+		deletedAt(author.DeletedAt),
+	})
+}
+
+// BookRecord lays out book in the BookExportColumns, leaving unset fields
+// empty.
+func BookRecord(book models.BookOut) []string {
+	contributors := make([]string, 0, len(book.Contributors))
 
-	for _, line := range lines {
-		author := models.Author{Name: line[0]}
-		authors = append(authors, author)
+	for _, contributor := range book.Contributors {
+		contributors = append(contributors, fmt.Sprintf("%s (%s)", contributor.Name, contributor.Role))
 	}
 
-	return authors, nil
+	return neutralizeFormulas([]string{
+		book.ID.String(),
+		book.Title,
+		strconv.Itoa(int(book.Edition)),
+		strconv.FormatUint(uint64(book.PublicationYear), 10),
+		optional(book.ISBN),
+		optional(book.ISBN10),
+		optionalID(book.PublisherID),
+		optionalID(book.WorkID),
+		optional(book.Language),
+		optionalID(book.TranslationOfID),
+		strconv.FormatUint(uint64(book.Version), 10),
+		strings.Join(book.AuthorsName, "; "),
+		strings.Join(contributors, "; "),
+		strings.Join(book.GenresName, "; "),
+		deletedAt(book.DeletedAt),
+	})
+}
+
+// neutralizeFormulas prefixes with ' the cells a spreadsheet would run as a
+// formula, those starting with =, +, - or @, so that an exported name or
+// title can not inject one.
+func neutralizeFormulas(record []string) []string {
+	for i, cell := range record {
+		if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+			record[i] = "'" + cell
+		}
+	}
+
+	return record
+}
+
+func optional(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func optionalYear(year *uint16) string {
+	if year == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*year))
+}
+
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func deletedAt(deleted models.DeletedAt) string {
+	if !deleted.Valid {
+		return ""
+	}
+	return deleted.Time.UTC().Format(time.RFC3339)
 }
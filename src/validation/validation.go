@@ -0,0 +1,70 @@
+// Package validation registers the custom validations used in the binding tags
+// of the models on the gin validator, so REST handlers and the gRPC service
+// validate the same way.
+package validation
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/joaooliveira247/go_olist_challenge/src/isbn"
+	"github.com/joaooliveira247/go_olist_challenge/src/language"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+)
+
+// Struct validates obj against its binding tags.
+func Struct(obj interface{}) error {
+	return binding.Validator.ValidateStruct(obj)
+}
+
+func init() {
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterValidation("isbn_checksum", func(field validator.FieldLevel) bool {
+			return isbn.Valid(field.Field().String())
+		})
+		engine.RegisterValidation("iso639_1", func(field validator.FieldLevel) bool {
+			return language.Valid(field.Field().String())
+		})
+		engine.RegisterStructValidation(validateAuthor, models.Author{})
+		engine.RegisterStructValidation(validateAuthorUpdate, models.AuthorUpdate{})
+		engine.RegisterStructValidation(validateBookIn, models.BookIn{})
+		engine.RegisterStructValidation(validateBookUpdate, models.BookUpdate{})
+	}
+}
+
+// validateAuthor rejects a death year before the birth year.
+func validateAuthor(level validator.StructLevel) {
+	author := level.Current().Interface().(models.Author)
+
+	if author.LivedBackwards() {
+		level.ReportError(author.DeathYear, "DeathYear", "death_year", "gtefield", "BirthYear")
+	}
+}
+
+func validateAuthorUpdate(level validator.StructLevel) {
+	author := level.Current().Interface().(models.AuthorUpdate)
+
+	if author.BirthYear != 0 && author.DeathYear != 0 && author.DeathYear < author.BirthYear {
+		level.ReportError(author.DeathYear, "DeathYear", "death_year", "gtefield", "BirthYear")
+	}
+}
+
+// validateBookIn requires the authors of a book either as a plain list of ids
+// or as contributors, but not both.
+func validateBookIn(level validator.StructLevel) {
+	book := level.Current().Interface().(models.BookIn)
+
+	if len(book.AuthorsID) < 1 && len(book.Contributors) < 1 {
+		level.ReportError(book.AuthorsID, "AuthorsID", "authors", "required_without", "Contributors")
+	}
+	if len(book.AuthorsID) > 0 && len(book.Contributors) > 0 {
+		level.ReportError(book.AuthorsID, "AuthorsID", "authors", "excluded_with", "Contributors")
+	}
+}
+
+func validateBookUpdate(level validator.StructLevel) {
+	book := level.Current().Interface().(models.BookUpdate)
+
+	if len(book.AuthorsID) > 0 && len(book.Contributors) > 0 {
+		level.ReportError(book.AuthorsID, "AuthorsID", "authors", "excluded_with", "Contributors")
+	}
+}
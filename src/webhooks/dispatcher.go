@@ -0,0 +1,219 @@
+// Package webhooks delivers the catalog events written to the outbox to the
+// registered webhooks, signing every request with the secret of its webhook.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-ID"
+)
+
+const (
+	// batchSize is how many events are fanned out and deliveries claimed at a
+	// time.
+	batchSize = 100
+	// workers is how many deliveries are sent at once.
+	workers = 10
+	// maxBackoff caps the wait between two attempts.
+	maxBackoff = 6 * time.Hour
+)
+
+// GenerateSecret answers a new random secret to sign the deliveries of a
+// webhook with.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// Sign answers the signature of a delivery of body sent at timestamp, the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff answers how long to wait after the attempt-th failed attempt, base
+// doubled after every attempt up to maxBackoff.
+func Backoff(base time.Duration, attempt int) time.Duration {
+	wait := base
+
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	return min(wait, maxBackoff)
+}
+
+type Dispatcher struct {
+	repository  repositories.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+func NewDispatcher(repository repositories.WebhookRepository, maxAttempts int, backoff time.Duration, timeout time.Duration) *Dispatcher {
+	return &Dispatcher{repository, &http.Client{Timeout: timeout}, maxAttempts, backoff}
+}
+
+// Run dispatches every interval until ctx is done.
+func (dispatcher *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := dispatcher.RunOnce(ctx); err != nil {
+			log.Println("WEBHOOKS: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce fans the new outbox events out to the webhooks subscribed to them
+// and sends the deliveries that are due.
+func (dispatcher *Dispatcher) RunOnce(ctx context.Context) error {
+	repository := dispatcher.repository.WithContext(ctx)
+
+	for {
+		events, err := repository.FanOut(batchSize)
+
+		if err != nil {
+			return err
+		}
+
+		if events < batchSize {
+			break
+		}
+	}
+
+	// Held long enough for every worker to send its share of the batch
+	// before another dispatcher may claim them again.
+	lease := dispatcher.client.Timeout * (batchSize/workers + 1)
+
+	deliveries, err := repository.ClaimDeliveries(lease, batchSize)
+
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed error
+	)
+
+	queue := make(chan models.WebhookDelivery)
+
+	for range min(workers, len(deliveries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for delivery := range queue {
+				if err := dispatcher.deliver(ctx, repository, delivery); err != nil {
+					mu.Lock()
+					failed = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, delivery := range deliveries {
+		queue <- delivery
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return failed
+}
+
+// deliver sends delivery and records the outcome. A 2xx answer delivers it,
+// anything else is retried with backoff until it runs out of attempts.
+func (dispatcher *Dispatcher) deliver(ctx context.Context, repository repositories.WebhookRepository, delivery models.WebhookDelivery) error {
+	body, err := json.Marshal(delivery.Event)
+
+	if err != nil {
+		return err
+	}
+
+	status, err := dispatcher.send(ctx, delivery, body)
+
+	if err == nil {
+		return repository.MarkDelivered(delivery.ID, status)
+	}
+
+	var retryAt *time.Time
+
+	if attempt := delivery.Attempts + 1; attempt < dispatcher.maxAttempts {
+		at := time.Now().Add(Backoff(dispatcher.backoff, attempt))
+		retryAt = &at
+	}
+
+	return repository.MarkFailed(delivery.ID, status, err.Error(), retryAt)
+}
+
+func (dispatcher *Dispatcher) send(ctx context.Context, delivery models.WebhookDelivery, body []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(SignatureHeader, Sign(delivery.Webhook.Secret, timestamp, body))
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(EventHeader, delivery.Event.Type)
+	request.Header.Set(DeliveryHeader, delivery.ID.String())
+
+	response, err := dispatcher.client.Do(request)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
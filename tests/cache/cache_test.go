@@ -0,0 +1,157 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+type clock struct {
+	current time.Time
+}
+
+func (clock *clock) now() time.Time {
+	return clock.current
+}
+
+// counter loads the number of times it was called.
+type counter struct {
+	calls int
+}
+
+func (counter *counter) load() (int, error) {
+	counter.calls++
+	return counter.calls, nil
+}
+
+func tagged(tags ...string) func(int) []string {
+	return func(int) []string { return tags }
+}
+
+func TestFetchCachesUntilTTL(t *testing.T) {
+	now := &clock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	readCache := cache.New(time.Minute, 10, now.now)
+	loads := &counter{}
+
+	first, _ := cache.Fetch(context.Background(), readCache, "key", loads.load, tagged())
+	second, _ := cache.Fetch(context.Background(), readCache, "key", loads.load, tagged())
+
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 1, second)
+
+	now.current = now.current.Add(time.Minute)
+
+	third, _ := cache.Fetch(context.Background(), readCache, "key", loads.load, tagged())
+
+	assert.Equal(t, 2, third)
+
+	stats := readCache.Stats()
+
+	assert.True(t, stats.Enabled)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, 1, stats.Entries)
+}
+
+func TestFetchEvictsLeastRecentlyUsed(t *testing.T) {
+	readCache := cache.New(time.Minute, 2, nil)
+	loads := map[string]*counter{"a": {}, "b": {}, "c": {}}
+
+	fetch := func(key string) {
+		cache.Fetch(context.Background(), readCache, key, loads[key].load, tagged())
+	}
+
+	fetch("a")
+	fetch("b")
+	fetch("a")
+	fetch("c")
+	fetch("a")
+	fetch("b")
+
+	assert.Equal(t, 1, loads["a"].calls)
+	assert.Equal(t, 2, loads["b"].calls)
+	assert.Equal(t, uint64(2), readCache.Stats().Evictions)
+	assert.Equal(t, 2, readCache.Stats().Entries)
+}
+
+func TestInvalidateDropsTaggedEntries(t *testing.T) {
+	readCache := cache.New(time.Minute, 10, nil)
+	author, book := &counter{}, &counter{}
+
+	cache.Fetch(context.Background(), readCache, "author", author.load, tagged("author:1", "authors"))
+	cache.Fetch(context.Background(), readCache, "book", book.load, tagged("book:1", "author:2"))
+
+	readCache.Invalidate("author:1")
+
+	cache.Fetch(context.Background(), readCache, "author", author.load, tagged("author:1", "authors"))
+	cache.Fetch(context.Background(), readCache, "book", book.load, tagged("book:1", "author:2"))
+
+	assert.Equal(t, 2, author.calls)
+	assert.Equal(t, 1, book.calls)
+	assert.Equal(t, uint64(1), readCache.Stats().Invalidations)
+}
+
+func TestFetchSkipsValuesLoadedDuringAnInvalidation(t *testing.T) {
+	readCache := cache.New(time.Minute, 10, nil)
+	calls := 0
+
+	load := func() (int, error) {
+		calls++
+		if calls == 1 {
+			readCache.Invalidate("author:1")
+		}
+		return calls, nil
+	}
+
+	cache.Fetch(context.Background(), readCache, "key", load, tagged())
+	value, _ := cache.Fetch(context.Background(), readCache, "key", load, tagged())
+
+	assert.Equal(t, 2, value)
+}
+
+func TestFetchDoesNotCacheErrors(t *testing.T) {
+	readCache := cache.New(time.Minute, 10, nil)
+	calls := 0
+
+	load := func() (int, error) {
+		calls++
+		return 0, errors.New("unavailable")
+	}
+
+	_, first := cache.Fetch(context.Background(), readCache, "key", load, tagged())
+	_, second := cache.Fetch(context.Background(), readCache, "key", load, tagged())
+
+	assert.Error(t, first)
+	assert.Error(t, second)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 0, readCache.Stats().Entries)
+}
+
+func TestFetchBypassesCache(t *testing.T) {
+	readCache := cache.New(time.Minute, 10, nil)
+	loads := &counter{}
+	ctx := cache.WithBypass(context.Background())
+
+	cache.Fetch(ctx, readCache, "key", loads.load, tagged())
+	cache.Fetch(ctx, readCache, "key", loads.load, tagged())
+
+	assert.Equal(t, 2, loads.calls)
+	assert.Equal(t, uint64(2), readCache.Stats().Bypasses)
+	assert.Equal(t, 0, readCache.Stats().Entries)
+}
+
+func TestNilCacheLoadsEveryTime(t *testing.T) {
+	var readCache *cache.Cache
+	loads := &counter{}
+
+	cache.Fetch(context.Background(), readCache, "key", loads.load, tagged())
+	cache.Fetch(context.Background(), readCache, "key", loads.load, tagged())
+	readCache.Invalidate("key")
+
+	assert.Equal(t, 2, loads.calls)
+	assert.False(t, readCache.Stats().Enabled)
+}
@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadEnv(t *testing.T, env map[string]string) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("API_PORT=8000\n"), 0o600))
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	config.LoadEnv()
+}
+
+func TestLoadEnvIdempotencyWindow(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{"default", "", 24 * time.Hour},
+		{"duration", "1h", time.Hour},
+		{"disabled", "0", 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config.IdempotencyWindow = 24 * time.Hour
+
+			loadEnv(t, map[string]string{"IDEMPOTENCY_WINDOW": testCase.value})
+
+			assert.Equal(t, testCase.expected, config.IdempotencyWindow)
+		})
+	}
+}
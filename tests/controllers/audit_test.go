@@ -0,0 +1,106 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func serveAuditLogs(repository *mocks.AuditRepository, url string) *httptest.ResponseRecorder {
+	controller := controllers.NewAuditController(repository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, url, nil)
+
+	controller.GetAuditLogs(c)
+
+	return w
+}
+
+func TestGetAuditLogsSuccess(t *testing.T) {
+	mockRepository := new(mocks.AuditRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	bookID := uuid.New()
+	logs := []models.AuditLog{
+		{ID: uuid.New(), Entity: "book", EntityID: bookID, Action: "update", Actor: "cli"},
+	}
+
+	mockRepository.On("GetAll", "book", bookID, 2, 10).Return(logs, int64(11), nil)
+
+	w := serveAuditLogs(mockRepository, fmt.Sprintf("/audit?entity=book&id=%s&page=2&pageSize=10", bookID))
+
+	var body struct {
+		Items    []models.AuditLog `json:"items"`
+		Page     int               `json:"page"`
+		PageSize int               `json:"page_size"`
+		Total    int64             `json:"total"`
+	}
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Items, 1)
+	assert.Equal(t, bookID, body.Items[0].EntityID)
+	assert.Equal(t, 2, body.Page)
+	assert.Equal(t, 10, body.PageSize)
+	assert.Equal(t, int64(11), body.Total)
+}
+
+func TestGetAuditLogsDefaultPagination(t *testing.T) {
+	mockRepository := new(mocks.AuditRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+	mockRepository.On("GetAll", "", uuid.Nil, 1, 50).Return([]models.AuditLog{}, int64(0), nil)
+
+	w := serveAuditLogs(mockRepository, "/audit")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items": [], "page": 1, "page_size": 50, "total": 0}`, w.Body.String())
+}
+
+func TestGetAuditLogsReturnInvalidParam(t *testing.T) {
+	for _, url := range []string{"/audit?entity=api_key", "/audit?page=0", "/audit?pageSize=201"} {
+		t.Run(url, func(t *testing.T) {
+			mockRepository := new(mocks.AuditRepository)
+			mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+			w := serveAuditLogs(mockRepository, url)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockRepository.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetAuditLogsReturnInvalidID(t *testing.T) {
+	mockRepository := new(mocks.AuditRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	w := serveAuditLogs(mockRepository, "/audit?id=not-a-uuid")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "invalid id"}`, w.Body.String())
+}
+
+func TestGetAuditLogsReturnGenericError(t *testing.T) {
+	mockRepository := new(mocks.AuditRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+	mockRepository.On("GetAll", "", uuid.Nil, 1, 50).Return(nil, int64(0), &errors.AuthorGenericError)
+
+	w := serveAuditLogs(mockRepository, "/audit")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
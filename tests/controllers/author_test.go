@@ -10,15 +10,18 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
 	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
 	"github.com/joaooliveira247/go_olist_challenge/src/errors"
 	"github.com/joaooliveira247/go_olist_challenge/src/models"
 	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestCreateSuccess(t *testing.T) {
 	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
 	expectedID := uuid.New()
 	author := models.Author{
 		Name: "Luciano Ramalho",
@@ -42,8 +45,35 @@ func TestCreateSuccess(t *testing.T) {
 	assert.JSONEq(t, fmt.Sprintf(`{"id": "%s"}`, expectedID), w.Body.String())
 }
 
+func TestCreateIgnoresDeletedAt(t *testing.T) {
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+	expectedID := uuid.New()
+	author := models.Author{
+		Name: "Luciano Ramalho",
+	}
+	mockRepository.On("Create", &author).Return(expectedID, nil)
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+
+	body := `{"name": "Luciano Ramalho", "deleted_at": "2024-01-01T00:00:00Z"}`
+
+	c.Request, _ = http.NewRequest(http.MethodPost, "/authors/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.CreateAuthor(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"id": "%s"}`, expectedID), w.Body.String())
+}
+
 func TestCreateReturnAlreadyExists(t *testing.T) {
 	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
 	author := models.Author{
 		Name: "Luciano Ramalho",
 	}
@@ -67,6 +97,7 @@ func TestCreateReturnAlreadyExists(t *testing.T) {
 
 func TestCreateReturnInvalidRequestBody(t *testing.T) {
 	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
 	controller := controllers.NewAuthorController(mockRepository)
 
 	w := httptest.NewRecorder()
@@ -85,6 +116,7 @@ func TestCreateReturnInvalidRequestBody(t *testing.T) {
 
 func TestCreateReturnUnableCreateEntity(t *testing.T) {
 	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
 
 	author := models.Author{
 		Name: "Luciano Ramalho",
@@ -112,6 +144,7 @@ func TestCreateReturnUnableCreateEntity(t *testing.T) {
 
 func TestGetAuthorsByQueryReturnErrorInAuthorID(t *testing.T) {
 	mockAuthorRepository := new(mocks.AuthorRepository)
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
 
 	testCases := []struct {
 		name string
@@ -158,6 +191,8 @@ func TestGetAuthorsByIDReturnNotFound(t *testing.T) {
 	authorID := uuid.New()
 
 	mockAuthorRepository := new(mocks.AuthorRepository)
+
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
 	mockAuthorRepository.On("GetByID", authorID).Return(models.Author{}, &errors.AuthorGenericError)
 
 	w := httptest.NewRecorder()
@@ -177,13 +212,14 @@ func TestGetAuthorsByIDReturnNotFound(t *testing.T) {
 func TestGetAuthorsByNameReturnNotFound(t *testing.T) {
 	authorName := "Edgar Allan Poe"
 	mockAuthorRepository := new(mocks.AuthorRepository)
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
 	mockAuthorRepository.On("GetByName", authorName).Return(nil, &errors.AuthorGenericError)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
 
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/authors/?name=%s", authorName), nil)
+	c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/authors/?name=%s&match=substring", authorName), nil)
 	c.Header("Content-Type", "application/json")
 
 	controller := controllers.NewAuthorController(mockAuthorRepository)
@@ -202,6 +238,8 @@ func TestGetAuthorsByIDSuccess(t *testing.T) {
 	}
 
 	mockAuthorRepository := new(mocks.AuthorRepository)
+
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
 	mockAuthorRepository.On("GetByID", authorID).Return(mockAuthor, nil)
 
 	w := httptest.NewRecorder()
@@ -235,6 +273,8 @@ func TestGetAuthorsByNameSuccess(t *testing.T) {
 	}
 
 	mockAuthorRepository := new(mocks.AuthorRepository)
+
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
 	mockAuthorRepository.On("GetByName", authorName).Return(mockAuthors, nil)
 
 	w := httptest.NewRecorder()
@@ -251,10 +291,75 @@ func TestGetAuthorsByNameSuccess(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.JSONEq(t, string(bMock), w.Body.String())
+	mockAuthorRepository.AssertNotCalled(t, "GetBySimilarName", mock.Anything, mock.Anything)
+}
+
+func TestGetAuthorsBySimilarNameSuccess(t *testing.T) {
+	mockAuthors := []models.AuthorMatch{
+		{Author: models.Author{ID: uuid.New(), Name: "João Guimarães Rosa"}, Similarity: 1},
+		{Author: models.Author{ID: uuid.New(), Name: "João Cabral de Melo Neto"}, Similarity: 1},
+	}
+
+	mockAuthorRepository := new(mocks.AuthorRepository)
+
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
+	mockAuthorRepository.On("GetBySimilarName", "joao", config.AuthorSimilarityThreshold).Return(mockAuthors, nil)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/authors/?name=joao&match=fuzzy", nil)
+
+	controller := controllers.NewAuthorController(mockAuthorRepository)
+	controller.GetAuthors(c)
+
+	bMock, _ := json.Marshal(mockAuthors)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, string(bMock), w.Body.String())
+	assert.Contains(t, w.Body.String(), `"similarity":1`)
+	mockAuthorRepository.AssertNotCalled(t, "GetByName", mock.Anything)
+}
+
+func TestGetAuthorsBySimilarNameReturnNotFound(t *testing.T) {
+	mockAuthorRepository := new(mocks.AuthorRepository)
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
+	mockAuthorRepository.On("GetBySimilarName", "Ramalo", mock.Anything).Return(nil, &errors.AuthorGenericError)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/authors/?name=Ramalo&match=fuzzy", nil)
+
+	controller := controllers.NewAuthorController(mockAuthorRepository)
+	controller.GetAuthors(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message": "author not found"}`, w.Body.String())
+}
+
+func TestGetAuthorsReturnInvalidMatch(t *testing.T) {
+	mockAuthorRepository := new(mocks.AuthorRepository)
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/authors/?name=joao&match=exact", nil)
+
+	controller := controllers.NewAuthorController(mockAuthorRepository)
+	controller.GetAuthors(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "invalid query param"}`, w.Body.String())
 }
 
 func TestGetAuthorsReturnUnableFetchEntity(t *testing.T) {
 	mockAuthorRepository := new(mocks.AuthorRepository)
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
 	mockAuthorRepository.On("GetAll").Return(nil, &errors.AuthorGenericError)
 
 	w := httptest.NewRecorder()
@@ -288,6 +393,8 @@ func TestGetAuthorsSuccess(t *testing.T) {
 	}
 
 	mockAuthorRepository := new(mocks.AuthorRepository)
+
+	mockAuthorRepository.On("WithContext", mock.Anything).Return(mockAuthorRepository).Maybe()
 	mockAuthorRepository.On("GetAll").Return(mockAuthors, nil)
 
 	w := httptest.NewRecorder()
@@ -309,6 +416,7 @@ func TestGetAuthorsSuccess(t *testing.T) {
 
 func TestDeleteAuthorSuccess(t *testing.T) {
 	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
 
 	expectedID := uuid.New()
 
@@ -334,6 +442,7 @@ func TestDeleteAuthorSuccess(t *testing.T) {
 
 func TestDeleteAuthorReturnInvalidID(t *testing.T) {
 	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -355,6 +464,7 @@ func TestDeleteAuthorReturnInvalidID(t *testing.T) {
 
 func TestDeleteAuthorReturnAuthorNotFound(t *testing.T) {
 	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
 
 	expectedID := uuid.New()
 
@@ -379,6 +489,7 @@ func TestDeleteAuthorReturnAuthorNotFound(t *testing.T) {
 
 func TestDeleteAuthorReturnUnableFetchEntity(t *testing.T) {
 	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
 
 	expectedID := uuid.New()
 
@@ -400,3 +511,204 @@ func TestDeleteAuthorReturnUnableFetchEntity(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.JSONEq(t, `{"message": "unable to fetch entity"}`, w.Body.String())
 }
+
+func TestGetAuthorsIncludeDeletedUseUnscoped(t *testing.T) {
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	unscopedRepository := new(mocks.AuthorRepository)
+
+	authors := []models.Author{{ID: uuid.New(), Name: "Luciano Ramalho"}}
+
+	mockRepository.On("Unscoped").Return(unscopedRepository)
+	unscopedRepository.On("GetAll").Return(authors, nil)
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/authors/?includeDeleted=true", nil)
+
+	controller.GetAuthors(c)
+
+	expected, _ := json.Marshal(authors)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, string(expected), w.Body.String())
+	mockRepository.AssertNotCalled(t, "GetAll")
+}
+
+func TestRestoreAuthor(t *testing.T) {
+	testCases := []struct {
+		name            string
+		id              string
+		restoreError    error
+		status          int
+		expectedMessage string
+	}{
+		{"success", uuid.New().String(), nil, http.StatusNoContent, ""},
+		{"invalid id", "123", nil, http.StatusBadRequest, `{"message": "invalid id"}`},
+		{"not deleted", uuid.New().String(), &errors.AuthorNotFound, http.StatusNotFound, `{"message": "author not found"}`},
+		{"name taken", uuid.New().String(), &errors.AuthorAlreadyExists, http.StatusConflict, `{"message": "author already exists"}`},
+		{"generic error", uuid.New().String(), &errors.AuthorGenericError, http.StatusInternalServerError, `{"message": "unable to fetch entity"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepository := new(mocks.AuthorRepository)
+			mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+			mockRepository.On("Restore", mock.Anything).Return(testCase.restoreError).Maybe()
+
+			controller := controllers.NewAuthorController(mockRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/authors/%s/restore", testCase.id), nil)
+			c.Params = gin.Params{
+				{Key: "id", Value: testCase.id},
+			}
+
+			controller.RestoreAuthor(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+			if testCase.expectedMessage != "" {
+				assert.JSONEq(t, testCase.expectedMessage, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateAuthorWithProfile(t *testing.T) {
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			"full profile",
+			`{"name": "Jorge Amado", "sort_name": "Amado, Jorge", "birth_year": 1912, "death_year": 2001, "nationality": "BR", "website": "https://www.jorgeamado.org.br"}`,
+			http.StatusCreated,
+		},
+		{"death before birth", `{"name": "Jorge Amado", "birth_year": 2001, "death_year": 1912}`, http.StatusUnprocessableEntity},
+		{"nationality not a country code", `{"name": "Jorge Amado", "nationality": "Brazil"}`, http.StatusUnprocessableEntity},
+		{"website not an url", `{"name": "Jorge Amado", "website": "jorge amado"}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepository := new(mocks.AuthorRepository)
+			mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+			mockRepository.On("Create", mock.Anything).Return(uuid.New(), nil).Maybe()
+
+			controller := controllers.NewAuthorController(mockRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+
+			c.Request, _ = http.NewRequest(http.MethodPost, "/authors/", bytes.NewBufferString(testCase.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			controller.CreateAuthor(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+		})
+	}
+}
+
+func TestUpdateAuthor(t *testing.T) {
+	testCases := []struct {
+		name            string
+		body            string
+		updateError     error
+		status          int
+		expectedMessage string
+	}{
+		{"success", `{"nationality": "BR", "death_year": 2001}`, nil, http.StatusNoContent, ""},
+		{"nothing to update", `{}`, nil, http.StatusNotModified, ""},
+		{"death before birth", `{"birth_year": 2001, "death_year": 1912}`, nil, http.StatusUnprocessableEntity, `{"message": "request body invalid"}`},
+		{"death before stored birth", `{"death_year": 1900}`, &errors.InvalidAuthorYears, http.StatusUnprocessableEntity, `{"message": "request body invalid"}`},
+		{"not found", `{"name": "Jorge Amado"}`, &errors.AuthorNotFound, http.StatusNotFound, `{"message": "author not found"}`},
+		{"name taken", `{"name": "Jorge Amado"}`, &errors.AuthorAlreadyExists, http.StatusConflict, `{"message": "author already exists"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			id := uuid.New()
+
+			mockRepository := new(mocks.AuthorRepository)
+			mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+			mockRepository.On("Update", id, mock.Anything).Return(testCase.updateError).Maybe()
+
+			controller := controllers.NewAuthorController(mockRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPut, fmt.Sprintf("/authors/%s", id), bytes.NewBufferString(testCase.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{
+				{Key: "id", Value: id.String()},
+			}
+
+			controller.UpdateAuthor(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+			if testCase.expectedMessage != "" {
+				assert.JSONEq(t, testCase.expectedMessage, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetAuthorsByNationality(t *testing.T) {
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	brazilianRepository := new(mocks.AuthorRepository)
+
+	nationality := "BR"
+	authors := []models.Author{{ID: uuid.New(), Name: "Jorge Amado", Nationality: &nationality}}
+
+	mockRepository.On("WithNationality", "BR").Return(brazilianRepository)
+	brazilianRepository.On("GetAll").Return(authors, nil)
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/authors/?nationality=BR", nil)
+
+	controller.GetAuthors(c)
+
+	expected, _ := json.Marshal(authors)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, string(expected), w.Body.String())
+	mockRepository.AssertNotCalled(t, "GetAll")
+}
+
+func TestGetAuthorsReturnInvalidNationality(t *testing.T) {
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/authors/?nationality=Brazil", nil)
+
+	controller.GetAuthors(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "invalid query param"}`, w.Body.String())
+}
@@ -0,0 +1,429 @@
+package controllers_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/gql"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type authorizationRoute struct {
+	name    string
+	method  string
+	url     string
+	id      string
+	body    string
+	handler func(authorCtrl *controllers.AuthorController, bookCtrl *controllers.BookController) gin.HandlerFunc
+	allowed []auth.Role
+}
+
+func authorizationRoutes() []authorizationRoute {
+	id := uuid.New()
+	everyone := []auth.Role{auth.RoleReader, auth.RoleLibrarian, auth.RoleAdmin}
+	librarians := []auth.Role{auth.RoleLibrarian, auth.RoleAdmin}
+	admins := []auth.Role{auth.RoleAdmin}
+
+	return []authorizationRoute{
+		{
+			"POST /authors/", http.MethodPost, "/authors/", "", `{"name": "Luciano Ramalho"}`,
+			func(a *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return a.CreateAuthor
+			},
+			librarians,
+		},
+		{
+			"POST /authors/bulk", http.MethodPost, "/authors/bulk", "", `[{"name": "Luciano Ramalho"}]`,
+			func(a *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return a.CreateAuthors
+			},
+			admins,
+		},
+		{
+			"GET /authors/", http.MethodGet, "/authors/", "", "",
+			func(a *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return a.GetAuthors
+			},
+			everyone,
+		},
+		{
+			"PUT /authors/:id", http.MethodPut, fmt.Sprintf("/authors/%s", id), id.String(), `{"nationality": "BR"}`,
+			func(a *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return a.UpdateAuthor
+			},
+			librarians,
+		},
+		{
+			"DELETE /authors/:id", http.MethodDelete, fmt.Sprintf("/authors/%s", id), id.String(), "",
+			func(a *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return a.DeleteAuthor
+			},
+			admins,
+		},
+		{
+			"GET /authors/?includeDeleted=true", http.MethodGet, "/authors/?includeDeleted=true", "", "",
+			func(a *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return a.GetAuthors
+			},
+			admins,
+		},
+		{
+			"POST /authors/:id/restore", http.MethodPost, fmt.Sprintf("/authors/%s/restore", id), id.String(), "",
+			func(a *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return a.RestoreAuthor
+			},
+			admins,
+		},
+		{
+			"POST /books/", http.MethodPost, "/books/", "",
+			fmt.Sprintf(`{"title": "Python Fluente", "edition": 2, "publication_year": 2022, "authors": ["%s"]}`, uuid.New()),
+			func(_ *controllers.AuthorController, b *controllers.BookController) gin.HandlerFunc { return b.Create },
+			librarians,
+		},
+		{
+			"POST /books/bulk", http.MethodPost, "/books/bulk", "",
+			fmt.Sprintf(`[{"title": "Python Fluente", "edition": 2, "publication_year": 2022, "authors": ["%s"]}]`, uuid.New()),
+			func(_ *controllers.AuthorController, b *controllers.BookController) gin.HandlerFunc {
+				return b.CreateBooks
+			},
+			admins,
+		},
+		{
+			"GET /books/", http.MethodGet, "/books/", "", "",
+			func(_ *controllers.AuthorController, b *controllers.BookController) gin.HandlerFunc {
+				return b.GetBooks
+			},
+			everyone,
+		},
+		{
+			"PUT /books/:id", http.MethodPut, fmt.Sprintf("/books/%s", id), id.String(), `{"edition": 3}`,
+			func(_ *controllers.AuthorController, b *controllers.BookController) gin.HandlerFunc {
+				return b.UpdateBook
+			},
+			librarians,
+		},
+		{
+			"DELETE /books/:id", http.MethodDelete, fmt.Sprintf("/books/%s", id), id.String(), "",
+			func(_ *controllers.AuthorController, b *controllers.BookController) gin.HandlerFunc {
+				return b.DeleteBook
+			},
+			admins,
+		},
+		{
+			"GET /books/?includeDeleted=true", http.MethodGet, "/books/?includeDeleted=true", "", "",
+			func(_ *controllers.AuthorController, b *controllers.BookController) gin.HandlerFunc {
+				return b.GetBooks
+			},
+			admins,
+		},
+		{
+			"POST /books/:id/restore", http.MethodPost, fmt.Sprintf("/books/%s/restore", id), id.String(), "",
+			func(_ *controllers.AuthorController, b *controllers.BookController) gin.HandlerFunc {
+				return b.RestoreBook
+			},
+			admins,
+		},
+		{
+			"POST /books/:id/editions", http.MethodPost, fmt.Sprintf("/books/%s/editions", id), id.String(), `{"edition": 2, "publication_year": 2022}`,
+			func(_ *controllers.AuthorController, b *controllers.BookController) gin.HandlerFunc {
+				return b.CreateEdition
+			},
+			librarians,
+		},
+		{
+			"GET /books/:id/translations", http.MethodGet, fmt.Sprintf("/books/%s/translations", id), id.String(), "",
+			func(_ *controllers.AuthorController, b *controllers.BookController) gin.HandlerFunc {
+				return b.GetTranslations
+			},
+			everyone,
+		},
+		{
+			"POST /publishers/", http.MethodPost, "/publishers/", "", `{"name": "Novatec"}`,
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissivePublisherController().Create
+			},
+			librarians,
+		},
+		{
+			"GET /publishers/", http.MethodGet, "/publishers/?name=nova", "", "",
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissivePublisherController().GetAll
+			},
+			everyone,
+		},
+		{
+			"GET /publishers/:id", http.MethodGet, fmt.Sprintf("/publishers/%s", id), id.String(), "",
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissivePublisherController().Get
+			},
+			everyone,
+		},
+		{
+			"PUT /publishers/:id", http.MethodPut, fmt.Sprintf("/publishers/%s", id), id.String(), `{"name": "Novatec"}`,
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissivePublisherController().Update
+			},
+			librarians,
+		},
+		{
+			"DELETE /publishers/:id", http.MethodDelete, fmt.Sprintf("/publishers/%s", id), id.String(), "",
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissivePublisherController().Delete
+			},
+			admins,
+		},
+		{
+			"POST /genres/", http.MethodPost, "/genres/", "", `{"name": "Fantasy"}`,
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissiveGenreController().Create
+			},
+			librarians,
+		},
+		{
+			"GET /genres/", http.MethodGet, "/genres/?name=fant", "", "",
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissiveGenreController().GetAll
+			},
+			everyone,
+		},
+		{
+			"GET /genres/:id", http.MethodGet, fmt.Sprintf("/genres/%s", id), id.String(), "",
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissiveGenreController().Get
+			},
+			everyone,
+		},
+		{
+			"PUT /genres/:id", http.MethodPut, fmt.Sprintf("/genres/%s", id), id.String(), `{"name": "Fantasy"}`,
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissiveGenreController().Update
+			},
+			librarians,
+		},
+		{
+			"DELETE /genres/:id", http.MethodDelete, fmt.Sprintf("/genres/%s", id), id.String(), "",
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissiveGenreController().Delete
+			},
+			admins,
+		},
+		{
+			"GET /works/:id", http.MethodGet, fmt.Sprintf("/works/%s", id), id.String(), "",
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissiveWorkController().GetWork
+			},
+			everyone,
+		},
+		{
+			"POST /graphql", http.MethodPost, "/graphql", "", `{"query": "{ authors { total } }"}`,
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissiveGraphQLController().Query
+			},
+			everyone,
+		},
+		{
+			"GET /search", http.MethodGet, "/search?q=python", "", "",
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissiveSearchController().Search
+			},
+			everyone,
+		},
+		{
+			"GET /audit", http.MethodGet, "/audit", "", "",
+			func(_ *controllers.AuthorController, _ *controllers.BookController) gin.HandlerFunc {
+				return permissiveAuditController().GetAuditLogs
+			},
+			admins,
+		},
+	}
+}
+
+func permissiveControllers() (*controllers.AuthorController, *controllers.BookController) {
+	authorRepository := new(mocks.AuthorRepository)
+	authorRepository.On("WithContext", mock.Anything).Return(authorRepository).Maybe()
+	authorRepository.On("Unscoped").Return(authorRepository).Maybe()
+	authorRepository.On("Create", mock.Anything).Return(uuid.New(), nil).Maybe()
+	authorRepository.On("CreateMany", mock.Anything).Return([]uuid.UUID{uuid.New()}, nil).Maybe()
+	authorRepository.On("GetAll").Return([]models.Author{}, nil).Maybe()
+	authorRepository.On("Update", mock.Anything, mock.Anything).Return(nil).Maybe()
+	authorRepository.On("Delete", mock.Anything).Return(nil).Maybe()
+	authorRepository.On("Restore", mock.Anything).Return(nil).Maybe()
+
+	bookRepository := new(mocks.BookRepository)
+	bookRepository.On("WithContext", mock.Anything).Return(bookRepository).Maybe()
+	bookRepository.On("Unscoped").Return(bookRepository).Maybe()
+	bookRepository.On("Create", mock.Anything).Return(uuid.New(), nil).Maybe()
+	bookRepository.On("CreateMany", mock.Anything).Return([]uuid.UUID{uuid.New()}, nil).Maybe()
+	bookRepository.On("GetAll").Return([]models.BookOut{}, nil).Maybe()
+	bookRepository.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	bookRepository.On("Delete", mock.Anything, mock.Anything).Return(nil).Maybe()
+	bookRepository.On("Restore", mock.Anything).Return(nil).Maybe()
+	bookRepository.On("CreateEdition", mock.Anything, mock.Anything).Return(uuid.New(), nil).Maybe()
+	bookRepository.On("GetBookByID", mock.Anything).Return(models.BookOut{}, nil).Maybe()
+	bookRepository.On("GetTranslations", mock.Anything).Return([]models.BookOut{}, nil).Maybe()
+
+	return controllers.NewAuthorController(authorRepository), controllers.NewBookController(bookRepository)
+}
+
+func permissivePublisherController() *controllers.PublisherController {
+	publisherRepository := new(mocks.NamedRepository[models.Publisher])
+	publisherRepository.On("WithContext", mock.Anything).Return(publisherRepository).Maybe()
+	publisherRepository.On("Create", mock.Anything).Return(uuid.New(), nil).Maybe()
+	publisherRepository.On("GetByName", mock.Anything).Return([]models.Publisher{}, nil).Maybe()
+	publisherRepository.On("GetByID", mock.Anything).Return(models.Publisher{}, nil).Maybe()
+	publisherRepository.On("Update", mock.Anything, mock.Anything).Return(nil).Maybe()
+	publisherRepository.On("Delete", mock.Anything).Return(nil).Maybe()
+
+	return controllers.NewPublisherController(publisherRepository)
+}
+
+func permissiveGenreController() *controllers.GenreController {
+	genreRepository := new(mocks.NamedRepository[models.Genre])
+	genreRepository.On("WithContext", mock.Anything).Return(genreRepository).Maybe()
+	genreRepository.On("Create", mock.Anything).Return(uuid.New(), nil).Maybe()
+	genreRepository.On("GetByName", mock.Anything).Return([]models.Genre{}, nil).Maybe()
+	genreRepository.On("GetByID", mock.Anything).Return(models.Genre{}, nil).Maybe()
+	genreRepository.On("Update", mock.Anything, mock.Anything).Return(nil).Maybe()
+	genreRepository.On("Delete", mock.Anything).Return(nil).Maybe()
+
+	return controllers.NewGenreController(genreRepository)
+}
+
+func permissiveWorkController() *controllers.WorkController {
+	workRepository := new(mocks.WorkRepository)
+	workRepository.On("WithContext", mock.Anything).Return(workRepository).Maybe()
+	workRepository.On("GetByID", mock.Anything).Return(models.Work{}, nil).Maybe()
+
+	bookRepository := new(mocks.BookRepository)
+	bookRepository.On("WithContext", mock.Anything).Return(bookRepository).Maybe()
+	bookRepository.On("GetBooksByWorkID", mock.Anything).Return([]models.BookOut{}, nil).Maybe()
+
+	return controllers.NewWorkController(workRepository, bookRepository)
+}
+
+func permissiveGraphQLController() *controllers.GraphQLController {
+	authorRepository := new(mocks.AuthorRepository)
+	authorRepository.On("WithContext", mock.Anything).Return(authorRepository).Maybe()
+	authorRepository.On("GetAll").Return([]models.Author{}, nil).Maybe()
+
+	bookRepository := new(mocks.BookRepository)
+	bookRepository.On("WithContext", mock.Anything).Return(bookRepository).Maybe()
+
+	schema, _ := gql.NewSchema(authorRepository, bookRepository)
+
+	return controllers.NewGraphQLController(schema)
+}
+
+func permissiveSearchController() *controllers.SearchController {
+	searchRepository := new(mocks.SearchRepository)
+	searchRepository.On("WithContext", mock.Anything).Return(searchRepository).Maybe()
+	searchRepository.On("SearchBooks", mock.Anything, mock.Anything, mock.Anything).Return([]models.BookSearchResult{}, int64(0), nil).Maybe()
+
+	return controllers.NewSearchController(searchRepository)
+}
+
+func permissiveAuditController() *controllers.AuditController {
+	auditRepository := new(mocks.AuditRepository)
+	auditRepository.On("WithContext", mock.Anything).Return(auditRepository).Maybe()
+	auditRepository.On("GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.AuditLog{}, int64(0), nil).Maybe()
+
+	return controllers.NewAuditController(auditRepository)
+}
+
+func serveAs(route authorizationRoute, principal *auth.Principal) *httptest.ResponseRecorder {
+	authorController, bookController := permissiveControllers()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(route.method, route.url, bytes.NewBufferString(route.body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	if route.id != "" {
+		c.Params = gin.Params{{Key: "id", Value: route.id}}
+	}
+
+	if principal != nil {
+		c.Set(auth.PrincipalKey, *principal)
+	}
+
+	route.handler(authorController, bookController)(c)
+
+	return w
+}
+
+func TestAuthorizationForEveryRouteAndRole(t *testing.T) {
+	config.AuthEnabled = true
+	defer func() { config.AuthEnabled = false }()
+
+	for _, route := range authorizationRoutes() {
+		for _, role := range []auth.Role{auth.RoleReader, auth.RoleLibrarian, auth.RoleAdmin} {
+			t.Run(fmt.Sprintf("%s as %s", route.name, role), func(t *testing.T) {
+				w := serveAs(route, &auth.Principal{Subject: "someone", Method: auth.MethodJWT, Role: role})
+
+				if contains(route.allowed, role) {
+					assert.NotEqual(t, http.StatusForbidden, w.Code)
+					assert.Less(t, w.Code, http.StatusBadRequest)
+				} else {
+					assert.Equal(t, http.StatusForbidden, w.Code)
+					assert.JSONEq(t, `{"message": "forbidden"}`, w.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorizationWithoutPrincipal(t *testing.T) {
+	config.AuthEnabled = true
+	defer func() {
+		config.AuthEnabled = false
+		config.AuthPublicReads = true
+	}()
+
+	for _, publicReads := range []bool{true, false} {
+		config.AuthPublicReads = publicReads
+
+		for _, route := range authorizationRoutes() {
+			t.Run(fmt.Sprintf("%s anonymous public reads %t", route.name, publicReads), func(t *testing.T) {
+				w := serveAs(route, nil)
+
+				if contains(route.allowed, auth.RoleReader) && publicReads {
+					assert.Equal(t, http.StatusOK, w.Code)
+				} else {
+					assert.Equal(t, http.StatusForbidden, w.Code)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorizationDisabledAllowsEveryRoute(t *testing.T) {
+	for _, route := range authorizationRoutes() {
+		t.Run(route.name, func(t *testing.T) {
+			w := serveAs(route, nil)
+
+			assert.Less(t, w.Code, http.StatusBadRequest)
+		})
+	}
+}
+
+func contains(roles []auth.Role, role auth.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -2,14 +2,18 @@ package controllers_test
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
 	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
 	"github.com/joaooliveira247/go_olist_challenge/src/errors"
 	"github.com/joaooliveira247/go_olist_challenge/src/models"
@@ -18,9 +22,19 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// bookETag is the ETag GET /books/?bookID answers for book.
+func bookETag(t *testing.T, book models.BookOut) string {
+	body, err := json.Marshal(book)
+	assert.NoError(t, err)
+
+	sum := sha256.Sum256(body)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
 func TestBookCreateSucess(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	bookID := uuid.New()
 	authorsID := []uuid.UUID{uuid.New(), uuid.New()}
@@ -34,13 +48,9 @@ func TestBookCreateSucess(t *testing.T) {
 		AuthorsID: authorsID,
 	}
 
-	mockBookRepository.On("Create", &MBookIn.Book).Return(bookID, nil)
+	mockBookRepository.On("Create", &MBookIn).Return(bookID, nil)
 
-	for _, author := range MBookIn.AuthorsID {
-		mockBookAuthorRepository.On("Create", &models.BookAuthor{BookID: bookID, AuthorID: author}).Return(nil)
-	}
-
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -65,7 +75,7 @@ func TestBookCreateSucess(t *testing.T) {
 
 func TestBookCreateReturnInvalidRequestBody(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	requestBodyTests := []struct {
 		name        string
@@ -124,10 +134,57 @@ func TestBookCreateReturnInvalidRequestBody(t *testing.T) {
 			"authors": ["00000000-0000-0000-0000-000000000000", "00000000-0000-0000-0000-000000000000"]
 }`,
 		},
+		{
+			"ISBN Checksum invalid",
+			fmt.Sprintf(`{
+			"title": "The Rust Programming Language",
+			"edition": 1,
+			"publication_year": 2018,
+			"isbn": "978-1-59327-828-2",
+			"authors": ["%s"]
+}`, uuid.New()),
+		},
+		{
+			"Neither Authors Nor Contributors",
+			`{
+			"title": "The Rust Programming Language",
+			"edition": 1,
+			"publication_year": 2018
+}`,
+		},
+		{
+			"Both Authors And Contributors",
+			fmt.Sprintf(`{
+			"title": "The Rust Programming Language",
+			"edition": 1,
+			"publication_year": 2018,
+			"authors": ["%s"],
+			"contributors": [{"author_id": "%s"}]
+}`, uuid.New(), uuid.New()),
+		},
+		{
+			"Contributor Role invalid",
+			fmt.Sprintf(`{
+			"title": "The Rust Programming Language",
+			"edition": 1,
+			"publication_year": 2018,
+			"contributors": [{"author_id": "%s", "role": "narrator"}]
+}`, uuid.New()),
+		},
+		{
+			"Language invalid",
+			fmt.Sprintf(`{
+			"title": "A Linguagem de Programação Rust",
+			"edition": 1,
+			"publication_year": 2018,
+			"language": "portuguese",
+			"authors": ["%s"]
+}`, uuid.New()),
+		},
 	}
 
 	for _, testCase := range requestBodyTests {
-		controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+		controller := controllers.NewBookController(mockBookRepository)
 
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
@@ -144,9 +201,248 @@ func TestBookCreateReturnInvalidRequestBody(t *testing.T) {
 	}
 }
 
+func TestBookCreateWithContributors(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	bookID := uuid.New()
+	authorID := uuid.New()
+	translatorID := uuid.New()
+
+	mockBookRepository.On("Create", &models.BookIn{
+		Book:         models.Book{Title: "Cem Anos de Solidão", Edition: 1, PublicationYear: 1967},
+		Contributors: []models.Contributor{{AuthorID: authorID}, {AuthorID: translatorID, Role: models.ContributorTranslator}},
+	}).Return(bookID, nil)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+
+	body := fmt.Sprintf(`{
+		"title": "Cem Anos de Solidão",
+		"edition": 1,
+		"publication_year": 1967,
+		"contributors": [{"author_id": "%s"}, {"author_id": "%s", "role": "translator"}]
+}`, authorID, translatorID)
+
+	c.Request, _ = http.NewRequest(http.MethodPost, "/books/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.Create(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockBookRepository.AssertExpectations(t)
+}
+
+func TestBookCreateReturnDuplicateEntries(t *testing.T) {
+	authorID, genreID := uuid.New(), uuid.New()
+
+	testCases := []struct {
+		name string
+		list string
+	}{
+		{"contributors", fmt.Sprintf(`"contributors": [{"author_id": "%s"}, {"author_id": "%s", "role": "translator"}]`, authorID, authorID)},
+		{"authors", fmt.Sprintf(`"authors": ["%s", "%s"]`, authorID, authorID)},
+		{"genres", fmt.Sprintf(`"authors": ["%s"], "genres": ["%s", "%s"]`, authorID, genreID, genreID)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockBookRepository := new(mocks.BookRepository)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+			controller := controllers.NewBookController(mockBookRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+
+			body := fmt.Sprintf(`{"title": "Cem Anos de Solidão", "edition": 1, "publication_year": 1967, %s}`, testCase.list)
+
+			c.Request, _ = http.NewRequest(http.MethodPost, "/books/", bytes.NewBufferString(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			controller.Create(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"message": "authors, contributors and genres can not repeat an entry"}`, w.Body.String())
+			mockBookRepository.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestBookCreateReturnBookAlreadyExists(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	isbn := "978-1-59327-828-1"
+	authorID := uuid.New()
+
+	mockBookRepository.On("Create", &models.BookIn{
+		Book: models.Book{
+			Title:           "The Rust Programming Language",
+			Edition:         1,
+			PublicationYear: 2018,
+			ISBN:            &isbn,
+		},
+		AuthorsID: []uuid.UUID{authorID},
+	}).Return(uuid.Nil, &errors.BookAlreadyExists)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+
+	body := fmt.Sprintf(`{
+		"title": "The Rust Programming Language",
+		"edition": 1,
+		"publication_year": 2018,
+		"isbn": "978-1-59327-828-1",
+		"authors": ["%s"]
+}`, authorID)
+
+	c.Request, _ = http.NewRequest(http.MethodPost, "/books/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.Create(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"message": "book already exists"}`, w.Body.String())
+}
+
+func TestBookCreateWithGenres(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	bookID := uuid.New()
+	authorID := uuid.New()
+	genreID := uuid.New()
+
+	mockBookRepository.On("Create", &models.BookIn{
+		Book:      models.Book{Title: "The Rust Programming Language", Edition: 1, PublicationYear: 2018},
+		AuthorsID: []uuid.UUID{authorID},
+		GenresID:  []uuid.UUID{genreID},
+	}).Return(bookID, nil)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+
+	body := fmt.Sprintf(`{
+		"title": "The Rust Programming Language",
+		"edition": 1,
+		"publication_year": 2018,
+		"authors": ["%s"],
+		"genres": ["%s"]
+}`, authorID, genreID)
+
+	c.Request, _ = http.NewRequest(http.MethodPost, "/books/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.Create(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"id": "%s"}`, bookID), w.Body.String())
+	mockBookRepository.AssertExpectations(t)
+}
+
+func TestBookCreateReturnPublisherNotFound(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	publisherID := uuid.New()
+	authorID := uuid.New()
+
+	mockBookRepository.On("Create", &models.BookIn{
+		Book: models.Book{
+			Title:           "The Rust Programming Language",
+			Edition:         1,
+			PublicationYear: 2018,
+			PublisherID:     &publisherID,
+		},
+		AuthorsID: []uuid.UUID{authorID},
+	}).Return(uuid.Nil, &errors.PublisherNotFound)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+
+	body := fmt.Sprintf(`{
+		"title": "The Rust Programming Language",
+		"edition": 1,
+		"publication_year": 2018,
+		"publisher_id": "%s",
+		"authors": ["%s"]
+}`, publisherID, authorID)
+
+	c.Request, _ = http.NewRequest(http.MethodPost, "/books/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.Create(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message": "publisher not found"}`, w.Body.String())
+}
+
+func TestBookCreateTranslationReturnOriginalBookNotFound(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	originalID := uuid.New()
+	authorID, translatorID := uuid.New(), uuid.New()
+	pt := "pt"
+
+	mockBookRepository.On("Create", &models.BookIn{
+		Book: models.Book{
+			Title:           "Python Fluente",
+			Edition:         1,
+			PublicationYear: 2015,
+			Language:        &pt,
+			TranslationOfID: &originalID,
+		},
+		Contributors: []models.Contributor{{AuthorID: authorID}, {AuthorID: translatorID, Role: models.ContributorTranslator}},
+	}).Return(uuid.Nil, &errors.OriginalBookNotFound)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+
+	body := fmt.Sprintf(`{
+		"title": "Python Fluente",
+		"edition": 1,
+		"publication_year": 2015,
+		"language": "pt",
+		"translation_of": "%s",
+		"contributors": [{"author_id": "%s"}, {"author_id": "%s", "role": "translator"}]
+}`, originalID, authorID, translatorID)
+
+	c.Request, _ = http.NewRequest(http.MethodPost, "/books/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.Create(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message": "original book not found"}`, w.Body.String())
+}
+
 func TestBookCreateReturnUnableCreateEntity(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	mockBookRepository.On("Create", mock.Anything).Return(uuid.Nil, &errors.BookGenericError)
 
@@ -157,7 +453,7 @@ func TestBookCreateReturnUnableCreateEntity(t *testing.T) {
 		"authors": ["4ed37603-c983-4137-bbe9-bccfc30b53a6", "31455548-62a9-4935-aa89-c1d2ac036e0f"]
 	}`
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -172,12 +468,11 @@ func TestBookCreateReturnUnableCreateEntity(t *testing.T) {
 	assert.JSONEq(t, `{"message": "unable to create entity"}`, w.Body.String())
 }
 
-func TestBookCreateReturnUnableCreateEntityWhenCreateRelationship(t *testing.T) {
+func TestBookCreateReturnAuthorNotFound(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
-	mockBookRepository.On("Create", mock.Anything).Return(uuid.New(), nil)
-	mockBookAuthorRepository.On("Create", mock.Anything).Return(&errors.BookAuthorGenericError)
+	mockBookRepository.On("Create", mock.Anything).Return(uuid.Nil, &errors.AuthorNotFound)
 
 	body := `{
 		"title": "The Rust Programming Language",
@@ -186,7 +481,7 @@ func TestBookCreateReturnUnableCreateEntityWhenCreateRelationship(t *testing.T)
 		"authors": ["4ed37603-c983-4137-bbe9-bccfc30b53a6", "31455548-62a9-4935-aa89-c1d2ac036e0f"]
 	}`
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -197,13 +492,13 @@ func TestBookCreateReturnUnableCreateEntityWhenCreateRelationship(t *testing.T)
 
 	controller.Create(c)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.JSONEq(t, `{"message": "unable to create entity"}`, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message": "author not found"}`, w.Body.String())
 }
 
 func TestGetBooksReturnInvalidParam(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	testCases := []struct {
 		name string
@@ -236,7 +531,7 @@ func TestGetBooksReturnInvalidParam(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+		controller := controllers.NewBookController(mockBookRepository)
 
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
@@ -254,7 +549,7 @@ func TestGetBooksReturnInvalidParam(t *testing.T) {
 
 func TestGetBooksReturnInvalidID(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	params := []string{"bookID", "authorID"}
 
@@ -279,7 +574,7 @@ func TestGetBooksReturnInvalidID(t *testing.T) {
 	for _, param := range params {
 		for _, testCase := range testCases {
 			t.Run(fmt.Sprintf("%s %s", param, testCase.name), func(t *testing.T) {
-				controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+				controller := controllers.NewBookController(mockBookRepository)
 
 				w := httptest.NewRecorder()
 				gin.SetMode(gin.TestMode)
@@ -299,7 +594,7 @@ func TestGetBooksReturnInvalidID(t *testing.T) {
 
 func TestGetBooksReturnUnableFetchEntity(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	testCases := []struct {
 		name             string
@@ -336,8 +631,8 @@ func TestGetBooksReturnUnableFetchEntity(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			mockBookRepository.Calls = nil
-			mockBookRepository.On(testCase.methodRepository, mock.Anything).Return(testCase.returnObj, &errors.BookGenericError)
-			controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+			mockBookRepository.On(testCase.methodRepository, mock.Anything, mock.Anything).Return(testCase.returnObj, &errors.BookGenericError)
+			controller := controllers.NewBookController(mockBookRepository)
 
 			w := httptest.NewRecorder()
 			gin.SetMode(gin.TestMode)
@@ -356,13 +651,13 @@ func TestGetBooksReturnUnableFetchEntity(t *testing.T) {
 
 func TestGetBooksQueryBookIDSuccess(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	mbook := mocks.NewMockBookOut()
 
 	mockBookRepository.On("GetBookByID", mbook.ID).Return(mbook, nil)
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -380,16 +675,90 @@ func TestGetBooksQueryBookIDSuccess(t *testing.T) {
 
 }
 
+func TestGetBooksQueryBookIDSetETag(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ifNoneMatch string
+		status      int
+	}{
+		{"without If-None-Match", "", http.StatusOK},
+		{"If-None-Match stale", `"7"`, http.StatusOK},
+		{"If-None-Match current", `{etag}`, http.StatusNotModified},
+		{"If-None-Match weak current", `W/{etag}`, http.StatusNotModified},
+		{"If-None-Match list", `"3", {etag}`, http.StatusNotModified},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockBookRepository := new(mocks.BookRepository)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+			mbook := mocks.NewMockBookOut()
+
+			mockBookRepository.On("GetBookByID", mbook.ID).Return(mbook, nil)
+
+			controller := controllers.NewBookController(mockBookRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/books/?bookID=%s", mbook.ID.String()), nil)
+			if testCase.ifNoneMatch != "" {
+				c.Request.Header.Set("If-None-Match", strings.ReplaceAll(testCase.ifNoneMatch, "{etag}", bookETag(t, mbook)))
+			}
+
+			controller.GetBooks(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+			assert.Equal(t, bookETag(t, mbook), w.Header().Get("ETag"))
+			if testCase.status == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetBooksQueryBookIDETagFollowsContributorRename(t *testing.T) {
+	authorID := uuid.New()
+
+	before := mocks.NewMockBookOut()
+	before.Contributors = models.ContributorsOut{{AuthorID: authorID, Name: "Steve Klabnik", Role: "author"}}
+
+	after := before
+	after.Contributors = models.ContributorsOut{{AuthorID: authorID, Name: "Steve K. Klabnik", Role: "author"}}
+
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+	mockBookRepository.On("GetBookByID", before.ID).Return(after, nil)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/books/?bookID=%s", before.ID.String()), nil)
+	c.Request.Header.Set("If-None-Match", bookETag(t, before))
+
+	controller.GetBooks(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, bookETag(t, after), w.Header().Get("ETag"))
+	assert.NotEqual(t, bookETag(t, before), w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), "Steve K. Klabnik")
+}
+
 func TestGetBooksQueryAuhthorIDSuccess(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	authorID := uuid.New()
 	mbook := mocks.NewMockBooks()[:2]
 
 	mockBookRepository.On("GetBooksByAuthorID", authorID).Return(mbook, nil)
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -408,8 +777,8 @@ func TestGetBooksQueryAuhthorIDSuccess(t *testing.T) {
 }
 
 func TestGetBooksManyQueriesSuccess(t *testing.T) {
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
 	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	Mbooks := mocks.NewMockBooks()
 
@@ -417,46 +786,82 @@ func TestGetBooksManyQueriesSuccess(t *testing.T) {
 		name       string
 		url        string
 		query      string
+		args       []interface{}
 		mockResult []models.BookOut
 	}{
 		{
 			"Title Param Return Two Books",
 			"/books/?title=Python Fluente",
-			"b.title = 'Python Fluente'",
+			"b.title = ?",
+			[]interface{}{"Python Fluente"},
 			Mbooks[:2],
 		},
 		{
 			"Edition Param Return Two Books",
 			"/books/?edition=1",
-			"b.edition = 1",
+			"b.edition = ?",
+			[]interface{}{uint8(1)},
 			[]models.BookOut{Mbooks[1], Mbooks[3]},
 		},
 		{
 			"publicationYear Param Return Two Books",
 			"/books/?publicationYear=2015",
-			"b.publication_year = 2015",
+			"b.publication_year = ?",
+			[]interface{}{uint(2015)},
 			[]models.BookOut{Mbooks[1], Mbooks[2]},
 		},
 		{
 			"Edition and PublicatioYear Return One Book",
 			"/books/?edition=2&publicationYear=2015",
-			"b.edition = 2 AND b.publication_year = 2015",
+			"b.edition = ? AND b.publication_year = ?",
+			[]interface{}{uint8(2), uint(2015)},
 			[]models.BookOut{Mbooks[1]},
 		},
 		{
 			"Title, Edition and PublicationYear Return One Book",
 			"/books/?title=The Go Programming Language&edition=2&publicationYear=2015",
-			"b.title = 'The Go Programming Language' AND b.edition = 2 AND b.publication_year = 2015",
+			"b.title = ? AND b.edition = ? AND b.publication_year = ?",
+			[]interface{}{"The Go Programming Language", uint8(2), uint(2015)},
 			[]models.BookOut{Mbooks[1]},
 		},
+		{
+			"ISBN-10 Param Return One Book",
+			"/books/?isbn=0-306-40615-2",
+			"b.isbn = ?",
+			[]interface{}{"9780306406157"},
+			[]models.BookOut{Mbooks[0]},
+		},
+		{
+			"Publisher Param Return Two Books",
+			"/books/?publisherID=3F8C3BDE-54A6-41D7-BB4F-8D74A33E8E12",
+			"b.publisher_id = ?",
+			[]interface{}{"3f8c3bde-54a6-41d7-bb4f-8d74a33e8e12"},
+			Mbooks[:2],
+		},
+		{
+			"Genre Params Return One Book",
+			"/books/?genre=Programming&genre=Children's",
+			"b.id IN (SELECT bg.book_id FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE lower(g.name) = lower(?)) AND " +
+				"b.id IN (SELECT bg.book_id FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE lower(g.name) = lower(?))",
+			[]interface{}{"Programming", "Children's"},
+			Mbooks[:1],
+		},
+		{
+			"Language Param Return Two Books",
+			"/books/?language=PT",
+			"b.language = ?",
+			[]interface{}{"pt"},
+			Mbooks[2:],
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			mockBookRepository.ExpectedCalls = nil
-			mockBookRepository.On("GetBookByQuery", testCase.query).Return(testCase.mockResult, nil)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+			mockBookRepository.On("GetBookByQuery", testCase.query, testCase.args).Return(testCase.mockResult, nil)
 
-			controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+			controller := controllers.NewBookController(mockBookRepository)
 
 			w := httptest.NewRecorder()
 			gin.SetMode(gin.TestMode)
@@ -475,15 +880,72 @@ func TestGetBooksManyQueriesSuccess(t *testing.T) {
 	}
 }
 
+func TestGetBooksReturnInvalidParamWhenISBNInvalid(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/books/?isbn=0-306-40615-3", nil)
+
+	controller.GetBooks(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "invalid query param"}`, w.Body.String())
+	mockBookRepository.AssertNotCalled(t, "GetBookByQuery", mock.Anything)
+}
+
+func TestGetBooksReturnInvalidParamWhenLanguageInvalid(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/books/?language=por", nil)
+
+	controller.GetBooks(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "invalid query param"}`, w.Body.String())
+	mockBookRepository.AssertNotCalled(t, "GetBookByQuery", mock.Anything)
+}
+
+func TestGetBooksReturnInvalidIDWhenPublisherIDInvalid(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/books/?publisherID=1' OR '1'='1", nil)
+
+	controller.GetBooks(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "invalid id"}`, w.Body.String())
+	mockBookRepository.AssertNotCalled(t, "GetBookByQuery", mock.Anything)
+}
+
 func TestGetBooksAllSuccess(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	Mbooks := mocks.NewMockBooks()
 
 	mockBookRepository.On("GetAll").Return(Mbooks, nil)
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -502,7 +964,7 @@ func TestGetBooksAllSuccess(t *testing.T) {
 
 func TestUpdateBookInfoSucess(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	bookID := uuid.New()
 
@@ -552,9 +1014,9 @@ func TestUpdateBookInfoSucess(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		mockBookRepository.On("Update", bookID, testCase.model).Return(nil)
+		mockBookRepository.On("Update", bookID, testCase.model, uint(0)).Return(nil)
 
-		controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+		controller := controllers.NewBookController(mockBookRepository)
 
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
@@ -573,7 +1035,7 @@ func TestUpdateBookInfoSucess(t *testing.T) {
 
 func TestUpdateBookSingleAuthorIDSuccess(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	bookID := uuid.New()
 
@@ -581,14 +1043,11 @@ func TestUpdateBookSingleAuthorIDSuccess(t *testing.T) {
 		uuid.New(),
 	}
 
-	mockBookAuthorRepository.On("Delete", bookID).Return(nil)
-	mockBookAuthorRepository.On("Create", &models.BookAuthor{
-		BookID: bookID, AuthorID: authors[0],
-	}).Return(nil)
+	mockBookRepository.On("Update", bookID, &models.BookUpdate{AuthorsID: authors}, uint(0)).Return(nil)
 
 	body := fmt.Sprintf(`{"authors": ["%s"]}`, authors[0])
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -606,9 +1065,53 @@ func TestUpdateBookSingleAuthorIDSuccess(t *testing.T) {
 	assert.Empty(t, w.Body.String())
 }
 
+func TestUpdateBookGenres(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		code     int
+		expected string
+	}{
+		{"Success", nil, http.StatusNoContent, ""},
+		{"GenreNotFound", &errors.GenreNotFound, http.StatusNotFound, `{"message": "genre not found"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockBookRepository := new(mocks.BookRepository)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+			bookID := uuid.New()
+			genres := []uuid.UUID{uuid.New(), uuid.New()}
+
+			mockBookRepository.On("Update", bookID, &models.BookUpdate{GenresID: genres}, uint(0)).Return(testCase.err)
+
+			body := fmt.Sprintf(`{"genres": ["%s", "%s"]}`, genres[0], genres[1])
+
+			controller := controllers.NewBookController(mockBookRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPut, fmt.Sprintf("/books/%s", bookID), bytes.NewBufferString(body))
+			c.Params = gin.Params{
+				{Key: "id", Value: bookID.String()},
+			}
+
+			controller.UpdateBook(c)
+
+			assert.Equal(t, testCase.code, w.Code)
+			if testCase.expected != "" {
+				assert.JSONEq(t, testCase.expected, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestUpdateBookDoubleAuthorIDSuccess(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	bookID := uuid.New()
 
@@ -616,16 +1119,11 @@ func TestUpdateBookDoubleAuthorIDSuccess(t *testing.T) {
 		uuid.New(), uuid.New(),
 	}
 
-	mockBookAuthorRepository.On("Delete", bookID).Return(nil)
-	for _, author := range authors {
-		mockBookAuthorRepository.On("Create", &models.BookAuthor{
-			BookID: bookID, AuthorID: author,
-		}).Return(nil)
-	}
+	mockBookRepository.On("Update", bookID, &models.BookUpdate{AuthorsID: authors}, uint(0)).Return(nil)
 
 	body := fmt.Sprintf(`{"authors": ["%s", "%s"]}`, authors[0], authors[1])
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -645,23 +1143,17 @@ func TestUpdateBookDoubleAuthorIDSuccess(t *testing.T) {
 
 func TestUpdateBookFullSucess(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	bookID := uuid.New()
 
 	MUpdate := mocks.NewMockUpdateBook()
 
-	mockBookRepository.On("Update", bookID, &MUpdate).Return(nil)
-	mockBookAuthorRepository.On("Delete", bookID).Return(nil)
-	for _, author := range MUpdate.AuthorsID {
-		mockBookAuthorRepository.On("Create", &models.BookAuthor{
-			BookID: bookID, AuthorID: author,
-		}).Return(nil)
-	}
+	mockBookRepository.On("Update", bookID, &MUpdate, uint(0)).Return(nil)
 
 	body := fmt.Sprintf(`{"title": "%s","edition": %d,"publication_year": %d,"authors": ["%s", "%s"]}`, MUpdate.BookInfo.Title, MUpdate.BookInfo.Edition, MUpdate.BookInfo.PublicationYear, MUpdate.AuthorsID[0], MUpdate.AuthorsID[1])
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -681,7 +1173,7 @@ func TestUpdateBookFullSucess(t *testing.T) {
 
 func TestUpdateBookReturnInvalidID(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	testCases := []struct {
 		name string
@@ -701,7 +1193,7 @@ func TestUpdateBookReturnInvalidID(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+		controller := controllers.NewBookController(mockBookRepository)
 
 		w := httptest.NewRecorder()
 
@@ -723,7 +1215,7 @@ func TestUpdateBookReturnInvalidID(t *testing.T) {
 
 func TestUpdateBookReturnInvalidParam(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	testCases := []struct {
 		name string
@@ -778,7 +1270,7 @@ func TestUpdateBookReturnInvalidParam(t *testing.T) {
 	bookID := uuid.New()
 
 	for _, testCase := range testCases {
-		controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+		controller := controllers.NewBookController(mockBookRepository)
 
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
@@ -798,6 +1290,33 @@ func TestUpdateBookReturnInvalidParam(t *testing.T) {
 	}
 }
 
+func TestUpdateBookReturnDuplicateEntries(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	bookID, authorID := uuid.New(), uuid.New()
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+
+	body := fmt.Sprintf(`{"contributors": [{"author_id": "%s", "role": "editor"}, {"author_id": "%s"}]}`, authorID, authorID)
+
+	c.Request, _ = http.NewRequest(http.MethodPut, fmt.Sprintf("/books/%s", bookID.String()), bytes.NewBufferString(body))
+	c.Params = gin.Params{
+		{Key: "id", Value: bookID.String()},
+	}
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.UpdateBook(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "authors, contributors and genres can not repeat an entry"}`, w.Body.String())
+	mockBookRepository.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestUpdateBookInfoReturnError(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -825,13 +1344,13 @@ func TestUpdateBookInfoReturnError(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			mockBookRepository := new(mocks.BookRepository)
-			mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 			bookID := uuid.New()
 
-			mockBookRepository.On("Update", mock.Anything, mock.Anything).Return(testCase.errorReturn)
+			mockBookRepository.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(testCase.errorReturn)
 
-			controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+			controller := controllers.NewBookController(mockBookRepository)
 
 			w := httptest.NewRecorder()
 			gin.SetMode(gin.TestMode)
@@ -858,15 +1377,15 @@ func TestUpdateBookInfoReturnError(t *testing.T) {
 
 func TestUpdateBookWhenDeleteRefReturnError(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	bookID := uuid.New()
 
 	body := fmt.Sprintf(`{"authors": ["%s"]}`, bookID.String())
 
-	mockBookAuthorRepository.On("Delete", bookID).Return(&errors.BookAuthorGenericError)
+	mockBookRepository.On("Update", bookID, mock.Anything, uint(0)).Return(&errors.BookAuthorGenericError)
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -884,18 +1403,17 @@ func TestUpdateBookWhenDeleteRefReturnError(t *testing.T) {
 	assert.JSONEq(t, `{"message": "unable to fetch entity"}`, w.Body.String())
 }
 
-func TestUpdateBookWhenCreateRefReturnError(t *testing.T) {
+func TestUpdateBookReturnAuthorNotFound(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	bookID := uuid.New()
 
 	body := fmt.Sprintf(`{"authors": ["%s"]}`, bookID.String())
 
-	mockBookAuthorRepository.On("Delete", mock.Anything).Return(nil)
-	mockBookAuthorRepository.On("Create", mock.Anything).Return(&errors.BookAuthorGenericError)
+	mockBookRepository.On("Update", bookID, mock.Anything, uint(0)).Return(&errors.AuthorNotFound)
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -909,8 +1427,8 @@ func TestUpdateBookWhenCreateRefReturnError(t *testing.T) {
 
 	controller.UpdateBook(c)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.JSONEq(t, `{"message": "unable to fetch entity"}`, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message": "author not found"}`, w.Body.String())
 }
 
 func TestDeleteBookReturnInvalidID(t *testing.T) {
@@ -931,9 +1449,9 @@ func TestDeleteBookReturnInvalidID(t *testing.T) {
 
 	for _, testCase := range testCases {
 		mockBookRepository := new(mocks.BookRepository)
-		mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+		mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
-		controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+		controller := controllers.NewBookController(mockBookRepository)
 
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
@@ -977,11 +1495,12 @@ func TestDeleteBookReturnError(t *testing.T) {
 		bookID := uuid.New()
 
 		mockBookRepository := new(mocks.BookRepository)
-		mockBookAuthorRepository := new(mocks.BookAuthorRepository)
 
-		mockBookRepository.On("Delete", bookID).Return(testCase.returnError)
+		mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+		mockBookRepository.On("Delete", bookID, uint(0)).Return(testCase.returnError)
 
-		controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+		controller := controllers.NewBookController(mockBookRepository)
 
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
@@ -1002,13 +1521,13 @@ func TestDeleteBookReturnError(t *testing.T) {
 
 func TestDeleteBookSuccess(t *testing.T) {
 	mockBookRepository := new(mocks.BookRepository)
-	mockBookAuthorRepository := new(mocks.BookAuthorRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
 
 	bookID := uuid.New()
 
-	mockBookRepository.On("Delete", bookID).Return(nil)
+	mockBookRepository.On("Delete", bookID, uint(0)).Return(nil)
 
-	controller := controllers.NewBookController(mockBookRepository, mockBookAuthorRepository)
+	controller := controllers.NewBookController(mockBookRepository)
 
 	w := httptest.NewRecorder()
 	gin.SetMode(gin.TestMode)
@@ -1025,3 +1544,307 @@ func TestDeleteBookSuccess(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, w.Code)
 	assert.Empty(t, w.Body.String())
 }
+
+func TestUpdateBookWithIfMatch(t *testing.T) {
+	testCases := []struct {
+		name            string
+		ifMatch         string
+		requireIfMatch  bool
+		version         uint
+		updateError     error
+		status          int
+		expectedMessage string
+	}{
+		{"If-Match matches", `{etag}`, false, 3, nil, http.StatusNoContent, ""},
+		{"If-Match any", `*`, false, 0, nil, http.StatusNoContent, ""},
+		{"If-Match stale", `"2"`, false, 0, nil, http.StatusPreconditionFailed, `{"message": "version does not match"}`},
+		{"If-Match changed before the lock", `{etag}`, false, 3, &errors.BookVersionMismatch, http.StatusPreconditionFailed, `{"message": "version does not match"}`},
+		{"If-Match weak", `W/{etag}`, false, 0, nil, http.StatusPreconditionFailed, `{"message": "version does not match"}`},
+		{"If-Match required", "", true, 0, nil, http.StatusPreconditionRequired, `{"message": "If-Match header required"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config.RequireIfMatch = testCase.requireIfMatch
+			defer func() { config.RequireIfMatch = false }()
+
+			mockBookRepository := new(mocks.BookRepository)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+			current := mocks.NewMockBookOut()
+			current.Version = 3
+			bookID := current.ID
+
+			mockBookRepository.On("GetBookByID", bookID).Return(current, nil).Maybe()
+			mockBookRepository.On("Update", bookID, mock.Anything, testCase.version).Return(testCase.updateError).Maybe()
+
+			controller := controllers.NewBookController(mockBookRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPut, fmt.Sprintf("/books/%s", bookID), bytes.NewBufferString(`{"edition": 2}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if testCase.ifMatch != "" {
+				c.Request.Header.Set("If-Match", strings.ReplaceAll(testCase.ifMatch, "{etag}", bookETag(t, current)))
+			}
+			c.Params = gin.Params{
+				{Key: "id", Value: bookID.String()},
+			}
+
+			controller.UpdateBook(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+			if testCase.expectedMessage != "" {
+				assert.JSONEq(t, testCase.expectedMessage, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateBookWithIfMatchListUseCurrentVersion(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	mbook := mocks.NewMockBookOut()
+
+	mockBookRepository.On("GetBookByID", mbook.ID).Return(mbook, nil)
+	mockBookRepository.On("Update", mbook.ID, mock.Anything, uint(1)).Return(nil)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPut, fmt.Sprintf("/books/%s", mbook.ID), bytes.NewBufferString(`{"edition": 2}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"4", `+bookETag(t, mbook))
+	c.Params = gin.Params{
+		{Key: "id", Value: mbook.ID.String()},
+	}
+
+	controller.UpdateBook(c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockBookRepository.AssertExpectations(t)
+}
+
+func TestDeleteBookWithIfMatch(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ifMatch     string
+		getError    error
+		deletes     bool
+		deleteError error
+		status      int
+	}{
+		{"If-Match matches", `{etag}`, nil, true, nil, http.StatusNoContent},
+		{"If-Match stale", `"4"`, nil, false, nil, http.StatusPreconditionFailed},
+		{"If-Match changed before the lock", `{etag}`, nil, true, &errors.BookVersionMismatch, http.StatusPreconditionFailed},
+		{"If-Match not found", `{etag}`, &errors.BookNotFound, false, nil, http.StatusPreconditionFailed},
+		{"If-Match fetch error", `{etag}`, &errors.BookGenericError, false, nil, http.StatusInternalServerError},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockBookRepository := new(mocks.BookRepository)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+			current := mocks.NewMockBookOut()
+			current.Version = 5
+			bookID := current.ID
+
+			if testCase.getError != nil {
+				mockBookRepository.On("GetBookByID", bookID).Return(models.BookOut{}, testCase.getError)
+			} else {
+				mockBookRepository.On("GetBookByID", bookID).Return(current, nil)
+			}
+			if testCase.deletes {
+				mockBookRepository.On("Delete", bookID, uint(5)).Return(testCase.deleteError)
+			}
+
+			controller := controllers.NewBookController(mockBookRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/books/%s", bookID), nil)
+			c.Request.Header.Set("If-Match", strings.ReplaceAll(testCase.ifMatch, "{etag}", bookETag(t, current)))
+			c.Params = gin.Params{
+				{Key: "id", Value: bookID.String()},
+			}
+
+			controller.DeleteBook(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+			mockBookRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetBooksIncludeDeletedUseUnscoped(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	unscopedRepository := new(mocks.BookRepository)
+
+	books := mocks.NewMockBooks()
+
+	mockBookRepository.On("Unscoped").Return(unscopedRepository)
+	unscopedRepository.On("GetAll").Return(books, nil)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/books/?includeDeleted=true", nil)
+
+	controller.GetBooks(c)
+
+	expected, _ := json.Marshal(books)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, string(expected), w.Body.String())
+	mockBookRepository.AssertNotCalled(t, "GetAll")
+}
+
+func TestRestoreBook(t *testing.T) {
+	testCases := []struct {
+		name            string
+		id              string
+		restoreError    error
+		status          int
+		expectedMessage string
+	}{
+		{"success", uuid.New().String(), nil, http.StatusNoContent, ""},
+		{"invalid id", uuid.Nil.String(), nil, http.StatusBadRequest, `{"message": "invalid id"}`},
+		{"not deleted", uuid.New().String(), &errors.BookNotFound, http.StatusNotFound, `{"message": "book not found"}`},
+		{"isbn taken", uuid.New().String(), &errors.BookAlreadyExists, http.StatusConflict, `{"message": "book already exists"}`},
+		{"generic error", uuid.New().String(), &errors.BookGenericError, http.StatusInternalServerError, `{"message": "unable to fetch entity"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockBookRepository := new(mocks.BookRepository)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+			mockBookRepository.On("Restore", mock.Anything).Return(testCase.restoreError).Maybe()
+
+			controller := controllers.NewBookController(mockBookRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/books/%s/restore", testCase.id), nil)
+			c.Params = gin.Params{
+				{Key: "id", Value: testCase.id},
+			}
+
+			controller.RestoreBook(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+			if testCase.expectedMessage != "" {
+				assert.JSONEq(t, testCase.expectedMessage, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateEdition(t *testing.T) {
+	editionID := uuid.New()
+
+	testCases := []struct {
+		name            string
+		id              string
+		body            string
+		createError     error
+		status          int
+		expectedMessage string
+	}{
+		{"success", uuid.New().String(), `{"edition": 2, "publication_year": 2022}`, nil, http.StatusCreated, fmt.Sprintf(`{"id": "%s"}`, editionID)},
+		{"invalid id", uuid.Nil.String(), `{"edition": 2, "publication_year": 2022}`, nil, http.StatusBadRequest, `{"message": "invalid id"}`},
+		{"missing edition", uuid.New().String(), `{"publication_year": 2022}`, nil, http.StatusUnprocessableEntity, `{"message": "request body invalid"}`},
+		{"book not found", uuid.New().String(), `{"edition": 2, "publication_year": 2022}`, &errors.BookNotFound, http.StatusNotFound, `{"message": "book not found"}`},
+		{"edition exists", uuid.New().String(), `{"edition": 1, "publication_year": 2015}`, &errors.EditionAlreadyExists, http.StatusConflict, `{"message": "edition already exists"}`},
+		{"publisher not found", uuid.New().String(), `{"edition": 2, "publication_year": 2022, "publisher_id": "3f8c3bde-54a6-41d7-bb4f-8d74a33e8e12"}`, &errors.PublisherNotFound, http.StatusNotFound, `{"message": "publisher not found"}`},
+		{"generic error", uuid.New().String(), `{"edition": 2, "publication_year": 2022}`, &errors.BookGenericError, http.StatusInternalServerError, `{"message": "unable to create entity"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockBookRepository := new(mocks.BookRepository)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+			mockBookRepository.On("CreateEdition", mock.Anything, mock.Anything).Return(editionID, testCase.createError).Maybe()
+
+			controller := controllers.NewBookController(mockBookRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/books/%s/editions", testCase.id), bytes.NewBufferString(testCase.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{
+				{Key: "id", Value: testCase.id},
+			}
+
+			controller.CreateEdition(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+			assert.JSONEq(t, testCase.expectedMessage, w.Body.String())
+		})
+	}
+}
+
+func TestGetTranslations(t *testing.T) {
+	translations := mocks.NewMockBooks()[2:]
+
+	testCases := []struct {
+		name             string
+		id               string
+		bookError        error
+		translationError error
+		status           int
+		expectedBody     string
+	}{
+		{"success", uuid.New().String(), nil, nil, http.StatusOK, ""},
+		{"invalid id", "not-an-id", nil, nil, http.StatusBadRequest, `{"message": "invalid id"}`},
+		{"book not found", uuid.New().String(), &errors.BookNotFound, nil, http.StatusNotFound, `{"message": "book not found"}`},
+		{"generic error", uuid.New().String(), nil, &errors.BookGenericError, http.StatusInternalServerError, `{"message": "unable to fetch entity"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockBookRepository := new(mocks.BookRepository)
+			mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+			mockBookRepository.On("GetBookByID", mock.Anything).Return(models.BookOut{}, testCase.bookError).Maybe()
+			mockBookRepository.On("GetTranslations", mock.Anything).Return(translations, testCase.translationError).Maybe()
+
+			controller := controllers.NewBookController(mockBookRepository)
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/books/%s/translations", testCase.id), nil)
+			c.Params = gin.Params{
+				{Key: "id", Value: testCase.id},
+			}
+
+			controller.GetTranslations(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+
+			if testCase.expectedBody == "" {
+				body, _ := json.Marshal(translations)
+				testCase.expectedBody = string(body)
+			}
+			assert.JSONEq(t, testCase.expectedBody, w.Body.String())
+		})
+	}
+}
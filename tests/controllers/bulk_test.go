@@ -0,0 +1,296 @@
+package controllers_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func bulkRequest(target string, body string) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodPost, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	return w, c
+}
+
+func TestCreateAuthorsAtomicSuccess(t *testing.T) {
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	authors := []models.Author{{Name: "Luciano Ramalho"}, {Name: "Alan Donovan"}}
+
+	mockRepository.On("CreateMany", &authors).Return(ids, nil)
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w, c := bulkRequest("/authors/bulk", `[{"name": "Luciano Ramalho"}, {"name": "Alan Donovan"}]`)
+
+	controller.CreateAuthors(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(
+		t,
+		fmt.Sprintf(`{"items": [{"index": 0, "status": 201, "id": "%s"}, {"index": 1, "status": 201, "id": "%s"}]}`, ids[0], ids[1]),
+		w.Body.String(),
+	)
+}
+
+func TestCreateAuthorsAtomicReturnInvalidItems(t *testing.T) {
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w, c := bulkRequest("/authors/bulk", `[{"name": "Luciano Ramalho"}, {"name": "A"}]`)
+
+	controller.CreateAuthors(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(
+		t,
+		`{"message": "request body invalid", "items": [{"index": 1, "status": 422, "error": "request body invalid"}]}`,
+		w.Body.String(),
+	)
+	mockRepository.AssertNotCalled(t, "CreateMany", mock.Anything)
+}
+
+func TestCreateAuthorsAtomicReturnAlreadyExists(t *testing.T) {
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+	mockRepository.On("CreateMany", mock.Anything).Return(nil, &errors.AuthorAlreadyExists)
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w, c := bulkRequest("/authors/bulk", `[{"name": "Luciano Ramalho"}, {"name": "Luciano Ramalho"}]`)
+
+	controller.CreateAuthors(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"message": "author already exists"}`, w.Body.String())
+}
+
+func TestCreateAuthorsPartialReturnMultiStatus(t *testing.T) {
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	id := uuid.New()
+
+	mockRepository.On("Create", &models.Author{Name: "Luciano Ramalho"}).Return(id, nil)
+	mockRepository.On("Create", &models.Author{Name: "Alan Donovan"}).Return(uuid.Nil, &errors.AuthorAlreadyExists)
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w, c := bulkRequest(
+		"/authors/bulk?mode=partial",
+		`[{"name": "Luciano Ramalho"}, {"name": "Alan Donovan"}, {"name": ""}]`,
+	)
+
+	controller.CreateAuthors(c)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.JSONEq(
+		t,
+		fmt.Sprintf(`{"items": [
+			{"index": 0, "status": 201, "id": "%s"},
+			{"index": 1, "status": 409, "error": "author already exists"},
+			{"index": 2, "status": 422, "error": "request body invalid"}
+		]}`, id),
+		w.Body.String(),
+	)
+}
+
+func TestCreateAuthorsReturnTooManyItems(t *testing.T) {
+	limit := config.BulkMaxItems
+	config.BulkMaxItems = 1
+	defer func() { config.BulkMaxItems = limit }()
+
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w, c := bulkRequest("/authors/bulk", `[{"name": "Luciano Ramalho"}, {"name": "Alan Donovan"}]`)
+
+	controller.CreateAuthors(c)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.JSONEq(t, `{"message": "too many items"}`, w.Body.String())
+}
+
+func TestCreateAuthorsStopsReadingPastTheLimit(t *testing.T) {
+	limit := config.BulkMaxItems
+	config.BulkMaxItems = 1
+	defer func() { config.BulkMaxItems = limit }()
+
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	body := &countingReader{Reader: strings.NewReader(`[{"name": "Luciano Ramalho"}, {"name": "Alan Donovan"}` + strings.Repeat(`, {"name": "Brian Kernighan"}`, 10000) + `]`)}
+	w, c := bulkRequest("/authors/bulk", "")
+	c.Request.Body = io.NopCloser(body)
+
+	controller.CreateAuthors(c)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Less(t, body.read, 64*1024)
+}
+
+// countingReader counts the bytes read from Reader.
+type countingReader struct {
+	io.Reader
+	read int
+}
+
+func (reader *countingReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	reader.read += n
+	return n, err
+}
+
+func TestCreateAuthorsReturnInvalidRequestBody(t *testing.T) {
+	for _, body := range []string{`[]`, `null`, `{"name": "Luciano Ramalho"}`, `[{"name": "Luciano Ramalho"}`} {
+		mockRepository := new(mocks.AuthorRepository)
+		mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+		controller := controllers.NewAuthorController(mockRepository)
+
+		w, c := bulkRequest("/authors/bulk", body)
+
+		controller.CreateAuthors(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, body)
+		assert.JSONEq(t, `{"message": "request body invalid"}`, w.Body.String())
+	}
+}
+
+func TestCreateAuthorsReturnInvalidParam(t *testing.T) {
+	mockRepository := new(mocks.AuthorRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	controller := controllers.NewAuthorController(mockRepository)
+
+	w, c := bulkRequest("/authors/bulk?mode=some", `[{"name": "Luciano Ramalho"}]`)
+
+	controller.CreateAuthors(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "invalid query param"}`, w.Body.String())
+}
+
+func TestCreateBooksAtomicSuccess(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	bookID := uuid.New()
+	authorID := uuid.New()
+
+	books := []models.BookIn{
+		{
+			Book: models.Book{
+				Title:           "The Rust Programming Language",
+				Edition:         1,
+				PublicationYear: 2018,
+			},
+			AuthorsID: []uuid.UUID{authorID},
+		},
+	}
+
+	mockBookRepository.On("CreateMany", books).Return([]uuid.UUID{bookID}, nil)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w, c := bulkRequest("/books/bulk", fmt.Sprintf(`[{
+		"title": "The Rust Programming Language",
+		"edition": 1,
+		"publication_year": 2018,
+		"authors": ["%s"]
+	}]`, authorID))
+
+	controller.CreateBooks(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"items": [{"index": 0, "status": 201, "id": "%s"}]}`, bookID), w.Body.String())
+}
+
+func TestCreateBooksAtomicReturnAuthorNotFound(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	mockBookRepository.On("CreateMany", mock.Anything).Return(nil, &errors.AuthorNotFound)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w, c := bulkRequest("/books/bulk", fmt.Sprintf(`[{
+		"title": "The Rust Programming Language",
+		"edition": 1,
+		"publication_year": 2018,
+		"authors": ["%s"]
+	}]`, uuid.New()))
+
+	controller.CreateBooks(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message": "author not found"}`, w.Body.String())
+}
+
+func TestCreateBooksPartialReturnMultiStatus(t *testing.T) {
+	mockBookRepository := new(mocks.BookRepository)
+	mockBookRepository.On("WithContext", mock.Anything).Return(mockBookRepository).Maybe()
+
+	bookID := uuid.New()
+	authorID := uuid.New()
+
+	created := models.BookIn{
+		Book:      models.Book{Title: "The Rust Programming Language", Edition: 1, PublicationYear: 2018},
+		AuthorsID: []uuid.UUID{authorID},
+	}
+	existing := models.BookIn{
+		Book:      models.Book{Title: "The Go Programming Language", Edition: 1, PublicationYear: 2015},
+		AuthorsID: []uuid.UUID{authorID},
+	}
+
+	mockBookRepository.On("CreateMany", []models.BookIn{created}).Return([]uuid.UUID{bookID}, nil)
+	mockBookRepository.On("CreateMany", []models.BookIn{existing}).Return(nil, &errors.BookAlreadyExists)
+
+	controller := controllers.NewBookController(mockBookRepository)
+
+	w, c := bulkRequest("/books/bulk?mode=partial", fmt.Sprintf(`[
+		{"title": "The Rust Programming Language", "edition": 1, "publication_year": 2018, "authors": ["%[1]s"]},
+		{"title": "The Go Programming Language", "edition": 1, "publication_year": 2015, "authors": ["%[1]s"]},
+		{"title": "The C Programming Language", "edition": 2, "publication_year": 1988, "authors": []}
+	]`, authorID))
+
+	controller.CreateBooks(c)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.JSONEq(
+		t,
+		fmt.Sprintf(`{"items": [
+			{"index": 0, "status": 201, "id": "%s"},
+			{"index": 1, "status": 409, "error": "book already exists"},
+			{"index": 2, "status": 422, "error": "request body invalid"}
+		]}`, bookID),
+		w.Body.String(),
+	)
+}
@@ -0,0 +1,73 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveCacheStats(readCache *cache.Cache, principal *auth.Principal) *httptest.ResponseRecorder {
+	controller := controllers.NewCacheController(readCache)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/cache/stats", nil)
+
+	if principal != nil {
+		c.Set(auth.PrincipalKey, *principal)
+	}
+
+	controller.GetStats(c)
+
+	return w
+}
+
+func TestGetCacheStatsSuccess(t *testing.T) {
+	readCache := cache.New(time.Minute, 10, nil)
+	load := func() (string, error) { return "Jorge Amado", nil }
+
+	cache.Fetch(context.Background(), readCache, "author", load, func(string) []string { return nil })
+	cache.Fetch(context.Background(), readCache, "author", load, func(string) []string { return nil })
+
+	w := serveCacheStats(readCache, nil)
+
+	var stats cache.Stats
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, cache.Stats{Enabled: true, Hits: 1, Misses: 1, Entries: 1}, stats)
+}
+
+func TestGetCacheStatsDisabled(t *testing.T) {
+	w := serveCacheStats(nil, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"enabled": false, "hits": 0, "misses": 0, "bypasses": 0, "evictions": 0, "invalidations": 0, "entries": 0}`, w.Body.String())
+}
+
+func TestGetCacheStatsAdminOnly(t *testing.T) {
+	config.AuthEnabled = true
+	defer func() { config.AuthEnabled = false }()
+
+	for _, role := range []auth.Role{auth.RoleReader, auth.RoleLibrarian} {
+		w := serveCacheStats(nil, &auth.Principal{Subject: "someone", Method: auth.MethodJWT, Role: role})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	}
+
+	w := serveCacheStats(nil, &auth.Principal{Subject: "someone", Method: auth.MethodJWT, Role: auth.RoleAdmin})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,152 @@
+package controllers_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/events"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newEventServer(repository *mocks.EventRepository) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+
+	broker := events.NewBroker(repository, 5*time.Millisecond, nil)
+	eng.GET("/events", controllers.NewEventController(broker, repository).StreamEvents)
+
+	return httptest.NewServer(eng)
+}
+
+func newEventRepository(last uint64) *mocks.EventRepository {
+	repository := new(mocks.EventRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("LastID").Return(last, nil)
+
+	return repository
+}
+
+// readEventIDs reads the ids of the first count events of the stream.
+func readEventIDs(t *testing.T, response *http.Response, count int) []string {
+	ids := make(chan string)
+
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			if id, ok := strings.CutPrefix(scanner.Text(), "id:"); ok {
+				ids <- id
+			}
+		}
+		close(ids)
+	}()
+
+	var read []string
+
+	for len(read) < count {
+		select {
+		case id, ok := <-ids:
+			if !ok {
+				return read
+			}
+			read = append(read, id)
+		case <-time.After(time.Second):
+			t.Fatalf("read %v, waiting for %d events", read, count)
+		}
+	}
+
+	return read
+}
+
+func TestStreamEventsSendsCommittedEvents(t *testing.T) {
+	repository := newEventRepository(2)
+	repository.On("GetAfter", uint64(2), uint64(0), []string(nil), 500).
+		Return([]models.OutboxEvent{{ID: 3, Type: models.EventAuthorCreated}, {ID: 4, Type: models.EventBookCreated}}, nil).Once()
+	repository.On("GetAfter", uint64(4), uint64(0), []string(nil), 500).Return([]models.OutboxEvent{}, nil)
+
+	server := newEventServer(repository)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/events")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	assert.Equal(t, []string{"3", "4"}, readEventIDs(t, response, 2))
+}
+
+func TestStreamEventsResumesFromLastEventID(t *testing.T) {
+	repository := newEventRepository(7)
+	repository.On("GetAfter", uint64(5), uint64(7), []string(nil), 500).
+		Return([]models.OutboxEvent{{ID: 6, Type: models.EventBookUpdated}, {ID: 7, Type: models.EventBookDeleted}}, nil).Once()
+	repository.On("GetAfter", uint64(7), uint64(0), []string(nil), 500).
+		Return([]models.OutboxEvent{{ID: 8, Type: models.EventAuthorUpdated}}, nil).Once()
+	repository.On("GetAfter", uint64(8), uint64(0), []string(nil), 500).Return([]models.OutboxEvent{}, nil)
+
+	server := newEventServer(repository)
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	request.Header.Set("Last-Event-ID", "5")
+
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, []string{"6", "7", "8"}, readEventIDs(t, response, 3))
+}
+
+func TestStreamEventsFiltersByEntity(t *testing.T) {
+	repository := newEventRepository(0)
+	repository.On("GetAfter", uint64(0), uint64(0), []string(nil), 500).
+		Return([]models.OutboxEvent{{ID: 1, Type: models.EventAuthorCreated}, {ID: 2, Type: models.EventBookCreated}}, nil).Once()
+	repository.On("GetAfter", uint64(2), uint64(0), []string(nil), 500).
+		Return([]models.OutboxEvent{{ID: 3, Type: models.EventAuthorDeleted}, {ID: 4, Type: models.EventBookDeleted}}, nil).Once()
+	repository.On("GetAfter", uint64(4), uint64(0), []string(nil), 500).Return([]models.OutboxEvent{}, nil)
+
+	server := newEventServer(repository)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/events?entity=book")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, []string{"2", "4"}, readEventIDs(t, response, 2))
+}
+
+func TestStreamEventsReturnBadRequest(t *testing.T) {
+	for name, request := range map[string]func(url string) *http.Request{
+		"last event id": func(url string) *http.Request {
+			request, _ := http.NewRequest(http.MethodGet, url+"/events", nil)
+			request.Header.Set("Last-Event-ID", "abc")
+			return request
+		},
+		"entity": func(url string) *http.Request {
+			request, _ := http.NewRequest(http.MethodGet, url+"/events?entity=genre", nil)
+			return request
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repository := newEventRepository(0)
+
+			server := newEventServer(repository)
+			defer server.Close()
+
+			response, err := http.DefaultClient.Do(request(server.URL))
+			assert.NoError(t, err)
+			defer response.Body.Close()
+
+			assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+			repository.AssertNotCalled(t, "LastID")
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package controllers_test
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/utils"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func serveBooks(repository *mocks.BookRepository, url string, accept string) *httptest.ResponseRecorder {
+
+	controller := controllers.NewBookController(repository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, url, nil)
+
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+
+	if strings.Contains(url, "/translations") {
+		c.Params = gin.Params{{Key: "id", Value: strings.Split(url, "/")[2]}}
+		controller.GetTranslations(c)
+	} else {
+		controller.GetBooks(c)
+	}
+
+	return w
+}
+
+func serveAuthors(repository *mocks.AuthorRepository, url string, accept string) *httptest.ResponseRecorder {
+	controller := controllers.NewAuthorController(repository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, url, nil)
+
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+
+	controller.GetAuthors(c)
+
+	return w
+}
+
+// yields makes an Each mock hand items to the callback it is given.
+func yields[T any](items []T) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		yield := args.Get(len(args) - 1).(func(T) error)
+
+		for _, item := range items {
+			if yield(item) != nil {
+				return
+			}
+		}
+	}
+}
+
+func readCSV(t *testing.T, body string) [][]string {
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+
+	assert.NoError(t, err)
+
+	return records
+}
+
+func TestGetBooksAsCSV(t *testing.T) {
+	isbn, isbn10, language := "9788575224625", "857522462X", "pt"
+	books := []models.BookOut{
+		{
+			Book:         models.Book{ID: uuid.New(), Title: "Python Fluente", Edition: 1, PublicationYear: 2015, ISBN: &isbn, Language: &language, Version: 2},
+			ISBN10:       &isbn10,
+			AuthorsName:  pq.StringArray{"Luciano Ramalho"},
+			Contributors: models.ContributorsOut{{AuthorID: uuid.New(), Name: "Luciano Ramalho", Role: "author"}, {AuthorID: uuid.New(), Name: "Lúcia Kinoshita", Role: "translator"}},
+			GenresName:   pq.StringArray{"Programming", "Python"},
+		},
+	}
+
+	for _, accept := range []string{"text/csv", "text/csv; charset=utf-8", "text/html, text/csv;q=0.9"} {
+		t.Run(accept, func(t *testing.T) {
+			repository := new(mocks.BookRepository)
+			repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+			repository.On("EachBook", "b.language = ?", []interface{}{"pt"}, mock.Anything).Run(yields(books)).Return(nil)
+
+			w := serveBooks(repository, "/books/?language=pt", accept)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename="books.csv"`, w.Header().Get("Content-Disposition"))
+
+			records := readCSV(t, w.Body.String())
+
+			assert.Equal(t, [][]string{
+				utils.BookExportColumns,
+				{
+					books[0].ID.String(), "Python Fluente", "1", "2015", "9788575224625", "857522462X", "", "", "pt", "", "2",
+					"Luciano Ramalho", "Luciano Ramalho (author); Lúcia Kinoshita (translator)", "Programming; Python", "",
+				},
+			}, records)
+		})
+	}
+}
+
+func TestGetBooksAsNDJSON(t *testing.T) {
+	books := mocks.NewMockBooks()
+	authorID := uuid.New()
+
+	repository := new(mocks.BookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("EachBookByAuthorID", authorID, mock.Anything).Run(yields(books)).Return(nil)
+
+	w := serveBooks(repository, "/books/?authorID="+authorID.String(), "application/x-ndjson")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	scanner := bufio.NewScanner(w.Body)
+	lines := 0
+
+	for scanner.Scan() {
+		var book models.BookOut
+
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &book))
+		assert.Equal(t, books[lines].ID, book.ID)
+		lines++
+	}
+
+	assert.Equal(t, len(books), lines)
+}
+
+func TestGetBooksFormatOverridesAccept(t *testing.T) {
+	repository := new(mocks.BookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("EachBook", "", []interface{}{}, mock.Anything).Run(yields(mocks.NewMockBooks())).Return(nil)
+	repository.On("GetAll").Return(mocks.NewMockBooks(), nil)
+
+	w := serveBooks(repository, "/books/?format=csv", "application/json")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, readCSV(t, w.Body.String()), len(mocks.NewMockBooks())+1)
+
+	w = serveBooks(repository, "/books/?format=json", "text/csv")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+}
+
+func TestGetBooksExportWritesEveryRow(t *testing.T) {
+	books := make([]models.BookOut, 250)
+
+	for i := range books {
+		books[i] = models.BookOut{Book: models.Book{ID: uuid.New(), Title: "Fluent Python"}}
+	}
+
+	repository := new(mocks.BookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("EachBook", "", []interface{}{}, mock.Anything).Run(yields(books)).Return(nil)
+
+	w := serveBooks(repository, "/books/?format=csv", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, readCSV(t, w.Body.String()), len(books)+1)
+	repository.AssertNotCalled(t, "GetAll")
+}
+
+func TestGetBooksExportWithoutRowsWritesHeader(t *testing.T) {
+	repository := new(mocks.BookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("EachBook", "", []interface{}{}, mock.Anything).Return(nil)
+
+	w := serveBooks(repository, "/books/?format=csv", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, [][]string{utils.BookExportColumns}, readCSV(t, w.Body.String()))
+}
+
+func TestGetBooksExportReturnUnableFetchEntityBeforeFirstRow(t *testing.T) {
+	repository := new(mocks.BookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("EachBook", "", []interface{}{}, mock.Anything).Return(errors.New("connection reset"))
+
+	w := serveBooks(repository, "/books/?format=ndjson", "")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"message": "unable to fetch entity"}`, w.Body.String())
+}
+
+func TestGetBooksReturnInvalidParamWhenFormatUnknown(t *testing.T) {
+	repository := new(mocks.BookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+
+	w := serveBooks(repository, "/books/?format=xml", "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "invalid query param"}`, w.Body.String())
+	repository.AssertNotCalled(t, "GetAll")
+}
+
+func TestGetBookByIDAsCSVSkipsETag(t *testing.T) {
+	book := mocks.NewMockBooks()[0]
+
+	repository := new(mocks.BookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("GetBookByID", book.ID).Return(book, nil)
+
+	w := serveBooks(repository, "/books/?format=csv&bookID="+book.ID.String(), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Len(t, readCSV(t, w.Body.String()), 2)
+}
+
+func TestGetTranslationsAsCSV(t *testing.T) {
+	original := uuid.New()
+	translations := mocks.NewMockBooks()[:2]
+
+	repository := new(mocks.BookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("GetBookByID", original).Return(models.BookOut{}, nil)
+	repository.On("GetTranslations", original).Return(translations, nil)
+
+	w := serveBooks(repository, "/books/"+original.String()+"/translations", "text/csv")
+
+	records := readCSV(t, w.Body.String())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, records, 3)
+	assert.Equal(t, translations[1].Title, records[2][1])
+}
+
+func TestGetAuthorsAsCSV(t *testing.T) {
+	nationality, birthYear := "BR", uint16(1912)
+	authors := []models.Author{
+		{ID: uuid.New(), Name: "Jorge Amado", Nationality: &nationality, BirthYear: &birthYear},
+		{ID: uuid.New(), Name: "Amado, \"Jorge\""},
+	}
+
+	repository := new(mocks.AuthorRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("WithNationality", "BR").Return(repository)
+	repository.On("Each", mock.Anything).Run(yields(authors)).Return(nil)
+
+	w := serveAuthors(repository, "/authors/?nationality=BR", "text/csv")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="authors.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, [][]string{
+		utils.AuthorExportColumns,
+		{authors[0].ID.String(), "Jorge Amado", "", "", "1912", "", "BR", "", ""},
+		{authors[1].ID.String(), "Amado, \"Jorge\"", "", "", "", "", "", "", ""},
+	}, readCSV(t, w.Body.String()))
+}
+
+func TestGetAuthorsBySimilarNameAsNDJSON(t *testing.T) {
+	matches := []models.AuthorMatch{
+		{Author: models.Author{ID: uuid.New(), Name: "João Guimarães Rosa"}, Similarity: 0.5},
+	}
+
+	repository := new(mocks.AuthorRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("GetBySimilarName", "joao", config.AuthorSimilarityThreshold).Return(matches, nil)
+
+	w := serveAuthors(repository, "/authors/?name=joao&match=fuzzy&format=ndjson", "")
+
+	expected, _ := json.Marshal(matches[0])
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, string(expected), strings.TrimSpace(w.Body.String()))
+}
+
+func TestGetAuthorsBySimilarNameAsCSVAddsSimilarity(t *testing.T) {
+	matches := []models.AuthorMatch{
+		{Author: models.Author{ID: uuid.New(), Name: "João Guimarães Rosa"}, Similarity: 0.5},
+	}
+
+	repository := new(mocks.AuthorRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("GetBySimilarName", "joao", config.AuthorSimilarityThreshold).Return(matches, nil)
+
+	records := readCSV(t, serveAuthors(repository, "/authors/?name=joao&match=fuzzy", "text/csv").Body.String())
+
+	assert.Equal(t, "similarity", records[0][len(records[0])-1])
+	assert.Equal(t, "0.5", records[1][len(records[1])-1])
+}
+
+func TestGetAuthorsWithoutAcceptAnswersJSON(t *testing.T) {
+	repository := new(mocks.AuthorRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("GetAll").Return([]models.Author{{ID: uuid.New(), Name: "Jorge Amado"}}, nil)
+
+	for _, accept := range []string{"", "*/*", "application/xml"} {
+		w := serveAuthors(repository, "/authors/", accept)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	}
+}
@@ -0,0 +1,229 @@
+package controllers_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/gql"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func graphQLRequest(body string) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	return w, c
+}
+
+func graphQLController(t *testing.T, authorRepository *mocks.AuthorRepository, bookRepository *mocks.BookRepository) *controllers.GraphQLController {
+	authorRepository.On("WithContext", mock.Anything).Return(authorRepository).Maybe()
+	bookRepository.On("WithContext", mock.Anything).Return(bookRepository).Maybe()
+
+	schema, err := gql.NewSchema(authorRepository, bookRepository)
+
+	assert.Nil(t, err)
+
+	return controllers.NewGraphQLController(schema)
+}
+
+func TestGraphQLAuthorsWithBooksLoadsBooksOnce(t *testing.T) {
+	ramalho := models.Author{ID: uuid.New(), Name: "Luciano Ramalho"}
+	slatkin := models.Author{ID: uuid.New(), Name: "Brett Slatkin"}
+	fluent, effective := uuid.New(), uuid.New()
+
+	authorRepository := new(mocks.AuthorRepository)
+	authorRepository.On("GetPage", 1, 20).Return([]models.Author{ramalho, slatkin}, int64(2), nil)
+
+	bookRepository := new(mocks.BookRepository)
+	bookRepository.On("GetBooksPageByAuthorIDs", []uuid.UUID{ramalho.ID, slatkin.ID}, 1, 20).Return(map[uuid.UUID]models.BookPage{
+		ramalho.ID: {Books: []models.BookOut{{
+			Book:         models.Book{ID: fluent, Title: "Fluent Python", Edition: 2, PublicationYear: 2022, Version: 1},
+			AuthorsName:  pq.StringArray{"Luciano Ramalho"},
+			Contributors: models.ContributorsOut{{AuthorID: ramalho.ID, Name: ramalho.Name, Role: "author"}},
+			GenresName:   pq.StringArray{"Programming"},
+		}}, Total: 1},
+		slatkin.ID: {Books: []models.BookOut{{
+			Book:         models.Book{ID: effective, Title: "Effective Python", Edition: 2, PublicationYear: 2019, Version: 1},
+			AuthorsName:  pq.StringArray{"Brett Slatkin"},
+			Contributors: models.ContributorsOut{{AuthorID: slatkin.ID, Name: slatkin.Name, Role: "author"}},
+		}}, Total: 1},
+	}, nil).Once()
+
+	controller := graphQLController(t, authorRepository, bookRepository)
+
+	w, c := graphQLRequest(`{"query": "{ authors { total items { name books { total items { id title genres } } } } }"}`)
+
+	controller.Query(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"data": {"authors": {"total": 2, "items": [
+		{"name": "Luciano Ramalho", "books": {"total": 1, "items": [{"id": "%s", "title": "Fluent Python", "genres": ["Programming"]}]}},
+		{"name": "Brett Slatkin", "books": {"total": 1, "items": [{"id": "%s", "title": "Effective Python", "genres": []}]}}
+	]}}}`, fluent, effective), w.Body.String())
+	bookRepository.AssertNumberOfCalls(t, "GetBooksPageByAuthorIDs", 1)
+	bookRepository.AssertNotCalled(t, "GetBooksByAuthorID", mock.Anything)
+}
+
+func TestGraphQLPaginatesAuthorBooks(t *testing.T) {
+	ramalho := models.Author{ID: uuid.New(), Name: "Luciano Ramalho"}
+	slatkin := models.Author{ID: uuid.New(), Name: "Brett Slatkin"}
+
+	authorRepository := new(mocks.AuthorRepository)
+	authorRepository.On("GetPage", 1, 20).Return([]models.Author{ramalho, slatkin}, int64(2), nil)
+
+	bookRepository := new(mocks.BookRepository)
+	bookRepository.On("GetBooksPageByAuthorIDs", []uuid.UUID{ramalho.ID, slatkin.ID}, 2, 1).Return(map[uuid.UUID]models.BookPage{
+		ramalho.ID: {Books: []models.BookOut{{Book: models.Book{ID: uuid.New(), Title: "Python Fluente", Edition: 1, PublicationYear: 2015, Version: 1}}}, Total: 3},
+		slatkin.ID: {Books: []models.BookOut{}, Total: 1},
+	}, nil).Once()
+
+	controller := graphQLController(t, authorRepository, bookRepository)
+
+	w, c := graphQLRequest(`{"query": "{ authors { items { name books(page: 2, pageSize: 1) { total page pageSize items { title } } } } }"}`)
+
+	controller.Query(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"authors": {"items": [
+		{"name": "Luciano Ramalho", "books": {"total": 3, "page": 2, "pageSize": 1, "items": [{"title": "Python Fluente"}]}},
+		{"name": "Brett Slatkin", "books": {"total": 1, "page": 2, "pageSize": 1, "items": []}}
+	]}}}`, w.Body.String())
+}
+
+func TestGraphQLBooksWithContributorsLoadsAuthorsOnce(t *testing.T) {
+	ramalho := models.Author{ID: uuid.New(), Name: "Luciano Ramalho"}
+	translator := models.Author{ID: uuid.New(), Name: "Lúcia Translator"}
+	original, translation := uuid.New(), uuid.New()
+	language := "pt"
+
+	bookRepository := new(mocks.BookRepository)
+	bookRepository.On("GetBooksPage", "b.language = ?", []interface{}{"pt"}, 1, 20).Return([]models.BookOut{
+		{
+			Book: models.Book{ID: translation, Title: "Python Fluente", Edition: 1, PublicationYear: 2015, Language: &language, TranslationOfID: &original, Version: 1},
+			Contributors: models.ContributorsOut{
+				{AuthorID: ramalho.ID, Name: ramalho.Name, Role: "author"},
+				{AuthorID: translator.ID, Name: translator.Name, Role: "translator"},
+			},
+		},
+	}, int64(1), nil)
+	bookRepository.On("GetBooksByIDs", []uuid.UUID{original}).Return([]models.BookOut{
+		{
+			Book:         models.Book{ID: original, Title: "Fluent Python", Edition: 1, PublicationYear: 2015, Version: 1},
+			Contributors: models.ContributorsOut{{AuthorID: ramalho.ID, Name: ramalho.Name, Role: "author"}},
+		},
+	}, nil).Once()
+
+	authorRepository := new(mocks.AuthorRepository)
+	authorRepository.On("GetByIDs", []uuid.UUID{ramalho.ID, translator.ID}).Return([]models.Author{ramalho, translator}, nil).Once()
+
+	controller := graphQLController(t, authorRepository, bookRepository)
+
+	w, c := graphQLRequest(`{
+		"query": "query($language: String) { books(language: $language) { items { title language contributors { role author { name } } translationOf { title authors { name } } } } }",
+		"variables": {"language": "PT"}
+	}`)
+
+	controller.Query(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"books": {"items": [{
+		"title": "Python Fluente",
+		"language": "pt",
+		"contributors": [
+			{"role": "author", "author": {"name": "Luciano Ramalho"}},
+			{"role": "translator", "author": {"name": "Lúcia Translator"}}
+		],
+		"translationOf": {"title": "Fluent Python", "authors": [{"name": "Luciano Ramalho"}]}
+	}]}}}`, w.Body.String())
+	authorRepository.AssertNumberOfCalls(t, "GetByIDs", 1)
+}
+
+func TestGraphQLPaginatesBooks(t *testing.T) {
+	books := []models.BookOut{}
+	for i := 3; i <= 4; i++ {
+		books = append(books, models.BookOut{Book: models.Book{ID: uuid.New(), Title: fmt.Sprintf("Book %d", i), Edition: 1, PublicationYear: 2000, Version: 1}})
+	}
+
+	bookRepository := new(mocks.BookRepository)
+	bookRepository.On("GetBooksPage", "", []interface{}(nil), 2, 2).Return(books, int64(5), nil)
+
+	controller := graphQLController(t, new(mocks.AuthorRepository), bookRepository)
+
+	w, c := graphQLRequest(`{"query": "{ books(page: 2, pageSize: 2) { total page pageSize items { title } } }"}`)
+
+	controller.Query(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"books": {"total": 5, "page": 2, "pageSize": 2, "items": [{"title": "Book 3"}, {"title": "Book 4"}]}}}`, w.Body.String())
+}
+
+func TestGraphQLAuthorNotFoundIsNull(t *testing.T) {
+	id := uuid.New()
+
+	authorRepository := new(mocks.AuthorRepository)
+	authorRepository.On("GetByID", id).Return(models.Author{}, &errors.AuthorNotFound)
+
+	controller := graphQLController(t, authorRepository, new(mocks.BookRepository))
+
+	w, c := graphQLRequest(fmt.Sprintf(`{"query": "{ author(id: \"%s\") { name } }"}`, id))
+
+	controller.Query(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"author": null}}`, w.Body.String())
+}
+
+func TestGraphQLReturnError(t *testing.T) {
+	testCases := []struct {
+		name            string
+		body            string
+		status          int
+		expectedMessage string
+	}{
+		{"missing query", `{}`, http.StatusUnprocessableEntity, `"request body invalid"`},
+		{"invalid id", `{"query": "{ book(id: \"not-an-id\") { title } }"}`, http.StatusOK, `"invalid id"`},
+		{"invalid page size", `{"query": "{ books(pageSize: 500) { total } }"}`, http.StatusOK, `"invalid argument"`},
+		{"invalid language", `{"query": "{ books(language: \"xx\") { total } }"}`, http.StatusOK, `"invalid argument"`},
+		{"repository error", `{"query": "{ authors { total } }"}`, http.StatusOK, `"unable to fetch entity"`},
+		{"invalid nested page size", `{"query": "{ author(id: \"00000000-0000-0000-0000-000000000001\") { books(pageSize: 0) { total } } }"}`, http.StatusOK, `"invalid argument"`},
+		{"too deep", `{"query": "{ book(id: \"00000000-0000-0000-0000-000000000001\") { translationOf { translationOf { translationOf { translationOf { translationOf { translationOf { translationOf { translationOf { translationOf { translationOf { title } } } } } } } } } } } }"}`, http.StatusOK, `"query is too deep"`},
+		{"too complex", `{"query": "{ authors(pageSize: 100) { items { books(pageSize: 100) { items { title } } } } }"}`, http.StatusOK, `"query is too complex"`},
+		{"too complex through variables and fragments", `{"query": "query($size: Int) { authors(pageSize: $size) { ...books } } fragment books on AuthorPage { items { books(pageSize: $size) { items { title } } } }", "variables": {"size": 100}}`, http.StatusOK, `"query is too complex"`},
+		{"unknown field", `{"query": "{ publishers { name } }"}`, http.StatusOK, `"Cannot query field \"publishers\" on type \"Query\"."`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			authorRepository := new(mocks.AuthorRepository)
+			authorRepository.On("GetPage", 1, 20).Return(nil, int64(0), &errors.AuthorGenericError).Maybe()
+			authorRepository.On("GetByID", mock.Anything).Return(models.Author{ID: uuid.New(), Name: "Luciano Ramalho"}, nil).Maybe()
+
+			bookRepository := new(mocks.BookRepository)
+			bookRepository.On("GetBooksPage", "", []interface{}(nil), 1, 20).Return([]models.BookOut{}, int64(0), nil).Maybe()
+
+			controller := graphQLController(t, authorRepository, bookRepository)
+
+			w, c := graphQLRequest(testCase.body)
+
+			controller.Query(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+			assert.Contains(t, w.Body.String(), testCase.expectedMessage)
+		})
+	}
+}
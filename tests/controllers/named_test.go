@@ -0,0 +1,270 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newPublisherRepository() *mocks.NamedRepository[models.Publisher] {
+	repository := new(mocks.NamedRepository[models.Publisher])
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	return repository
+}
+
+func namedRequest(method string, target string, body string, params ...gin.Param) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+
+	return w, c
+}
+
+func TestNamedCreateSuccess(t *testing.T) {
+	repository := newPublisherRepository()
+	id := uuid.New()
+
+	repository.On("Create", &models.Publisher{Name: "Novatec"}).Return(id, nil)
+
+	controller := controllers.NewPublisherController(repository)
+
+	w, c := namedRequest(http.MethodPost, "/publishers/", `{"name": "Novatec"}`)
+
+	controller.Create(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"id": "%s"}`, id), w.Body.String())
+}
+
+func TestNamedCreateReturnAlreadyExists(t *testing.T) {
+	repository := newPublisherRepository()
+	repository.On("Create", mock.Anything).Return(uuid.Nil, &errors.PublisherAlreadyExists)
+
+	controller := controllers.NewPublisherController(repository)
+
+	w, c := namedRequest(http.MethodPost, "/publishers/", `{"name": "Novatec"}`)
+
+	controller.Create(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"message": "publisher already exists"}`, w.Body.String())
+}
+
+func TestNamedCreateReturnInvalidRequestBody(t *testing.T) {
+	repository := newPublisherRepository()
+
+	controller := controllers.NewPublisherController(repository)
+
+	w, c := namedRequest(http.MethodPost, "/publishers/", `{"name": "N"}`)
+
+	controller.Create(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"message": "request body invalid"}`, w.Body.String())
+	repository.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestNamedGetAllSuccess(t *testing.T) {
+	publishers := []models.Publisher{{ID: uuid.New(), Name: "Novatec"}}
+	expected, _ := json.Marshal(publishers)
+
+	testCases := []struct {
+		name   string
+		url    string
+		method string
+		args   []interface{}
+	}{
+		{"All", "/publishers/", "GetAll", nil},
+		{"Name", "/publishers/?name=nova", "GetByName", []interface{}{"nova"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repository := newPublisherRepository()
+			repository.On(testCase.method, testCase.args...).Return(publishers, nil)
+
+			controller := controllers.NewPublisherController(repository)
+
+			w, c := namedRequest(http.MethodGet, testCase.url, "")
+
+			controller.GetAll(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func TestNamedGetAllReturnUnableFetchEntity(t *testing.T) {
+	repository := newPublisherRepository()
+	repository.On("GetAll").Return(nil, &errors.BookGenericError)
+
+	controller := controllers.NewPublisherController(repository)
+
+	w, c := namedRequest(http.MethodGet, "/publishers/", "")
+
+	controller.GetAll(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"message": "unable to fetch entity"}`, w.Body.String())
+}
+
+func TestNamedGetSuccess(t *testing.T) {
+	publisher := models.Publisher{ID: uuid.New(), Name: "Novatec"}
+
+	repository := newPublisherRepository()
+	repository.On("GetByID", publisher.ID).Return(publisher, nil)
+
+	controller := controllers.NewPublisherController(repository)
+
+	w, c := namedRequest(
+		http.MethodGet, "/publishers/"+publisher.ID.String(), "", gin.Param{Key: "id", Value: publisher.ID.String()},
+	)
+
+	controller.Get(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"id": "%s", "name": "Novatec"}`, publisher.ID), w.Body.String())
+}
+
+func TestNamedGetReturnNotFound(t *testing.T) {
+	id := uuid.New()
+
+	repository := newPublisherRepository()
+	repository.On("GetByID", id).Return(models.Publisher{}, &errors.PublisherNotFound)
+
+	controller := controllers.NewPublisherController(repository)
+
+	w, c := namedRequest(http.MethodGet, "/publishers/"+id.String(), "", gin.Param{Key: "id", Value: id.String()})
+
+	controller.Get(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message": "publisher not found"}`, w.Body.String())
+}
+
+func TestNamedGetReturnInvalidID(t *testing.T) {
+	repository := newPublisherRepository()
+
+	controller := controllers.NewPublisherController(repository)
+
+	w, c := namedRequest(http.MethodGet, "/publishers/abc", "", gin.Param{Key: "id", Value: "abc"})
+
+	controller.Get(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "invalid id"}`, w.Body.String())
+}
+
+func TestNamedUpdate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		code     int
+		expected string
+	}{
+		{"Success", nil, http.StatusNoContent, ""},
+		{"NotFound", &errors.PublisherNotFound, http.StatusNotFound, `{"message": "publisher not found"}`},
+		{"AlreadyExists", &errors.PublisherAlreadyExists, http.StatusConflict, `{"message": "publisher already exists"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			id := uuid.New()
+
+			repository := newPublisherRepository()
+			repository.On("Update", id, &models.Publisher{Name: "Novatec"}).Return(testCase.err)
+
+			controller := controllers.NewPublisherController(repository)
+
+			w, c := namedRequest(
+				http.MethodPut, "/publishers/"+id.String(), `{"name": "Novatec"}`, gin.Param{Key: "id", Value: id.String()},
+			)
+
+			controller.Update(c)
+
+			assert.Equal(t, testCase.code, w.Code)
+			if testCase.expected != "" {
+				assert.JSONEq(t, testCase.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNamedDelete(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"Success", nil, http.StatusNoContent},
+		{"NotFound", &errors.PublisherNotFound, http.StatusNotFound},
+		{"Generic", &errors.BookGenericError, http.StatusInternalServerError},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			id := uuid.New()
+
+			repository := newPublisherRepository()
+			repository.On("Delete", id).Return(testCase.err)
+
+			controller := controllers.NewPublisherController(repository)
+
+			w, c := namedRequest(http.MethodDelete, "/publishers/"+id.String(), "", gin.Param{Key: "id", Value: id.String()})
+
+			controller.Delete(c)
+
+			assert.Equal(t, testCase.code, w.Code)
+		})
+	}
+}
+
+func TestGenreControllerNamesGenres(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		code     int
+		expected string
+	}{
+		{"NotFound", &errors.GenreNotFound, http.StatusNotFound, `{"message": "genre not found"}`},
+		{"AlreadyExists", &errors.GenreAlreadyExists, http.StatusConflict, `{"message": "genre already exists"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			id := uuid.New()
+
+			repository := new(mocks.NamedRepository[models.Genre])
+			repository.On("WithContext", mock.Anything).Return(repository)
+			repository.On("Update", id, &models.Genre{Name: "Fantasy"}).Return(testCase.err)
+
+			controller := controllers.NewGenreController(repository)
+
+			w, c := namedRequest(
+				http.MethodPut, "/genres/"+id.String(), `{"name": "Fantasy"}`, gin.Param{Key: "id", Value: id.String()},
+			)
+
+			controller.Update(c)
+
+			assert.Equal(t, testCase.code, w.Code)
+			assert.JSONEq(t, testCase.expected, w.Body.String())
+		})
+	}
+}
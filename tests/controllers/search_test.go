@@ -0,0 +1,98 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func serveSearch(repository *mocks.SearchRepository, url string) *httptest.ResponseRecorder {
+	controller := controllers.NewSearchController(repository)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, url, nil)
+
+	controller.Search(c)
+
+	return w
+}
+
+func TestSearchSuccess(t *testing.T) {
+	mockRepository := new(mocks.SearchRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	result := models.BookSearchResult{
+		BookOut:          mocks.NewMockBookOut(),
+		Rank:             0.6,
+		TitleHighlight:   "<mark>Python</mark> Fluente",
+		AuthorsHighlight: "Luciano Ramalho",
+	}
+
+	mockRepository.On("SearchBooks", "python fluente", 2, 5).Return([]models.BookSearchResult{result}, int64(6), nil)
+
+	w := serveSearch(mockRepository, "/search?q=python+fluente&page=2&pageSize=5")
+
+	var body struct {
+		Items    []models.BookSearchResult `json:"items"`
+		Page     int                       `json:"page"`
+		PageSize int                       `json:"page_size"`
+		Total    int64                     `json:"total"`
+	}
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Items, 1)
+	assert.Equal(t, result.ID, body.Items[0].ID)
+	assert.Equal(t, "<mark>Python</mark> Fluente", body.Items[0].TitleHighlight)
+	assert.Equal(t, 2, body.Page)
+	assert.Equal(t, 5, body.PageSize)
+	assert.Equal(t, int64(6), body.Total)
+}
+
+func TestSearchDefaultPagination(t *testing.T) {
+	mockRepository := new(mocks.SearchRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+	mockRepository.On("SearchBooks", "king", 1, 20).Return([]models.BookSearchResult{}, int64(0), nil)
+
+	w := serveSearch(mockRepository, "/search?q=king")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items": [], "page": 1, "page_size": 20, "total": 0}`, w.Body.String())
+}
+
+func TestSearchReturnInvalidParam(t *testing.T) {
+	for _, url := range []string{"/search", "/search?q=", "/search?q=+++", "/search?q=king&page=0", "/search?q=king&pageSize=101"} {
+		t.Run(url, func(t *testing.T) {
+			mockRepository := new(mocks.SearchRepository)
+			mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+			w := serveSearch(mockRepository, url)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"message": "invalid query param"}`, w.Body.String())
+			mockRepository.AssertNotCalled(t, "SearchBooks", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSearchReturnGenericError(t *testing.T) {
+	mockRepository := new(mocks.SearchRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+	mockRepository.On("SearchBooks", "king", 1, 20).Return(nil, int64(0), &errors.BookGenericError)
+
+	w := serveSearch(mockRepository, "/search?q=king")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
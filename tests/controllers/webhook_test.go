@@ -0,0 +1,155 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newWebhookContext(method string, url string, body string) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(method, url, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	return w, c
+}
+
+func TestCreateWebhookAnswersItsSecret(t *testing.T) {
+	mockRepository := new(mocks.WebhookRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	id := uuid.New()
+	var created *models.Webhook
+
+	mockRepository.On("Create", mock.AnythingOfType("*models.Webhook")).
+		Run(func(args mock.Arguments) { created = args.Get(0).(*models.Webhook) }).
+		Return(id, nil)
+
+	w, c := newWebhookContext(http.MethodPost, "/webhooks/", `{"url": "https://example.com/hooks", "events": ["book.created", "book.deleted"]}`)
+
+	controllers.NewWebhookController(mockRepository).CreateWebhook(c)
+
+	var body struct {
+		ID     uuid.UUID `json:"id"`
+		Secret string    `json:"secret"`
+	}
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, id, body.ID)
+	assert.Len(t, body.Secret, 64)
+	assert.Equal(t, body.Secret, created.Secret)
+	assert.Equal(t, "https://example.com/hooks", created.URL)
+	assert.Equal(t, []string{"book.created", "book.deleted"}, []string(created.Events))
+}
+
+func TestCreateWebhookReturnInvalidRequestBody(t *testing.T) {
+	for _, body := range []string{`{}`, `{"url": "not a url"}`, `{"url": "https://example.com", "events": ["genre.created"]}`} {
+		t.Run(body, func(t *testing.T) {
+			mockRepository := new(mocks.WebhookRepository)
+			mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+			w, c := newWebhookContext(http.MethodPost, "/webhooks/", body)
+
+			controllers.NewWebhookController(mockRepository).CreateWebhook(c)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			mockRepository.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestDeleteWebhookReturnNotFound(t *testing.T) {
+	mockRepository := new(mocks.WebhookRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	id := uuid.New()
+	mockRepository.On("Delete", id).Return(&errors.WebhookNotFound)
+
+	w, c := newWebhookContext(http.MethodDelete, "/webhooks/"+id.String(), "")
+	c.Params = gin.Params{{Key: "id", Value: id.String()}}
+
+	controllers.NewWebhookController(mockRepository).DeleteWebhook(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message": "webhook not found"}`, w.Body.String())
+}
+
+func TestGetDeadDeliveries(t *testing.T) {
+	mockRepository := new(mocks.WebhookRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	reason := "unexpected status 500"
+	deliveries := []models.WebhookDelivery{
+		{ID: uuid.New(), WebhookID: uuid.New(), EventID: 7, Attempts: 8, LastError: &reason, Event: models.OutboxEvent{ID: 7, Type: models.EventBookCreated}},
+	}
+
+	mockRepository.On("GetDeliveries", models.DeliveryDead, 1, 50).Return(deliveries, int64(1), nil)
+
+	w, c := newWebhookContext(http.MethodGet, "/webhooks/deliveries?status=dead", "")
+
+	controllers.NewWebhookController(mockRepository).GetDeliveries(c)
+
+	var body struct {
+		Items []models.WebhookDelivery `json:"items"`
+		Total int64                    `json:"total"`
+	}
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int64(1), body.Total)
+	assert.Equal(t, models.EventBookCreated, body.Items[0].Event.Type)
+	assert.Equal(t, reason, *body.Items[0].LastError)
+}
+
+func TestGetDeliveriesReturnInvalidParam(t *testing.T) {
+	mockRepository := new(mocks.WebhookRepository)
+	mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+	w, c := newWebhookContext(http.MethodGet, "/webhooks/deliveries?status=failed", "")
+
+	controllers.NewWebhookController(mockRepository).GetDeliveries(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockRepository.AssertNotCalled(t, "GetDeliveries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRetryDelivery(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"dead", nil, http.StatusAccepted},
+		{"not dead", &errors.DeliveryNotFound, http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepository := new(mocks.WebhookRepository)
+			mockRepository.On("WithContext", mock.Anything).Return(mockRepository).Maybe()
+
+			id := uuid.New()
+			mockRepository.On("Retry", id).Return(tc.err)
+
+			w, c := newWebhookContext(http.MethodPost, "/webhooks/deliveries/"+id.String()+"/retry", "")
+			c.Params = gin.Params{{Key: "id", Value: id.String()}}
+
+			controllers.NewWebhookController(mockRepository).RetryDelivery(c)
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
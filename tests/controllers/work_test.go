@@ -0,0 +1,97 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/controllers"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func workRequest(id string) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/works/%s", id), nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+
+	return w, c
+}
+
+func TestGetWorkListEditions(t *testing.T) {
+	work := models.Work{ID: uuid.New(), Title: "Fluent Python"}
+	first, second := uuid.New(), uuid.New()
+
+	workRepository := new(mocks.WorkRepository)
+	workRepository.On("WithContext", mock.Anything).Return(workRepository)
+	workRepository.On("GetByID", work.ID).Return(work, nil)
+
+	bookRepository := new(mocks.BookRepository)
+	bookRepository.On("WithContext", mock.Anything).Return(bookRepository)
+	bookRepository.On("GetBooksByWorkID", work.ID).Return([]models.BookOut{
+		{Book: models.Book{ID: first, Title: "Fluent Python", Edition: 1, PublicationYear: 2015, WorkID: &work.ID, Version: 1}, AuthorsName: pq.StringArray{"Luciano Ramalho"}},
+		{Book: models.Book{ID: second, Title: "Fluent Python", Edition: 2, PublicationYear: 2022, WorkID: &work.ID, Version: 1}, AuthorsName: pq.StringArray{"Luciano Ramalho"}},
+	}, nil)
+
+	controller := controllers.NewWorkController(workRepository, bookRepository)
+
+	w, c := workRequest(work.ID.String())
+
+	controller.GetWork(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"id": "%[1]s",
+		"title": "Fluent Python",
+		"editions": [
+			{"id": "%[2]s", "title": "Fluent Python", "edition": 1, "publication_year": 2015, "work_id": "%[1]s", "version": 1, "deleted_at": null, "authors": ["Luciano Ramalho"], "contributors": null, "genres": null},
+			{"id": "%[3]s", "title": "Fluent Python", "edition": 2, "publication_year": 2022, "work_id": "%[1]s", "version": 1, "deleted_at": null, "authors": ["Luciano Ramalho"], "contributors": null, "genres": null}
+		]
+	}`, work.ID, first, second), w.Body.String())
+}
+
+func TestGetWorkReturnError(t *testing.T) {
+	testCases := []struct {
+		name            string
+		id              string
+		workError       error
+		editionsError   error
+		status          int
+		expectedMessage string
+	}{
+		{"invalid id", "not-an-id", nil, nil, http.StatusBadRequest, `{"message": "invalid id"}`},
+		{"not found", uuid.New().String(), &errors.WorkNotFound, nil, http.StatusNotFound, `{"message": "work not found"}`},
+		{"editions error", uuid.New().String(), nil, &errors.BookGenericError, http.StatusInternalServerError, `{"message": "unable to fetch entity"}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			workRepository := new(mocks.WorkRepository)
+			workRepository.On("WithContext", mock.Anything).Return(workRepository).Maybe()
+			workRepository.On("GetByID", mock.Anything).Return(models.Work{}, testCase.workError).Maybe()
+
+			bookRepository := new(mocks.BookRepository)
+			bookRepository.On("WithContext", mock.Anything).Return(bookRepository).Maybe()
+			bookRepository.On("GetBooksByWorkID", mock.Anything).Return(nil, testCase.editionsError).Maybe()
+
+			controller := controllers.NewWorkController(workRepository, bookRepository)
+
+			w, c := workRequest(testCase.id)
+
+			controller.GetWork(c)
+
+			assert.Equal(t, testCase.status, w.Code)
+			assert.JSONEq(t, testCase.expectedMessage, w.Body.String())
+		})
+	}
+}
@@ -2,6 +2,7 @@ package db_test
 
 import (
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -11,6 +12,37 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// createBooksTable matches the books CREATE TABLE, gorm emits its foreign keys
+// in any order
+var createBooksTable = func() string {
+	publisher := `CONSTRAINT "fk_books_publisher" FOREIGN KEY ("publisher_id") REFERENCES "publishers"("id") ON DELETE SET NULL ON UPDATE CASCADE`
+	work := `CONSTRAINT "fk_books_work" FOREIGN KEY ("work_id") REFERENCES "works"("id") ON DELETE SET NULL ON UPDATE CASCADE`
+	translation := `CONSTRAINT "fk_books_translation_of" FOREIGN KEY ("translation_of_id") REFERENCES "books"("id") ON DELETE SET NULL ON UPDATE CASCADE`
+
+	orders := []string{}
+	for _, keys := range [][]string{
+		{publisher, work, translation}, {publisher, translation, work},
+		{work, publisher, translation}, {work, translation, publisher},
+		{translation, publisher, work}, {translation, work, publisher},
+	} {
+		orders = append(orders, regexp.QuoteMeta(strings.Join(keys, ",")))
+	}
+
+	return regexp.QuoteMeta(`CREATE TABLE "books" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,"edition" smallint,"publication_year" smallint,"isbn" varchar(13),"publisher_id" uuid,"work_id" uuid,"language" varchar(2),"translation_of_id" uuid,"version" integer NOT NULL DEFAULT 1,"deleted_at" timestamptz,PRIMARY KEY ("id"),`) +
+		"(" + strings.Join(orders, "|") + ")" +
+		`\)`
+}()
+
+// authorsIndexes and booksIndexes match the indexes of their table, which
+// gorm creates in any order. Names and ISBNs are only unique among the rows
+// that are not deleted.
+var (
+	authorsIndexes = "(" + regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS "idx_authors_deleted_at" ON "authors" ("deleted_at")`) +
+		"|" + regexp.QuoteMeta(`CREATE UNIQUE INDEX IF NOT EXISTS "idx_authors_name" ON "authors" ("name") WHERE deleted_at IS NULL`) + ")"
+	booksIndexes = "(" + regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS "idx_books_deleted_at" ON "books" ("deleted_at")`) +
+		"|" + regexp.QuoteMeta(`CREATE UNIQUE INDEX IF NOT EXISTS "idx_books_isbn" ON "books" ("isbn") WHERE deleted_at IS NULL`) + ")"
+)
+
 func TestCreateAllTablesSuccess(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
@@ -21,7 +53,26 @@ func TestCreateAllTablesSuccess(t *testing.T) {
 
 	// Mock CREATE TABLE for "authors"
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_authors_name" UNIQUE ("name"))`,
+		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,"sort_name" varchar(255),"biography" text,"birth_year" smallint,"death_year" smallint,"nationality" char(2),"website" varchar(255),"deleted_at" timestamptz,PRIMARY KEY ("id"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("publishers", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "publishers" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_publishers_name" UNIQUE ("name"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("works", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "works" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,PRIMARY KEY ("id"))`,
 	)).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Mock SELECT for "books" table existence check
@@ -30,9 +81,10 @@ func TestCreateAllTablesSuccess(t *testing.T) {
 	)).WithArgs("books", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
 	// Mock CREATE TABLE for "books"
-	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "books" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,"edition" smallint,"publication_year" smallint,PRIMARY KEY ("id"))`,
-	)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(createBooksTable).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(booksIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(booksIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
 
 	// Mock SELECT for "book_author" table existence check
 	mock.ExpectQuery(regexp.QuoteMeta(
@@ -41,9 +93,134 @@ func TestCreateAllTablesSuccess(t *testing.T) {
 
 	// Mock CREATE TABLE for "book_author"
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "book_author" ("book_id" uuid,"author_id" uuid,PRIMARY KEY ("book_id","author_id"),CONSTRAINT "fk_book_author_book" FOREIGN KEY ("book_id") REFERENCES "books"("id") ON DELETE CASCADE ON UPDATE CASCADE,CONSTRAINT "fk_book_author_author" FOREIGN KEY ("author_id") REFERENCES "authors"("id") ON DELETE CASCADE ON UPDATE CASCADE)`,
+		`CREATE TABLE "book_author" ("book_id" uuid,"author_id" uuid,"role" varchar(16) NOT NULL DEFAULT 'author',"position" smallint NOT NULL DEFAULT 0,PRIMARY KEY ("book_id","author_id"),CONSTRAINT "fk_book_author_book" FOREIGN KEY ("book_id") REFERENCES "books"("id") ON DELETE CASCADE ON UPDATE CASCADE,CONSTRAINT "fk_book_author_author" FOREIGN KEY ("author_id") REFERENCES "authors"("id") ON DELETE CASCADE ON UPDATE CASCADE)`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Mock SELECT for "genres" table existence check
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("genres", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// Mock CREATE TABLE for "genres"
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "genres" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_genres_name" UNIQUE ("name"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Mock SELECT for "book_genre" table existence check
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("book_genre", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// Mock CREATE TABLE for "book_genre", gorm emits its two foreign keys in
+	// either order
+	bookGenreBook := `CONSTRAINT "fk_book_genre_book" FOREIGN KEY ("book_id") REFERENCES "books"("id") ON DELETE CASCADE ON UPDATE CASCADE`
+	bookGenreGenre := `CONSTRAINT "fk_book_genre_genre" FOREIGN KEY ("genre_id") REFERENCES "genres"("id") ON DELETE CASCADE ON UPDATE CASCADE`
+	mock.ExpectExec(
+		regexp.QuoteMeta(`CREATE TABLE "book_genre" ("book_id" uuid,"genre_id" uuid,PRIMARY KEY ("book_id","genre_id"),`) +
+			"(" + regexp.QuoteMeta(bookGenreBook+","+bookGenreGenre) + "|" + regexp.QuoteMeta(bookGenreGenre+","+bookGenreBook) + `)\)`,
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Mock SELECT for "api_keys" table existence check
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("api_keys", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// Mock CREATE TABLE for "api_keys"
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "api_keys" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,"prefix" varchar(16) NOT NULL,"hash" char(64) NOT NULL,"role" varchar(16) NOT NULL DEFAULT 'reader',"created_at" timestamptz,"revoked_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "uni_api_keys_hash" UNIQUE ("hash"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Mock SELECT for "audit_log" table existence check
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("audit_log", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// Mock CREATE TABLE for "audit_log"
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "audit_log" ("id" uuid DEFAULT gen_random_uuid(),"entity" varchar(32) NOT NULL,"entity_id" uuid NOT NULL,"action" varchar(16) NOT NULL,"actor" varchar(255) NOT NULL,"before" jsonb,"after" jsonb,"request_id" varchar(64),"created_at" timestamptz,PRIMARY KEY ("id"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE INDEX IF NOT EXISTS "idx_audit_log_entity" ON "audit_log" ("entity","entity_id","created_at")`,
+	)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Mock SELECT for "outbox" table existence check
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("outbox", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// Mock CREATE TABLE for "outbox"
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "outbox" ("id" bigserial,"type" varchar(32) NOT NULL,"entity_id" uuid NOT NULL,"payload" jsonb NOT NULL,"created_at" timestamptz,"dispatched_at" timestamptz,PRIMARY KEY ("id"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE INDEX IF NOT EXISTS "idx_outbox_dispatched_at" ON "outbox" ("dispatched_at")`,
+	)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Mock SELECT for "webhooks" table existence check
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("webhooks", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// Mock CREATE TABLE for "webhooks"
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "webhooks" ("id" uuid DEFAULT gen_random_uuid(),"url" varchar(2048) NOT NULL,"secret" varchar(64) NOT NULL,"events" text[],"created_at" timestamptz,PRIMARY KEY ("id"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Mock SELECT for "webhook_deliveries" table existence check
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("webhook_deliveries", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// Mock CREATE TABLE for "webhook_deliveries", gorm emits its foreign keys
+	// and indexes in any order
+	deliveryWebhook := `CONSTRAINT "fk_webhook_deliveries_webhook" FOREIGN KEY ("webhook_id") REFERENCES "webhooks"("id") ON DELETE CASCADE ON UPDATE CASCADE`
+	deliveryEvent := `CONSTRAINT "fk_webhook_deliveries_event" FOREIGN KEY ("event_id") REFERENCES "outbox"("id") ON DELETE CASCADE ON UPDATE CASCADE`
+
+	mock.ExpectExec(
+		regexp.QuoteMeta(`CREATE TABLE "webhook_deliveries" ("id" uuid DEFAULT gen_random_uuid(),"webhook_id" uuid NOT NULL,"event_id" bigint NOT NULL,"attempts" smallint NOT NULL DEFAULT 0,"next_attempt_at" timestamptz NOT NULL,"last_status" smallint,"last_error" text,"delivered_at" timestamptz,"dead_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"),`) +
+			"(" + regexp.QuoteMeta(deliveryWebhook+","+deliveryEvent) + "|" + regexp.QuoteMeta(deliveryEvent+","+deliveryWebhook) + `)\)`,
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	deliveryIndexes := "(" + regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_next_attempt_at" ON "webhook_deliveries" ("next_attempt_at")`) +
+		"|" + regexp.QuoteMeta(`CREATE UNIQUE INDEX IF NOT EXISTS "idx_webhook_deliveries_event" ON "webhook_deliveries" ("webhook_id","event_id")`) + ")"
+
+	mock.ExpectExec(deliveryIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(deliveryIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Mock SELECT for "idempotency_keys" table existence check
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("idempotency_keys", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// Mock CREATE TABLE for "idempotency_keys"
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "idempotency_keys" ("client" char(64),"key" varchar(255),"request_hash" char(64) NOT NULL,"status_code" smallint NOT NULL DEFAULT 0,"content_type" varchar(255),"body" bytea,"created_at" timestamptz,"expires_at" timestamptz NOT NULL,PRIMARY KEY ("client","key"))`,
 	)).WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at")`,
+	)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Mock full-text search columns and indexes
+	for _, statement := range []string{
+		`ALTER TABLE "books" ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce("title", '')), 'A') || setweight(to_tsvector('portuguese', coalesce("title", '')), 'A')) STORED`,
+		`CREATE INDEX IF NOT EXISTS "idx_books_search_vector" ON "books" USING gin ("search_vector")`,
+		`ALTER TABLE "authors" ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce("name", '')), 'B') || setweight(to_tsvector('portuguese', coalesce("name", '')), 'B')) STORED`,
+		`CREATE INDEX IF NOT EXISTS "idx_authors_search_vector" ON "authors" USING gin ("search_vector")`,
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE EXTENSION IF NOT EXISTS unaccent`,
+		`CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT`,
+		`CREATE INDEX IF NOT EXISTS "idx_authors_name_trgm" ON "authors" USING gin (immutable_unaccent(lower("name")) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS "idx_publishers_name_trgm" ON "publishers" USING gin (immutable_unaccent(lower("name")) gin_trgm_ops)`,
+		// Mock moving existing books into works
+		`INSERT INTO "works" ("title") SELECT DISTINCT b."title" FROM "books" b WHERE b."work_id" IS NULL AND NOT EXISTS (SELECT 1 FROM "works" w WHERE w."title" = b."title")`,
+		`UPDATE "books" b SET "work_id" = (SELECT w."id" FROM "works" w WHERE w."title" = b."title" ORDER BY w."id" LIMIT 1) WHERE b."work_id" IS NULL`,
+	} {
+		mock.ExpectExec(regexp.QuoteMeta(statement)).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
 	err := db.CreateTables(gormDB)
 
 	assert.Nil(t, err)
@@ -73,7 +250,7 @@ func TestCreateAllTablesReturnGenericErrorWhenCreateAuthors(t *testing.T) {
 
 	// Mock CREATE TABLE for "authors"
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_authors_name" UNIQUE ("name"))`,
+		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,"sort_name" varchar(255),"biography" text,"birth_year" smallint,"death_year" smallint,"nationality" char(2),"website" varchar(255),"deleted_at" timestamptz,PRIMARY KEY ("id"))`,
 	)).WillReturnError(&errors.AuthorGenericError)
 
 	err := db.CreateTables(gormDB)
@@ -90,7 +267,26 @@ func TestCreateAllTablesReturnErrorWhenCreateBooksTableAlreadyExists(t *testing.
 
 	// Mock CREATE TABLE for "authors"
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_authors_name" UNIQUE ("name"))`,
+		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,"sort_name" varchar(255),"biography" text,"birth_year" smallint,"death_year" smallint,"nationality" char(2),"website" varchar(255),"deleted_at" timestamptz,PRIMARY KEY ("id"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("publishers", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "publishers" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_publishers_name" UNIQUE ("name"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("works", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "works" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,PRIMARY KEY ("id"))`,
 	)).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	mock.ExpectQuery(regexp.QuoteMeta(
@@ -112,16 +308,33 @@ func TestCreateAllTablesReturnErrorGenericWhenCreateBooksTable(t *testing.T) {
 
 	// Mock CREATE TABLE for "authors"
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_authors_name" UNIQUE ("name"))`,
+		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,"sort_name" varchar(255),"biography" text,"birth_year" smallint,"death_year" smallint,"nationality" char(2),"website" varchar(255),"deleted_at" timestamptz,PRIMARY KEY ("id"))`,
 	)).WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+
 	mock.ExpectQuery(regexp.QuoteMeta(
 		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
-	)).WithArgs("books", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	)).WithArgs("publishers", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "books" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,"edition" smallint,"publication_year" smallint,PRIMARY KEY ("id"))`,
-	)).WillReturnError(&errors.BookGenericError)
+		`CREATE TABLE "publishers" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_publishers_name" UNIQUE ("name"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("works", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "works" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,PRIMARY KEY ("id"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("books", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(createBooksTable).WillReturnError(&errors.BookGenericError)
 
 	err := db.CreateTables(gormDB)
 
@@ -140,17 +353,37 @@ func TestCreateAllTablesReturnErrorWhenCreateBookAuthorsTableAlreadyExists(t *te
 
 	// Mock CREATE TABLE for "authors"
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_authors_name" UNIQUE ("name"))`,
+		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,"sort_name" varchar(255),"biography" text,"birth_year" smallint,"death_year" smallint,"nationality" char(2),"website" varchar(255),"deleted_at" timestamptz,PRIMARY KEY ("id"))`,
 	)).WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+
 	mock.ExpectQuery(regexp.QuoteMeta(
 		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
-	)).WithArgs("books", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	)).WithArgs("publishers", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "publishers" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_publishers_name" UNIQUE ("name"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("works", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "books" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,"edition" smallint,"publication_year" smallint,PRIMARY KEY ("id"))`,
+		`CREATE TABLE "works" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,PRIMARY KEY ("id"))`,
 	)).WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("books", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(createBooksTable).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(booksIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(booksIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+
 	mock.ExpectQuery(regexp.QuoteMeta(
 		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
 	)).WithArgs("book_author", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
@@ -170,24 +403,44 @@ func TestCreateAllTablesReturnErrorGenericWhenCreateBookAuthorsTable(t *testing.
 
 	// Mock CREATE TABLE for "authors"
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_authors_name" UNIQUE ("name"))`,
+		`CREATE TABLE "authors" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,"sort_name" varchar(255),"biography" text,"birth_year" smallint,"death_year" smallint,"nationality" char(2),"website" varchar(255),"deleted_at" timestamptz,PRIMARY KEY ("id"))`,
 	)).WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(authorsIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+
 	mock.ExpectQuery(regexp.QuoteMeta(
 		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
-	)).WithArgs("books", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	)).WithArgs("publishers", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "books" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,"edition" smallint,"publication_year" smallint,PRIMARY KEY ("id"))`,
+		`CREATE TABLE "publishers" ("id" uuid DEFAULT gen_random_uuid(),"name" varchar(255) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "uni_publishers_name" UNIQUE ("name"))`,
 	)).WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("works", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE TABLE "works" ("id" uuid DEFAULT gen_random_uuid(),"title" varchar(255) NOT NULL,PRIMARY KEY ("id"))`,
+	)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
+	)).WithArgs("books", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectExec(createBooksTable).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(booksIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(booksIndexes).WillReturnResult(sqlmock.NewResult(0, 0))
+
 	mock.ExpectQuery(regexp.QuoteMeta(
 		`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`,
 	)).WithArgs("book_author", "BASE TABLE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
 	// Mock CREATE TABLE for "book_author"
 	mock.ExpectExec(regexp.QuoteMeta(
-		`CREATE TABLE "book_author" ("book_id" uuid,"author_id" uuid,PRIMARY KEY ("book_id","author_id"),CONSTRAINT "fk_book_author_book" FOREIGN KEY ("book_id") REFERENCES "books"("id") ON DELETE CASCADE ON UPDATE CASCADE,CONSTRAINT "fk_book_author_author" FOREIGN KEY ("author_id") REFERENCES "authors"("id") ON DELETE CASCADE ON UPDATE CASCADE)`,
+		`CREATE TABLE "book_author" ("book_id" uuid,"author_id" uuid,"role" varchar(16) NOT NULL DEFAULT 'author',"position" smallint NOT NULL DEFAULT 0,PRIMARY KEY ("book_id","author_id"),CONSTRAINT "fk_book_author_book" FOREIGN KEY ("book_id") REFERENCES "books"("id") ON DELETE CASCADE ON UPDATE CASCADE,CONSTRAINT "fk_book_author_author" FOREIGN KEY ("author_id") REFERENCES "authors"("id") ON DELETE CASCADE ON UPDATE CASCADE)`,
 	)).WillReturnError(&errors.BookAuthorGenericError)
 
 	err := db.CreateTables(gormDB)
@@ -0,0 +1,145 @@
+package events_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/events"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const interval = 5 * time.Millisecond
+
+type clock struct {
+	mu      sync.Mutex
+	current time.Time
+}
+
+func (clock *clock) now() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return clock.current
+}
+
+func (clock *clock) advance(by time.Duration) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	clock.current = clock.current.Add(by)
+}
+
+func newEvents(ids ...uint64) []models.OutboxEvent {
+	events := []models.OutboxEvent{}
+
+	for _, id := range ids {
+		events = append(events, models.OutboxEvent{ID: id, Type: models.EventBookUpdated})
+	}
+
+	return events
+}
+
+func newRepository(last uint64) *mocks.EventRepository {
+	repository := new(mocks.EventRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("LastID").Return(last, nil)
+
+	return repository
+}
+
+func receive(t *testing.T, subscription *events.Subscription, count int) []uint64 {
+	var ids []uint64
+
+	for range count {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, event.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("received %v, waiting for %d events", ids, count)
+		}
+	}
+
+	return ids
+}
+
+func TestSubscribersReceiveCommittedEventsInOrder(t *testing.T) {
+	repository := newRepository(4)
+	repository.On("GetAfter", uint64(4), uint64(0), []string(nil), 500).Return(newEvents(5, 6), nil).Once()
+	repository.On("GetAfter", uint64(6), uint64(0), []string(nil), 500).Return(newEvents(), nil)
+
+	broker := events.NewBroker(repository, interval, nil)
+
+	first, err := broker.Subscribe(context.Background())
+	assert.NoError(t, err)
+	defer first.Close()
+
+	second, err := broker.Subscribe(context.Background())
+	assert.NoError(t, err)
+	defer second.Close()
+
+	assert.Equal(t, uint64(4), first.From)
+	assert.Equal(t, []uint64{5, 6}, receive(t, first, 2))
+	assert.Equal(t, []uint64{5, 6}, receive(t, second, 2))
+	repository.AssertNumberOfCalls(t, "LastID", 1)
+}
+
+func TestEventsAfterAGapWaitForIt(t *testing.T) {
+	now := &clock{current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	repository := newRepository(1)
+	// 2 is written but not committed yet
+	repository.On("GetAfter", uint64(1), uint64(0), []string(nil), 500).Return(newEvents(3), nil).Times(3)
+	repository.On("GetAfter", uint64(1), uint64(0), []string(nil), 500).Return(newEvents(2, 3, 4), nil).Once()
+	repository.On("GetAfter", uint64(4), uint64(0), []string(nil), 500).Return(newEvents(), nil)
+
+	subscription, _ := events.NewBroker(repository, interval, now.now).Subscribe(context.Background())
+	defer subscription.Close()
+
+	assert.Equal(t, []uint64{2, 3, 4}, receive(t, subscription, 3))
+}
+
+func TestEventsAfterAGapAreSentOnceItTimesOut(t *testing.T) {
+	now := &clock{current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	repository := newRepository(1)
+	// 2 was rolled back and never shows
+	repository.On("GetAfter", uint64(1), uint64(0), []string(nil), 500).Return(newEvents(3), nil).Run(func(mock.Arguments) {
+		now.advance(time.Second)
+	})
+	repository.On("GetAfter", uint64(3), uint64(0), []string(nil), 500).Return(newEvents(), nil)
+
+	subscription, _ := events.NewBroker(repository, interval, now.now).Subscribe(context.Background())
+	defer subscription.Close()
+
+	assert.Equal(t, []uint64{3}, receive(t, subscription, 1))
+	assert.False(t, now.now().Before(time.Date(2024, 1, 1, 0, 0, 5, 0, time.UTC)))
+}
+
+func TestSlowSubscribersAreDropped(t *testing.T) {
+	ids := make([]uint64, 100)
+	for i := range ids {
+		ids[i] = uint64(i + 1)
+	}
+
+	repository := newRepository(0)
+	repository.On("GetAfter", uint64(0), uint64(0), []string(nil), 500).Return(newEvents(ids...), nil).Once()
+	repository.On("GetAfter", uint64(100), uint64(0), []string(nil), 500).Return(newEvents(), nil)
+
+	subscription, _ := events.NewBroker(repository, interval, nil).Subscribe(context.Background())
+	defer subscription.Close()
+
+	received := receive(t, subscription, 100)
+
+	assert.Less(t, len(received), 100)
+
+	_, open := <-subscription.Events
+	assert.False(t, open)
+}
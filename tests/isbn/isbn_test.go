@@ -0,0 +1,71 @@
+package isbn_test
+
+import (
+	"testing"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/isbn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeValidISBN(t *testing.T) {
+	cases := map[string]string{
+		"9780134190440":     "9780134190440",
+		"978-0-13-419044-0": "9780134190440",
+		"0-306-40615-2":     "9780306406157",
+		"0306406152":        "9780306406157",
+		"080442957X":        "9780804429573",
+		"0 8044 2957 x":     "9780804429573",
+	}
+
+	for raw, expected := range cases {
+		normalized, ok := isbn.Normalize(raw)
+
+		assert.True(t, ok, raw)
+		assert.Equal(t, expected, normalized, raw)
+		assert.True(t, isbn.Valid(raw), raw)
+	}
+}
+
+func TestNormalizeInvalidISBN(t *testing.T) {
+	cases := []string{
+		"",
+		"0-306-40615-3",
+		"9780306406158",
+		"X306406152",
+		"978030640615X",
+		"97803064061",
+		"not an isbn",
+	}
+
+	for _, raw := range cases {
+		normalized, ok := isbn.Normalize(raw)
+
+		assert.False(t, ok, raw)
+		assert.Empty(t, normalized, raw)
+		assert.False(t, isbn.Valid(raw), raw)
+	}
+}
+
+func TestTo10(t *testing.T) {
+	cases := map[string]string{
+		"9780306406157": "0306406152",
+		"9780804429573": "080442957X",
+		"9781491946008": "1491946008",
+	}
+
+	for isbn13, expected := range cases {
+		isbn10, ok := isbn.To10(isbn13)
+
+		assert.True(t, ok, isbn13)
+		assert.Equal(t, expected, isbn10, isbn13)
+	}
+}
+
+func TestTo10WithoutISBN10(t *testing.T) {
+	for _, isbn13 := range []string{"9791032305690", "9780306406158", "0306406152", ""} {
+		isbn10, ok := isbn.To10(isbn13)
+
+		assert.False(t, ok, isbn13)
+		assert.Empty(t, isbn10, isbn13)
+	}
+}
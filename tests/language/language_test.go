@@ -0,0 +1,34 @@
+package language_test
+
+import (
+	"testing"
+
+	"github.com/joaooliveira247/go_olist_challenge/src/language"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeValidLanguage(t *testing.T) {
+	cases := map[string]string{
+		"pt":   "pt",
+		"EN":   "en",
+		" Es ": "es",
+		"zu":   "zu",
+	}
+
+	for raw, expected := range cases {
+		normalized, ok := language.Normalize(raw)
+
+		assert.True(t, ok, raw)
+		assert.Equal(t, expected, normalized, raw)
+		assert.True(t, language.Valid(raw), raw)
+	}
+}
+
+func TestNormalizeInvalidLanguage(t *testing.T) {
+	for _, raw := range []string{"", "p", "por", "pt-BR", "xx", "'; --"} {
+		_, ok := language.Normalize(raw)
+
+		assert.False(t, ok, raw)
+		assert.False(t, language.Valid(raw), raw)
+	}
+}
@@ -0,0 +1,323 @@
+package middlewares_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var hmacSecret = []byte("a-very-secret-secret")
+
+func enableAuth(t *testing.T, publicReads bool) {
+	config.AuthEnabled = true
+	config.AuthPublicReads = publicReads
+
+	t.Cleanup(func() {
+		config.AuthEnabled = false
+		config.AuthPublicReads = true
+	})
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.RegisteredClaims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	assert.NoError(t, err)
+	return token
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "librarian@olist.com",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func serve(handler gin.HandlerFunc, method string, headers map[string]string) (*httptest.ResponseRecorder, *auth.Principal) {
+	gin.SetMode(gin.TestMode)
+
+	var principal *auth.Principal
+
+	eng := gin.New()
+	eng.Handle(method, "/books/", handler, func(ctx *gin.Context) {
+		if value, ok := ctx.Get(auth.PrincipalKey); ok {
+			p := value.(auth.Principal)
+			principal = &p
+		}
+		ctx.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, "/books/", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	eng.ServeHTTP(w, req)
+
+	return w, principal
+}
+
+func newAPIKeyRepository() *mocks.APIKeyRepository {
+	repository := new(mocks.APIKeyRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	return repository
+}
+
+func TestAuthenticateDisabledAllowsEveryRequest(t *testing.T) {
+	repository := newAPIKeyRepository()
+	verifier := auth.NewJWTVerifier(hmacSecret, nil, "", "")
+
+	w, principal := serve(middlewares.Authenticate(repository, verifier), http.MethodPost, map[string]string{})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, principal)
+}
+
+func TestAuthenticateReturnUnauthorizedWithoutCredentials(t *testing.T) {
+	enableAuth(t, true)
+
+	repository := newAPIKeyRepository()
+	verifier := auth.NewJWTVerifier(hmacSecret, nil, "", "")
+
+	w, _ := serve(middlewares.Authenticate(repository, verifier), http.MethodPost, map[string]string{})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"message": "unauthorized"}`, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestAuthenticateWithAPIKeySuccess(t *testing.T) {
+	enableAuth(t, true)
+
+	key, err := auth.GenerateAPIKey()
+	assert.NoError(t, err)
+
+	apiKey := models.APIKey{ID: uuid.New(), Name: "ci", Role: "librarian"}
+
+	repository := newAPIKeyRepository()
+	repository.On("GetActiveByHash", auth.HashAPIKey(key)).Return(apiKey, nil)
+
+	w, principal := serve(
+		middlewares.Authenticate(repository, auth.NewJWTVerifier(nil, nil, "", "")),
+		http.MethodDelete,
+		map[string]string{auth.APIKeyHeader: key},
+	)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &auth.Principal{Subject: apiKey.ID.String(), Method: auth.MethodAPIKey, Role: auth.RoleLibrarian}, principal)
+}
+
+func TestAuthenticateSetsAuditActor(t *testing.T) {
+	enableAuth(t, true)
+	gin.SetMode(gin.TestMode)
+
+	token := signToken(t, jwt.SigningMethodHS256, hmacSecret, validClaims())
+
+	var actor string
+
+	eng := gin.New()
+	eng.POST("/books/", middlewares.Authenticate(newAPIKeyRepository(), auth.NewJWTVerifier(hmacSecret, nil, "", "")), func(ctx *gin.Context) {
+		actor = audit.Actor(ctx.Request.Context())
+		ctx.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/books/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	eng.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "jwt:librarian@olist.com", actor)
+}
+
+func TestAuthenticateReturnUnauthorizedWithUnknownAPIKey(t *testing.T) {
+	enableAuth(t, true)
+
+	repository := newAPIKeyRepository()
+	repository.On("GetActiveByHash", auth.HashAPIKey("olk_unknown")).Return(models.APIKey{}, &errors.APIKeyNotFound)
+
+	w, _ := serve(
+		middlewares.Authenticate(repository, auth.NewJWTVerifier(nil, nil, "", "")),
+		http.MethodPost,
+		map[string]string{auth.APIKeyHeader: "olk_unknown"},
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthenticateReturnErrorWhenAPIKeyLookupFails(t *testing.T) {
+	enableAuth(t, true)
+
+	repository := newAPIKeyRepository()
+	repository.On("GetActiveByHash", mock.Anything).Return(models.APIKey{}, &errors.BookGenericError)
+
+	w, _ := serve(
+		middlewares.Authenticate(repository, auth.NewJWTVerifier(nil, nil, "", "")),
+		http.MethodPost,
+		map[string]string{auth.APIKeyHeader: "olk_key"},
+	)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthenticateWithHS256TokenSuccess(t *testing.T) {
+	enableAuth(t, true)
+
+	token := signToken(t, jwt.SigningMethodHS256, hmacSecret, validClaims())
+
+	w, principal := serve(
+		middlewares.Authenticate(newAPIKeyRepository(), auth.NewJWTVerifier(hmacSecret, nil, "", "")),
+		http.MethodPut,
+		map[string]string{"Authorization": "Bearer " + token},
+	)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &auth.Principal{Subject: "librarian@olist.com", Method: auth.MethodJWT, Role: auth.RoleReader}, principal)
+}
+
+func TestAuthenticateWithRoleClaim(t *testing.T) {
+	enableAuth(t, true)
+
+	testCases := []struct {
+		name         string
+		role         string
+		expectedCode int
+		expectedRole auth.Role
+	}{
+		{"Librarian role", "librarian", http.StatusOK, auth.RoleLibrarian},
+		{"Admin role uppercase", "ADMIN", http.StatusOK, auth.RoleAdmin},
+		{"Unknown role", "superuser", http.StatusUnauthorized, ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"sub":  "someone",
+				"exp":  time.Now().Add(time.Hour).Unix(),
+				"role": testCase.role,
+			}).SignedString(hmacSecret)
+			assert.NoError(t, err)
+
+			w, principal := serve(
+				middlewares.Authenticate(newAPIKeyRepository(), auth.NewJWTVerifier(hmacSecret, nil, "", "")),
+				http.MethodPost,
+				map[string]string{"Authorization": "Bearer " + token},
+			)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+
+			if testCase.expectedRole != "" {
+				assert.Equal(t, testCase.expectedRole, principal.Role)
+			}
+		})
+	}
+}
+
+func TestAuthenticateWithRS256TokenSuccess(t *testing.T) {
+	enableAuth(t, true)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	token := signToken(t, jwt.SigningMethodRS256, privateKey, validClaims())
+
+	w, principal := serve(
+		middlewares.Authenticate(newAPIKeyRepository(), auth.NewJWTVerifier(nil, &privateKey.PublicKey, "", "")),
+		http.MethodPost,
+		map[string]string{"Authorization": "Bearer " + token},
+	)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "librarian@olist.com", principal.Subject)
+}
+
+func TestAuthenticateReturnUnauthorizedWithInvalidTokens(t *testing.T) {
+	enableAuth(t, true)
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "someone-else"
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	testCases := []struct {
+		name   string
+		header string
+	}{
+		{"Expired token", "Bearer " + signToken(t, jwt.SigningMethodHS256, hmacSecret, expired)},
+		{"Wrong secret", "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("other-secret"), validClaims())},
+		{"Wrong issuer", "Bearer " + signToken(t, jwt.SigningMethodHS256, hmacSecret, wrongIssuer)},
+		{"Algorithm not configured", "Bearer " + signToken(t, jwt.SigningMethodRS256, otherKey, validClaims())},
+		{"Missing expiration", "Bearer " + signToken(t, jwt.SigningMethodHS256, hmacSecret, jwt.RegisteredClaims{Subject: "x"})},
+		{"Not a bearer token", "Basic dXNlcjpwYXNzd2Q="},
+		{"Malformed token", "Bearer abc.def"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			w, principal := serve(
+				middlewares.Authenticate(newAPIKeyRepository(), auth.NewJWTVerifier(hmacSecret, nil, "olist", "")),
+				http.MethodPost,
+				map[string]string{"Authorization": testCase.header},
+			)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Nil(t, principal)
+		})
+	}
+}
+
+func TestAuthenticateReadsPublicAllowsAnonymous(t *testing.T) {
+	enableAuth(t, true)
+
+	w, principal := serve(
+		middlewares.AuthenticateReads(newAPIKeyRepository(), auth.NewJWTVerifier(hmacSecret, nil, "", "")),
+		http.MethodGet,
+		map[string]string{},
+	)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, principal)
+}
+
+func TestAuthenticateReadsPublicStillRejectsInvalidCredentials(t *testing.T) {
+	enableAuth(t, true)
+
+	w, _ := serve(
+		middlewares.AuthenticateReads(newAPIKeyRepository(), auth.NewJWTVerifier(hmacSecret, nil, "", "")),
+		http.MethodGet,
+		map[string]string{"Authorization": "Bearer invalid"},
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthenticateReadsPrivateReturnUnauthorized(t *testing.T) {
+	enableAuth(t, false)
+
+	w, _ := serve(
+		middlewares.AuthenticateReads(newAPIKeyRepository(), auth.NewJWTVerifier(hmacSecret, nil, "", "")),
+		http.MethodGet,
+		map[string]string{},
+	)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,52 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveCacheBypass(header string) bool {
+	gin.SetMode(gin.TestMode)
+
+	var bypassed bool
+
+	eng := gin.New()
+	eng.GET("/books/", middlewares.CacheBypass(), func(ctx *gin.Context) {
+		bypassed = cache.Bypassed(ctx.Request.Context())
+		ctx.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/books/", nil)
+	if header != "" {
+		req.Header.Set("Cache-Control", header)
+	}
+
+	eng.ServeHTTP(httptest.NewRecorder(), req)
+
+	return bypassed
+}
+
+func TestCacheBypass(t *testing.T) {
+	testCases := []struct {
+		header   string
+		bypassed bool
+	}{
+		{"", false},
+		{"no-cache", true},
+		{"No-Store", true},
+		{"max-age=0, no-cache", true},
+		{"max-age=60", false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.header, func(t *testing.T) {
+			assert.Equal(t, testCase.bypassed, serveCacheBypass(testCase.header))
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package middlewares_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIdempotencyRepository struct {
+	mu   sync.Mutex
+	keys map[string]*models.IdempotencyKey
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{keys: map[string]*models.IdempotencyKey{}}
+}
+
+func (repository *memoryIdempotencyRepository) WithContext(context.Context) repositories.IdempotencyRepository {
+	return repository
+}
+
+func (repository *memoryIdempotencyRepository) Reserve(key *models.IdempotencyKey) (*models.IdempotencyKey, bool, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if existing, ok := repository.keys[key.Client+key.Key]; ok && existing.ExpiresAt.After(time.Now()) {
+		found := *existing
+		return &found, false, nil
+	}
+
+	reserved := *key
+	repository.keys[key.Client+key.Key] = &reserved
+
+	return key, true, nil
+}
+
+func (repository *memoryIdempotencyRepository) Complete(client string, key string, statusCode int, contentType string, body []byte) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	stored := repository.keys[client+key]
+	stored.StatusCode, stored.ContentType, stored.Body = statusCode, contentType, append([]byte{}, body...)
+
+	return nil
+}
+
+func (repository *memoryIdempotencyRepository) Release(client string, key string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if stored, ok := repository.keys[client+key]; ok && stored.StatusCode == 0 {
+		delete(repository.keys, client+key)
+	}
+
+	return nil
+}
+
+func (repository *memoryIdempotencyRepository) DeleteExpired() (int64, error) {
+	return 0, nil
+}
+
+func newIdempotentEngine(repository repositories.IdempotencyRepository, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	eng := gin.New()
+	eng.POST("/books/", middlewares.Idempotency(repository, func() time.Duration { return time.Hour }), handler)
+
+	return eng
+}
+
+func post(eng *gin.Engine, remoteAddr string, key string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/books/", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("Content-Type", "application/json")
+
+	if key != "" {
+		req.Header.Set(middlewares.IdempotencyKeyHeader, key)
+	}
+
+	eng.ServeHTTP(w, req)
+
+	return w
+}
+
+// countingCreate answers 201 with the title it was sent and how many times
+// it was called.
+func countingCreate(calls *int) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var book struct {
+			Title string `json:"title"`
+		}
+
+		if err := ctx.ShouldBindJSON(&book); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{})
+			return
+		}
+
+		*calls++
+		ctx.JSON(http.StatusCreated, gin.H{"title": book.Title, "calls": *calls})
+	}
+}
+
+func TestIdempotencyReplaysTheFirstResponse(t *testing.T) {
+	calls := 0
+	eng := newIdempotentEngine(newMemoryIdempotencyRepository(), countingCreate(&calls))
+
+	first := post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Dune"}`)
+	second := post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Dune"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.JSONEq(t, `{"title": "Dune", "calls": 1}`, first.Body.String())
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), second.Header().Get("Content-Type"))
+	assert.Empty(t, first.Header().Get(middlewares.ReplayedHeader))
+	assert.Equal(t, "true", second.Header().Get(middlewares.ReplayedHeader))
+}
+
+func TestIdempotencyRejectsAKeyReusedForAnotherRequest(t *testing.T) {
+	calls := 0
+	eng := newIdempotentEngine(newMemoryIdempotencyRepository(), countingCreate(&calls))
+
+	post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Dune"}`)
+	w := post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Emma"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"message": "Idempotency-Key already used for a different request"}`, w.Body.String())
+}
+
+func TestIdempotencyRejectsAKeyInProgress(t *testing.T) {
+	var (
+		eng   *gin.Engine
+		retry *httptest.ResponseRecorder
+	)
+
+	calls := 0
+	eng = newIdempotentEngine(newMemoryIdempotencyRepository(), func(ctx *gin.Context) {
+		calls++
+		// retried while the first request is still being handled
+		retry = post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Dune"}`)
+		ctx.JSON(http.StatusCreated, gin.H{})
+	})
+
+	post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Dune"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusConflict, retry.Code)
+}
+
+func TestIdempotencyFreesTheKeyAfterAServerError(t *testing.T) {
+	calls := 0
+	eng := newIdempotentEngine(newMemoryIdempotencyRepository(), func(ctx *gin.Context) {
+		calls++
+		if calls == 1 {
+			ctx.JSON(http.StatusInternalServerError, gin.H{})
+			return
+		}
+		ctx.JSON(http.StatusCreated, gin.H{})
+	})
+
+	first := post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Dune"}`)
+	second := post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Dune"}`)
+	third := post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Dune"}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, http.StatusCreated, third.Code)
+	assert.Equal(t, "true", third.Header().Get(middlewares.ReplayedHeader))
+}
+
+func TestIdempotencyKeysBelongToTheirClient(t *testing.T) {
+	calls := 0
+	eng := newIdempotentEngine(newMemoryIdempotencyRepository(), countingCreate(&calls))
+
+	post(eng, "10.0.0.1:1234", "retry-me", `{"title": "Dune"}`)
+	w := post(eng, "10.0.0.2:1234", "retry-me", `{"title": "Dune"}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, w.Header().Get(middlewares.ReplayedHeader))
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	calls := 0
+	eng := newIdempotentEngine(newMemoryIdempotencyRepository(), countingCreate(&calls))
+
+	post(eng, "10.0.0.1:1234", "", `{"title": "Dune"}`)
+	post(eng, "10.0.0.1:1234", "", `{"title": "Dune"}`)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyRejectsATooLongKey(t *testing.T) {
+	calls := 0
+	eng := newIdempotentEngine(newMemoryIdempotencyRepository(), countingCreate(&calls))
+
+	w := post(eng, "10.0.0.1:1234", strings.Repeat("k", 256), `{"title": "Dune"}`)
+
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
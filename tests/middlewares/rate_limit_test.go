@@ -0,0 +1,207 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *fakeClock) Advance(d time.Duration) {
+	clock.now = clock.now.Add(d)
+}
+
+func newLimitedEngine(store middlewares.RateLimitStore, limit middlewares.RateLimit, before ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	eng := gin.New()
+	handlers := append(before, middlewares.RateLimiter(store, "read", func() middlewares.RateLimit { return limit }), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	eng.GET("/books/", handlers...)
+
+	return eng
+}
+
+func get(eng *gin.Engine, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/books/", nil)
+	req.RemoteAddr = remoteAddr
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	eng.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestRateLimiterAllowsUpToLimitThenRejects(t *testing.T) {
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	eng := newLimitedEngine(middlewares.NewMemoryRateLimitStore(clock.Now), middlewares.RateLimit{Requests: 3, Window: time.Minute})
+
+	for i := 2; i >= 0; i-- {
+		w := get(eng, "10.0.0.1:1234", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(i), w.Header().Get("RateLimit-Remaining"))
+	}
+
+	w := get(eng, "10.0.0.1:1234", nil)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.JSONEq(t, `{"message": "too many requests"}`, w.Body.String())
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "20", w.Header().Get("Retry-After"))
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+}
+
+func TestRateLimiterRefillsTokensOverTime(t *testing.T) {
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	eng := newLimitedEngine(middlewares.NewMemoryRateLimitStore(clock.Now), middlewares.RateLimit{Requests: 2, Window: time.Minute})
+
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", nil).Code)
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(eng, "10.0.0.1:1234", nil).Code)
+
+	clock.Advance(30 * time.Second)
+
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(eng, "10.0.0.1:1234", nil).Code)
+}
+
+func TestRateLimiterKeepsSeparateBucketsPerClient(t *testing.T) {
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	eng := newLimitedEngine(middlewares.NewMemoryRateLimitStore(clock.Now), middlewares.RateLimit{Requests: 1, Window: time.Minute})
+
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(eng, "10.0.0.1:1234", nil).Code)
+
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.2:1234", nil).Code)
+}
+
+func TestRateLimiterIgnoresUnverifiedAPIKeys(t *testing.T) {
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	eng := newLimitedEngine(middlewares.NewMemoryRateLimitStore(clock.Now), middlewares.RateLimit{Requests: 1, Window: time.Minute})
+
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", map[string]string{auth.APIKeyHeader: "olk_first"}).Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(eng, "10.0.0.1:1234", map[string]string{auth.APIKeyHeader: "olk_second"}).Code)
+}
+
+func TestRateLimiterKeysByPrincipal(t *testing.T) {
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	setPrincipal := func(ctx *gin.Context) {
+		ctx.Set(auth.PrincipalKey, auth.Principal{Subject: ctx.GetHeader("X-Subject"), Method: auth.MethodJWT})
+	}
+
+	eng := newLimitedEngine(middlewares.NewMemoryRateLimitStore(clock.Now), middlewares.RateLimit{Requests: 1, Window: time.Minute}, setPrincipal)
+
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", map[string]string{"X-Subject": "alice"}).Code)
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", map[string]string{"X-Subject": "bob"}).Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(eng, "10.0.0.3:1234", map[string]string{"X-Subject": "alice"}).Code)
+}
+
+func TestRateLimiterDisabledWithZeroRequests(t *testing.T) {
+	eng := newLimitedEngine(middlewares.NewMemoryRateLimitStore(nil), middlewares.RateLimit{Requests: 0, Window: time.Minute})
+
+	for i := 0; i < 10; i++ {
+		w := get(eng, "10.0.0.1:1234", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRateLimitStoreSeparatesScopes(t *testing.T) {
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := middlewares.NewMemoryRateLimitStore(clock.Now)
+	limit := middlewares.RateLimit{Requests: 1, Window: time.Minute}
+
+	assert.True(t, store.Take("read:ip:10.0.0.1", limit).Allowed)
+	assert.False(t, store.Take("read:ip:10.0.0.1", limit).Allowed)
+	assert.True(t, store.Take("write:ip:10.0.0.1", limit).Allowed)
+}
+
+func newFailedAuthEngine(store middlewares.RateLimitStore, limit middlewares.RateLimit) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	eng := gin.New()
+	eng.GET("/books/", middlewares.RateLimitFailedAuth(store, "read", func() middlewares.RateLimit { return limit }), func(ctx *gin.Context) {
+		if ctx.GetHeader(auth.APIKeyHeader) != "olk_valid" {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		ctx.Status(http.StatusOK)
+	})
+
+	return eng
+}
+
+func TestRateLimitFailedAuthThrottlesKeyGuessing(t *testing.T) {
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	eng := newFailedAuthEngine(middlewares.NewMemoryRateLimitStore(clock.Now), middlewares.RateLimit{Requests: 2, Window: time.Minute})
+
+	assert.Equal(t, http.StatusUnauthorized, get(eng, "10.0.0.1:1234", map[string]string{auth.APIKeyHeader: "olk_guess1"}).Code)
+	assert.Equal(t, http.StatusUnauthorized, get(eng, "10.0.0.1:1234", map[string]string{auth.APIKeyHeader: "olk_guess2"}).Code)
+
+	w := get(eng, "10.0.0.1:1234", map[string]string{auth.APIKeyHeader: "olk_valid"})
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.2:1234", map[string]string{auth.APIKeyHeader: "olk_valid"}).Code)
+
+	clock.Advance(30 * time.Second)
+
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", map[string]string{auth.APIKeyHeader: "olk_valid"}).Code)
+}
+
+func TestRateLimitFailedAuthDoesNotChargeSuccesses(t *testing.T) {
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	eng := newFailedAuthEngine(middlewares.NewMemoryRateLimitStore(clock.Now), middlewares.RateLimit{Requests: 1, Window: time.Minute})
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", map[string]string{auth.APIKeyHeader: "olk_valid"}).Code)
+	}
+}
+
+func TestRateLimitFailedAuthIgnoresAnonymousRequests(t *testing.T) {
+	store := middlewares.NewMemoryRateLimitStore(nil)
+	limit := middlewares.RateLimit{Requests: 1, Window: time.Minute}
+	eng := newFailedAuthEngine(store, limit)
+
+	store.Take("read:ip:10.0.0.1", limit)
+
+	assert.Equal(t, http.StatusOK, get(eng, "10.0.0.1:1234", map[string]string{auth.APIKeyHeader: "olk_valid"}).Code)
+	assert.True(t, store.Peek("read:authfail:10.0.0.1", limit).Allowed)
+
+	assert.Equal(t, http.StatusUnauthorized, get(eng, "10.0.0.1:1234", map[string]string{auth.APIKeyHeader: "olk_guess"}).Code)
+	assert.False(t, store.Peek("read:authfail:10.0.0.1", limit).Allowed)
+}
+
+func TestRateLimitStorePeekDoesNotTake(t *testing.T) {
+	store := middlewares.NewMemoryRateLimitStore(nil)
+	limit := middlewares.RateLimit{Requests: 1, Window: time.Minute}
+
+	assert.True(t, store.Peek("read:ip:10.0.0.1", limit).Allowed)
+	assert.True(t, store.Peek("read:ip:10.0.0.1", limit).Allowed)
+	assert.True(t, store.Take("read:ip:10.0.0.1", limit).Allowed)
+	assert.False(t, store.Peek("read:ip:10.0.0.1", limit).Allowed)
+}
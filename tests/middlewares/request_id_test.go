@@ -0,0 +1,61 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveRequestID(header string) (*httptest.ResponseRecorder, string) {
+	gin.SetMode(gin.TestMode)
+
+	var requestID string
+
+	eng := gin.New()
+	eng.GET("/books/", middlewares.RequestID(), func(ctx *gin.Context) {
+		requestID = audit.RequestID(ctx.Request.Context())
+		ctx.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/books/", nil)
+	if header != "" {
+		req.Header.Set(audit.RequestIDHeader, header)
+	}
+
+	eng.ServeHTTP(w, req)
+
+	return w, requestID
+}
+
+func TestRequestIDKeepsIncomingHeader(t *testing.T) {
+	w, requestID := serveRequestID("req-123")
+
+	assert.Equal(t, "req-123", requestID)
+	assert.Equal(t, "req-123", w.Header().Get(audit.RequestIDHeader))
+}
+
+func TestRequestIDGeneratesMissingHeader(t *testing.T) {
+	w, requestID := serveRequestID("")
+
+	_, err := uuid.Parse(requestID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, requestID, w.Header().Get(audit.RequestIDHeader))
+}
+
+func TestRequestIDReplacesOversizedHeader(t *testing.T) {
+	header := strings.Repeat("a", 65)
+
+	w, requestID := serveRequestID(header)
+
+	assert.NotEqual(t, header, requestID)
+	assert.Equal(t, requestID, w.Header().Get(audit.RequestIDHeader))
+}
@@ -3,9 +3,15 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/joaooliveira247/go_olist_challenge/src/models"
 	mock "github.com/stretchr/testify/mock"
 
+	repositories "github.com/joaooliveira247/go_olist_challenge/src/repositories"
+
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -92,6 +98,42 @@ func (_m *AuthorRepository) Delete(id uuid.UUID) error {
 	return r0
 }
 
+// Each provides a mock function with given fields: fn
+func (_m *AuthorRepository) Each(fn func(models.Author) error) error {
+	ret := _m.Called(fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Each")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(func(models.Author) error) error); ok {
+		r0 = rf(fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EachByName provides a mock function with given fields: name, fn
+func (_m *AuthorRepository) EachByName(name string, fn func(models.Author) error) error {
+	ret := _m.Called(name, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EachByName")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, func(models.Author) error) error); ok {
+		r0 = rf(name, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAll provides a mock function with given fields:
 func (_m *AuthorRepository) GetAll() ([]models.Author, error) {
 	ret := _m.Called()
@@ -150,6 +192,36 @@ func (_m *AuthorRepository) GetByID(id uuid.UUID) (models.Author, error) {
 	return r0, r1
 }
 
+// GetByIDs provides a mock function with given fields: ids
+func (_m *AuthorRepository) GetByIDs(ids []uuid.UUID) ([]models.Author, error) {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []models.Author
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]models.Author, error)); ok {
+		return rf(ids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []models.Author); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Author)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByName provides a mock function with given fields: name
 func (_m *AuthorRepository) GetByName(name string) ([]models.Author, error) {
 	ret := _m.Called(name)
@@ -180,6 +252,234 @@ func (_m *AuthorRepository) GetByName(name string) ([]models.Author, error) {
 	return r0, r1
 }
 
+// GetBySimilarName provides a mock function with given fields: name, threshold
+func (_m *AuthorRepository) GetBySimilarName(name string, threshold float64) ([]models.AuthorMatch, error) {
+	ret := _m.Called(name, threshold)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySimilarName")
+	}
+
+	var r0 []models.AuthorMatch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, float64) ([]models.AuthorMatch, error)); ok {
+		return rf(name, threshold)
+	}
+	if rf, ok := ret.Get(0).(func(string, float64) []models.AuthorMatch); ok {
+		r0 = rf(name, threshold)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AuthorMatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, float64) error); ok {
+		r1 = rf(name, threshold)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPage provides a mock function with given fields: page, pageSize
+func (_m *AuthorRepository) GetPage(page int, pageSize int) ([]models.Author, int64, error) {
+	ret := _m.Called(page, pageSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPage")
+	}
+
+	var r0 []models.Author
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(int, int) ([]models.Author, int64, error)); ok {
+		return rf(page, pageSize)
+	}
+	if rf, ok := ret.Get(0).(func(int, int) []models.Author); ok {
+		r0 = rf(page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Author)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int, int) int64); ok {
+		r1 = rf(page, pageSize)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(int, int) error); ok {
+		r2 = rf(page, pageSize)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetPageBySimilarName provides a mock function with given fields: name, threshold, page, pageSize
+func (_m *AuthorRepository) GetPageBySimilarName(name string, threshold float64, page int, pageSize int) ([]models.AuthorMatch, int64, error) {
+	ret := _m.Called(name, threshold, page, pageSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPageBySimilarName")
+	}
+
+	var r0 []models.AuthorMatch
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, float64, int, int) ([]models.AuthorMatch, int64, error)); ok {
+		return rf(name, threshold, page, pageSize)
+	}
+	if rf, ok := ret.Get(0).(func(string, float64, int, int) []models.AuthorMatch); ok {
+		r0 = rf(name, threshold, page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AuthorMatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, float64, int, int) int64); ok {
+		r1 = rf(name, threshold, page, pageSize)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, float64, int, int) error); ok {
+		r2 = rf(name, threshold, page, pageSize)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Purge provides a mock function with given fields: deletedBefore
+func (_m *AuthorRepository) Purge(deletedBefore time.Time) (int64, error) {
+	ret := _m.Called(deletedBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return rf(deletedBefore)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(deletedBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(deletedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: id
+func (_m *AuthorRepository) Restore(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Unscoped provides a mock function with given fields:
+func (_m *AuthorRepository) Unscoped() repositories.AuthorRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unscoped")
+	}
+
+	var r0 repositories.AuthorRepository
+	if rf, ok := ret.Get(0).(func() repositories.AuthorRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.AuthorRepository)
+		}
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: id, author
+func (_m *AuthorRepository) Update(id uuid.UUID, author *models.AuthorUpdate) error {
+	ret := _m.Called(id, author)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.AuthorUpdate) error); ok {
+		r0 = rf(id, author)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WithContext provides a mock function with given fields: ctx
+func (_m *AuthorRepository) WithContext(ctx context.Context) repositories.AuthorRepository {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithContext")
+	}
+
+	var r0 repositories.AuthorRepository
+	if rf, ok := ret.Get(0).(func(context.Context) repositories.AuthorRepository); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.AuthorRepository)
+		}
+	}
+
+	return r0
+}
+
+// WithNationality provides a mock function with given fields: code
+func (_m *AuthorRepository) WithNationality(code string) repositories.AuthorRepository {
+	ret := _m.Called(code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithNationality")
+	}
+
+	var r0 repositories.AuthorRepository
+	if rf, ok := ret.Get(0).(func(string) repositories.AuthorRepository); ok {
+		r0 = rf(code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.AuthorRepository)
+		}
+	}
+
+	return r0
+}
+
 // NewAuthorRepository creates a new instance of AuthorRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAuthorRepository(t interface {
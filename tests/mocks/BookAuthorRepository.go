@@ -3,9 +3,13 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/joaooliveira247/go_olist_challenge/src/models"
 	mock "github.com/stretchr/testify/mock"
 
+	repositories "github.com/joaooliveira247/go_olist_challenge/src/repositories"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -50,6 +54,26 @@ func (_m *BookAuthorRepository) Delete(bookID uuid.UUID) error {
 	return r0
 }
 
+// WithContext provides a mock function with given fields: ctx
+func (_m *BookAuthorRepository) WithContext(ctx context.Context) repositories.BookAuthorRepository {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithContext")
+	}
+
+	var r0 repositories.BookAuthorRepository
+	if rf, ok := ret.Get(0).(func(context.Context) repositories.BookAuthorRepository); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.BookAuthorRepository)
+		}
+	}
+
+	return r0
+}
+
 // NewBookAuthorRepository creates a new instance of BookAuthorRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewBookAuthorRepository(t interface {
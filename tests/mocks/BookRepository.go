@@ -3,9 +3,15 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/joaooliveira247/go_olist_challenge/src/models"
 	mock "github.com/stretchr/testify/mock"
 
+	repositories "github.com/joaooliveira247/go_olist_challenge/src/repositories"
+
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -15,7 +21,7 @@ type BookRepository struct {
 }
 
 // Create provides a mock function with given fields: book
-func (_m *BookRepository) Create(book *models.Book) (uuid.UUID, error) {
+func (_m *BookRepository) Create(book *models.BookIn) (uuid.UUID, error) {
 	ret := _m.Called(book)
 
 	if len(ret) == 0 {
@@ -24,10 +30,10 @@ func (_m *BookRepository) Create(book *models.Book) (uuid.UUID, error) {
 
 	var r0 uuid.UUID
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*models.Book) (uuid.UUID, error)); ok {
+	if rf, ok := ret.Get(0).(func(*models.BookIn) (uuid.UUID, error)); ok {
 		return rf(book)
 	}
-	if rf, ok := ret.Get(0).(func(*models.Book) uuid.UUID); ok {
+	if rf, ok := ret.Get(0).(func(*models.BookIn) uuid.UUID); ok {
 		r0 = rf(book)
 	} else {
 		if ret.Get(0) != nil {
@@ -35,7 +41,7 @@ func (_m *BookRepository) Create(book *models.Book) (uuid.UUID, error) {
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*models.Book) error); ok {
+	if rf, ok := ret.Get(1).(func(*models.BookIn) error); ok {
 		r1 = rf(book)
 	} else {
 		r1 = ret.Error(1)
@@ -44,17 +50,113 @@ func (_m *BookRepository) Create(book *models.Book) (uuid.UUID, error) {
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *BookRepository) Delete(id uuid.UUID) error {
-	ret := _m.Called(id)
+// CreateEdition provides a mock function with given fields: id, edition
+func (_m *BookRepository) CreateEdition(id uuid.UUID, edition *models.EditionIn) (uuid.UUID, error) {
+	ret := _m.Called(id, edition)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateEdition")
+	}
+
+	var r0 uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.EditionIn) (uuid.UUID, error)); ok {
+		return rf(id, edition)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.EditionIn) uuid.UUID); ok {
+		r0 = rf(id, edition)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, *models.EditionIn) error); ok {
+		r1 = rf(id, edition)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateMany provides a mock function with given fields: books
+func (_m *BookRepository) CreateMany(books []models.BookIn) ([]uuid.UUID, error) {
+	ret := _m.Called(books)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMany")
+	}
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]models.BookIn) ([]uuid.UUID, error)); ok {
+		return rf(books)
+	}
+	if rf, ok := ret.Get(0).(func([]models.BookIn) []uuid.UUID); ok {
+		r0 = rf(books)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]models.BookIn) error); ok {
+		r1 = rf(books)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: id, version
+func (_m *BookRepository) Delete(id uuid.UUID, version uint) error {
+	ret := _m.Called(id, version)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uint) error); ok {
+		r0 = rf(id, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EachBook provides a mock function with given fields: query, args, fn
+func (_m *BookRepository) EachBook(query string, args []interface{}, fn func(models.BookOut) error) error {
+	ret := _m.Called(query, args, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EachBook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []interface{}, func(models.BookOut) error) error); ok {
+		r0 = rf(query, args, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EachBookByAuthorID provides a mock function with given fields: authorID, fn
+func (_m *BookRepository) EachBookByAuthorID(authorID uuid.UUID, fn func(models.BookOut) error) error {
+	ret := _m.Called(authorID, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EachBookByAuthorID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, func(models.BookOut) error) error); ok {
+		r0 = rf(authorID, fn)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -120,9 +222,9 @@ func (_m *BookRepository) GetBookByID(id uuid.UUID) (models.BookOut, error) {
 	return r0, r1
 }
 
-// GetBookByQuery provides a mock function with given fields: query
-func (_m *BookRepository) GetBookByQuery(query string) ([]models.BookOut, error) {
-	ret := _m.Called(query)
+// GetBookByQuery provides a mock function with given fields: query, args
+func (_m *BookRepository) GetBookByQuery(query string, args []interface{}) ([]models.BookOut, error) {
+	ret := _m.Called(query, args)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetBookByQuery")
@@ -130,19 +232,19 @@ func (_m *BookRepository) GetBookByQuery(query string) ([]models.BookOut, error)
 
 	var r0 []models.BookOut
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]models.BookOut, error)); ok {
-		return rf(query)
+	if rf, ok := ret.Get(0).(func(string, []interface{}) ([]models.BookOut, error)); ok {
+		return rf(query, args)
 	}
-	if rf, ok := ret.Get(0).(func(string) []models.BookOut); ok {
-		r0 = rf(query)
+	if rf, ok := ret.Get(0).(func(string, []interface{}) []models.BookOut); ok {
+		r0 = rf(query, args)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.BookOut)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(query)
+	if rf, ok := ret.Get(1).(func(string, []interface{}) error); ok {
+		r1 = rf(query, args)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -180,17 +282,240 @@ func (_m *BookRepository) GetBooksByAuthorID(authorID uuid.UUID) ([]models.BookO
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: id, book
-func (_m *BookRepository) Update(id uuid.UUID, book *models.BookUpdate) error {
-	ret := _m.Called(id, book)
+// GetBooksByIDs provides a mock function with given fields: ids
+func (_m *BookRepository) GetBooksByIDs(ids []uuid.UUID) ([]models.BookOut, error) {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBooksByIDs")
+	}
+
+	var r0 []models.BookOut
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]models.BookOut, error)); ok {
+		return rf(ids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []models.BookOut); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BookOut)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBooksByWorkID provides a mock function with given fields: workID
+func (_m *BookRepository) GetBooksByWorkID(workID uuid.UUID) ([]models.BookOut, error) {
+	ret := _m.Called(workID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBooksByWorkID")
+	}
+
+	var r0 []models.BookOut
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.BookOut, error)); ok {
+		return rf(workID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.BookOut); ok {
+		r0 = rf(workID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BookOut)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(workID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBooksPage provides a mock function with given fields: query, args, page, pageSize
+func (_m *BookRepository) GetBooksPage(query string, args []interface{}, page int, pageSize int) ([]models.BookOut, int64, error) {
+	ret := _m.Called(query, args, page, pageSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBooksPage")
+	}
+
+	var r0 []models.BookOut
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, []interface{}, int, int) ([]models.BookOut, int64, error)); ok {
+		return rf(query, args, page, pageSize)
+	}
+	if rf, ok := ret.Get(0).(func(string, []interface{}, int, int) []models.BookOut); ok {
+		r0 = rf(query, args, page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BookOut)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []interface{}, int, int) int64); ok {
+		r1 = rf(query, args, page, pageSize)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, []interface{}, int, int) error); ok {
+		r2 = rf(query, args, page, pageSize)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetBooksPageByAuthorIDs provides a mock function with given fields: authorIDs, page, pageSize
+func (_m *BookRepository) GetBooksPageByAuthorIDs(authorIDs []uuid.UUID, page int, pageSize int) (map[uuid.UUID]models.BookPage, error) {
+	ret := _m.Called(authorIDs, page, pageSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBooksPageByAuthorIDs")
+	}
+
+	var r0 map[uuid.UUID]models.BookPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID, int, int) (map[uuid.UUID]models.BookPage, error)); ok {
+		return rf(authorIDs, page, pageSize)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID, int, int) map[uuid.UUID]models.BookPage); ok {
+		r0 = rf(authorIDs, page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID]models.BookPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID, int, int) error); ok {
+		r1 = rf(authorIDs, page, pageSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTranslations provides a mock function with given fields: id
+func (_m *BookRepository) GetTranslations(id uuid.UUID) ([]models.BookOut, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTranslations")
+	}
+
+	var r0 []models.BookOut
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.BookOut, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.BookOut); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BookOut)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: deletedBefore
+func (_m *BookRepository) Purge(deletedBefore time.Time) (int64, error) {
+	ret := _m.Called(deletedBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return rf(deletedBefore)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(deletedBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(deletedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: id
+func (_m *BookRepository) Restore(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Unscoped provides a mock function with given fields:
+func (_m *BookRepository) Unscoped() repositories.BookRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unscoped")
+	}
+
+	var r0 repositories.BookRepository
+	if rf, ok := ret.Get(0).(func() repositories.BookRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.BookRepository)
+		}
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: id, book, version
+func (_m *BookRepository) Update(id uuid.UUID, book *models.BookUpdate, version uint) error {
+	ret := _m.Called(id, book, version)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.BookUpdate) error); ok {
-		r0 = rf(id, book)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.BookUpdate, uint) error); ok {
+		r0 = rf(id, book, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -198,6 +523,26 @@ func (_m *BookRepository) Update(id uuid.UUID, book *models.BookUpdate) error {
 	return r0
 }
 
+// WithContext provides a mock function with given fields: ctx
+func (_m *BookRepository) WithContext(ctx context.Context) repositories.BookRepository {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithContext")
+	}
+
+	var r0 repositories.BookRepository
+	if rf, ok := ret.Get(0).(func(context.Context) repositories.BookRepository); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.BookRepository)
+		}
+	}
+
+	return r0
+}
+
 // NewBookRepository creates a new instance of BookRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewBookRepository(t interface {
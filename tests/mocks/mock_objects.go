@@ -21,6 +21,7 @@ func NewMockBookOut() models.BookOut {
 			Title:           "the Rust Programming Language",
 			Edition:         1,
 			PublicationYear: 2018,
+			Version:         1,
 		},
 		AuthorsName: pq.StringArray{"Carol Nichols", "Steve Klabnik"},
 	}
@@ -69,11 +70,7 @@ func NewMockBooks() []models.BookOut {
 
 func NewMockUpdateBook() models.BookUpdate {
 	return models.BookUpdate{
-		BookInfo: struct {
-			Title           string `json:"title,omitempty"`
-			Edition         uint8  `json:"edition,omitempty"`
-			PublicationYear uint   `json:"publication_year,omitempty"`
-		}{
+		BookInfo: models.BookInfo{
 			Title:           "The Rust Programming Language",
 			Edition:         1,
 			PublicationYear: 2018,
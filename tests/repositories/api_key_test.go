@@ -0,0 +1,100 @@
+package repositories_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAPIKeySuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	keyID := uuid.New()
+	key := models.APIKey{Name: "ci", Prefix: "olk_abcd", Hash: "hash", Role: "librarian"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "api_keys" ("name","prefix","hash","role","created_at","revoked_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`,
+	)).WithArgs(key.Name, key.Prefix, key.Hash, key.Role, sqlmock.AnyArg(), nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(keyID))
+	mock.ExpectCommit()
+
+	repository := repositories.NewAPIKeyRepository(gormDB)
+	id, err := repository.Create(&key)
+
+	assert.Nil(t, err)
+	assert.Equal(t, keyID, id)
+}
+
+func TestGetActiveAPIKeyByHashSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	keyID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "api_keys" WHERE hash = $1 AND revoked_at IS NULL ORDER BY "api_keys"."id" LIMIT $2`,
+	)).WithArgs("hash", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "hash", "role"}).AddRow(keyID, "ci", "olk_abcd", "hash", "admin"))
+
+	repository := repositories.NewAPIKeyRepository(gormDB)
+	key, err := repository.GetActiveByHash("hash")
+
+	assert.Nil(t, err)
+	assert.Equal(t, keyID, key.ID)
+	assert.Equal(t, "admin", key.Role)
+}
+
+func TestGetActiveAPIKeyByHashReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "api_keys" WHERE hash = $1 AND revoked_at IS NULL ORDER BY "api_keys"."id" LIMIT $2`,
+	)).WithArgs("hash", 1).WillReturnRows(sqlmock.NewRows([]string{}))
+
+	repository := repositories.NewAPIKeyRepository(gormDB)
+	_, err := repository.GetActiveByHash("hash")
+
+	assert.ErrorIs(t, err, &errors.APIKeyNotFound)
+}
+
+func TestRevokeAPIKeyReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	keyID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "api_keys" SET "revoked_at"=$1 WHERE id = $2 AND revoked_at IS NULL`,
+	)).WithArgs(sqlmock.AnyArg(), keyID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	repository := repositories.NewAPIKeyRepository(gormDB)
+	err := repository.Revoke(keyID)
+
+	assert.ErrorIs(t, err, &errors.APIKeyNotFound)
+}
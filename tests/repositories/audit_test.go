@@ -0,0 +1,111 @@
+package repositories_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const insertAuditLog = `INSERT INTO "audit_log" ("entity","entity_id","action","actor","before","after","request_id","created_at") VALUES`
+
+func expectAudit(mock sqlmock.Sqlmock, entries int) {
+	rows := sqlmock.NewRows([]string{"id"})
+
+	for range entries {
+		rows.AddRow(uuid.New())
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(insertAuditLog)).WillReturnRows(rows)
+}
+
+func TestAuditRecordsActorAndRequestID(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+	ctx := audit.WithRequestID(audit.WithActor(context.Background(), "api_key:123"), "req-1")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "authors" ("name","sort_name","biography","birth_year","death_year","nationality","website","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING "id"`)).
+		WithArgs("Luciano Ramalho", nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(authorID))
+	mock.ExpectQuery(regexp.QuoteMeta(insertAuditLog)).
+		WithArgs(audit.EntityAuthor, authorID, audit.ActionCreate, "api_key:123", nil, sqlmock.AnyArg(), "req-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewAuthorRepository(gormDB).WithContext(ctx)
+	_, err := repository.Create(&models.Author{Name: "Luciano Ramalho"})
+
+	assert.Nil(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuditLogsSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "audit_log" WHERE entity = $1 AND entity_id = $2`)).
+		WithArgs(audit.EntityBook, bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_log" WHERE entity = $1 AND entity_id = $2 ORDER BY created_at DESC LIMIT $3 OFFSET $4`)).
+		WithArgs(audit.EntityBook, bookID, 2, 2).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "entity", "entity_id", "action", "actor", "before", "after", "request_id", "created_at"}).
+				AddRow(uuid.New(), audit.EntityBook, bookID, audit.ActionCreate, "cli", nil, []byte(`{"title":"Fluent Python"}`), "", time.Now()),
+		)
+
+	repository := repositories.NewAuditRepository(gormDB)
+	logs, total, err := repository.GetAll(audit.EntityBook, bookID, 2, 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, logs, 1)
+	assert.Nil(t, logs[0].Before)
+	assert.JSONEq(t, `{"title":"Fluent Python"}`, string(logs[0].After))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuditLogsWithoutFilters(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery("^" + regexp.QuoteMeta(`SELECT count(*) FROM "audit_log"`) + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("^" + regexp.QuoteMeta(`SELECT * FROM "audit_log" ORDER BY created_at DESC LIMIT $1`) + "$").
+		WithArgs(50).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	repository := repositories.NewAuditRepository(gormDB)
+	logs, total, err := repository.GetAll("", uuid.Nil, 1, 50)
+
+	assert.Nil(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, logs)
+	assert.NotNil(t, logs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
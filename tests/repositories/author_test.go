@@ -3,6 +3,7 @@ package repositories_test
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -29,9 +30,11 @@ func TestCreateSuccess(t *testing.T) {
 	}
 
 	mock.ExpectBegin()
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "authors" ("name") VALUES ($1) RETURNING "id"`)).
-		WithArgs(author.Name).
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "authors" ("name","sort_name","biography","birth_year","death_year","nationality","website","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING "id"`)).
+		WithArgs(author.Name, nil, nil, nil, nil, nil, nil, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(expectedID))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
 	mock.ExpectCommit()
 
 	id, err := repository.Create(author)
@@ -51,7 +54,7 @@ func TestCreateAlreadyExistsError(t *testing.T) {
 	}()
 
 	mock.ExpectBegin()
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "authors" ("name") VALUES ($1) RETURNING "id"`)).WithArgs("Luciano Ramalho").WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "authors" ("name","sort_name","biography","birth_year","death_year","nationality","website","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING "id"`)).WithArgs("Luciano Ramalho", nil, nil, nil, nil, nil, nil, nil).WillReturnError(gorm.ErrDuplicatedKey)
 	mock.ExpectRollback()
 
 	repository := repositories.NewAuthorRepository(gormDB)
@@ -77,7 +80,7 @@ func TestCreateNotExpectedError(t *testing.T) {
 		Name: "Luciano Ramalho",
 	}
 	mock.ExpectBegin()
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "authors" ("name") VALUES ($1) RETURNING "id"`)).WithArgs(author.Name).WillReturnError(&errors.AuthorGenericError)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "authors" ("name","sort_name","biography","birth_year","death_year","nationality","website","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING "id"`)).WithArgs(author.Name, nil, nil, nil, nil, nil, nil, nil).WillReturnError(&errors.AuthorGenericError)
 	mock.ExpectRollback()
 
 	id, err := repository.Create(author)
@@ -111,8 +114,10 @@ func TestCreateManySuccess(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`INSERT INTO "authors" ("name") VALUES ($1),($2) RETURNING "id"`),
-	).WithArgs(authors[0].Name, authors[1].Name).WillReturnRows(rows)
+		`INSERT INTO "authors" ("name","sort_name","biography","birth_year","death_year","nationality","website","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8),($9,$10,$11,$12,$13,$14,$15,$16) RETURNING "id"`),
+	).WithArgs(authors[0].Name, nil, nil, nil, nil, nil, nil, nil, authors[1].Name, nil, nil, nil, nil, nil, nil, nil).WillReturnRows(rows)
+	expectAudit(mock, 2)
+	expectOutbox(mock, 2)
 	mock.ExpectCommit()
 
 	ids, err := repository.CreateMany(&authors)
@@ -140,8 +145,8 @@ func TestCreateManyNotExpectedError(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`INSERT INTO "authors" ("name") VALUES ($1),($2) RETURNING "id"`),
-	).WithArgs(authors[0].Name, authors[1].Name).WillReturnError(&errors.AuthorGenericError)
+		`INSERT INTO "authors" ("name","sort_name","biography","birth_year","death_year","nationality","website","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8),($9,$10,$11,$12,$13,$14,$15,$16) RETURNING "id"`),
+	).WithArgs(authors[0].Name, nil, nil, nil, nil, nil, nil, nil, authors[1].Name, nil, nil, nil, nil, nil, nil, nil).WillReturnError(&errors.AuthorGenericError)
 	mock.ExpectRollback()
 
 	ids, err := repository.CreateMany(&authors)
@@ -151,6 +156,33 @@ func TestCreateManyNotExpectedError(t *testing.T) {
 	assert.Error(t, err, "some error not mapped")
 }
 
+func TestCreateManyAlreadyExistsError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+
+	authors := []models.Author{
+		{Name: "J. K. Rowling"},
+		{Name: "J. K. Rowling"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "authors" ("name","sort_name","biography","birth_year","death_year","nationality","website","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8),($9,$10,$11,$12,$13,$14,$15,$16) RETURNING "id"`),
+	).WithArgs(authors[0].Name, nil, nil, nil, nil, nil, nil, nil, authors[1].Name, nil, nil, nil, nil, nil, nil, nil).WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
+
+	ids, err := repository.CreateMany(&authors)
+
+	assert.Nil(t, ids)
+	assert.ErrorIs(t, err, &errors.AuthorAlreadyExists)
+}
+
 func TestGetAllSuccess(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
@@ -172,7 +204,7 @@ func TestGetAllSuccess(t *testing.T) {
 		AddRow(uuid.New(), authors[1].Name).
 		AddRow(uuid.New(), authors[2].Name)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors"`)).WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE "authors"."deleted_at" IS NULL`)).WillReturnRows(rows)
 
 	results, err := repository.GetAll()
 
@@ -190,7 +222,7 @@ func TestGetAllNotExpectedError(t *testing.T) {
 
 	repository := repositories.NewAuthorRepository(gormDB)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors"`)).WillReturnError(&errors.AuthorGenericError)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE "authors"."deleted_at" IS NULL`)).WillReturnError(&errors.AuthorGenericError)
 
 	results, err := repository.GetAll()
 
@@ -213,7 +245,7 @@ func TestGetByIDSuccess(t *testing.T) {
 
 	row := mock.NewRows([]string{"id", "name"}).AddRow(expectedID, "Luciano Ramalho")
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 ORDER BY "authors"."id" LIMIT $2`)).WithArgs(expectedID, 1).WillReturnRows(row)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND "authors"."deleted_at" IS NULL ORDER BY "authors"."id" LIMIT $2`)).WithArgs(expectedID, 1).WillReturnRows(row)
 
 	result, err := repository.GetByID(expectedID)
 
@@ -222,6 +254,31 @@ func TestGetByIDSuccess(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestGetByIDsSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+
+	first, second := uuid.New(), uuid.New()
+
+	rows := mock.NewRows([]string{"id", "name"}).
+		AddRow(first, "Luciano Ramalho").
+		AddRow(second, "Brett Slatkin")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id IN ($1,$2) AND "authors"."deleted_at" IS NULL`)).WithArgs(first, second).WillReturnRows(rows)
+
+	result, err := repository.GetByIDs([]uuid.UUID{first, second})
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, second, result[1].ID)
+}
+
 func TestGetByIDNotFoundError(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
@@ -232,7 +289,7 @@ func TestGetByIDNotFoundError(t *testing.T) {
 
 	expectedID := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 ORDER BY "authors"."id" LIMIT $2`)).WithArgs(expectedID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND "authors"."deleted_at" IS NULL ORDER BY "authors"."id" LIMIT $2`)).WithArgs(expectedID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
 	repository := repositories.NewAuthorRepository(gormDB)
 
@@ -255,7 +312,7 @@ func TestGetByIDNotExpectedError(t *testing.T) {
 
 	expectedID := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 ORDER BY "authors"."id" LIMIT $2`)).WithArgs(expectedID, 1).WillReturnError(&errors.AuthorGenericError)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND "authors"."deleted_at" IS NULL ORDER BY "authors"."id" LIMIT $2`)).WithArgs(expectedID, 1).WillReturnError(&errors.AuthorGenericError)
 
 	result, err := repository.GetByID(expectedID)
 
@@ -274,7 +331,7 @@ func TestGetByNameSuccess(t *testing.T) {
 
 	rows := mock.NewRows([]string{"id", "name"}).AddRow(uuid.New(), "Luciano Ramalho").AddRow(uuid.New(), "Luciano Peres")
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE name LIKE $1`)).WithArgs("%Luciano%").WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE name LIKE $1 AND "authors"."deleted_at" IS NULL`)).WithArgs("%Luciano%").WillReturnRows(rows)
 
 	repository := repositories.NewAuthorRepository(gormDB)
 
@@ -284,6 +341,56 @@ func TestGetByNameSuccess(t *testing.T) {
 	assert.Len(t, result, 2)
 }
 
+func TestEachStreamsEveryAuthor(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	rows := mock.NewRows([]string{"id", "name"}).AddRow(uuid.New(), "J. K. Rowling").AddRow(uuid.New(), "Stephen King")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE "authors"."deleted_at" IS NULL`)).WillReturnRows(rows)
+
+	repository := repositories.NewAuthorRepository(gormDB)
+
+	var names []string
+
+	err := repository.Each(func(author models.Author) error {
+		names = append(names, author.Name)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"J. K. Rowling", "Stephen King"}, names)
+}
+
+func TestEachByNameStopsAtCallbackError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	rows := mock.NewRows([]string{"id", "name"}).AddRow(uuid.New(), "Luciano Ramalho").AddRow(uuid.New(), "Luciano Peres")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE name LIKE $1 AND "authors"."deleted_at" IS NULL`)).WithArgs("%Luciano%").WillReturnRows(rows)
+
+	repository := repositories.NewAuthorRepository(gormDB)
+
+	calls := 0
+
+	err := repository.EachByName("Luciano", func(models.Author) error {
+		calls++
+		return &errors.AuthorGenericError
+	})
+
+	assert.ErrorIs(t, err, &errors.AuthorGenericError)
+	assert.Equal(t, 1, calls)
+}
+
 func TestGetByNameEmptySlice(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
@@ -292,7 +399,7 @@ func TestGetByNameEmptySlice(t *testing.T) {
 		db.Close()
 	}()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE name LIKE $1`)).WithArgs("%Test%").WillReturnRows(mock.NewRows([]string{"id", "name"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE name LIKE $1 AND "authors"."deleted_at" IS NULL`)).WithArgs("%Test%").WillReturnRows(mock.NewRows([]string{"id", "name"}))
 
 	repository := repositories.NewAuthorRepository(gormDB)
 
@@ -310,7 +417,7 @@ func TestGetByNameNotExpectedError(t *testing.T) {
 		db.Close()
 	}()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE name LIKE $1`)).WithArgs("%Luciano%").WillReturnError(&errors.AuthorGenericError)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE name LIKE $1 AND "authors"."deleted_at" IS NULL`)).WithArgs("%Luciano%").WillReturnError(&errors.AuthorGenericError)
 
 	repository := repositories.NewAuthorRepository(gormDB)
 
@@ -321,6 +428,187 @@ func TestGetByNameNotExpectedError(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+func TestGetBySimilarNameSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`)).
+		WithArgs("0.3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT *, word_similarity(immutable_unaccent(lower($1)), immutable_unaccent(lower(name))) AS similarity FROM "authors" WHERE immutable_unaccent(lower($2)) <% immutable_unaccent(lower(name)) AND "authors"."deleted_at" IS NULL ORDER BY similarity DESC, name`)).
+		WithArgs("Ramalo", "Ramalo").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at", "similarity"}).AddRow(authorID, "Luciano Ramalho", nil, 0.625))
+	mock.ExpectCommit()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	result, err := repository.GetBySimilarName("Ramalo", 0.3)
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, authorID, result[0].ID)
+	assert.Equal(t, "Luciano Ramalho", result[0].Name)
+	assert.Equal(t, 0.625, result[0].Similarity)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBySimilarNameUnscopedIncludeDeleted(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`)).
+		WithArgs("0.5").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("^"+regexp.QuoteMeta(`SELECT *, word_similarity(immutable_unaccent(lower($1)), immutable_unaccent(lower(name))) AS similarity FROM "authors" WHERE immutable_unaccent(lower($2)) <% immutable_unaccent(lower(name)) ORDER BY similarity DESC, name`)+"$").
+		WithArgs("joao", "joao").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at", "similarity"}))
+	mock.ExpectCommit()
+
+	repository := repositories.NewAuthorRepository(gormDB).Unscoped()
+	result, err := repository.GetBySimilarName("joao", 0.5)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBySimilarNameNotExpectedError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`)).
+		WithArgs("0.3").
+		WillReturnError(&errors.AuthorGenericError)
+	mock.ExpectRollback()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	result, err := repository.GetBySimilarName("joao", 0.3)
+
+	assert.ErrorIs(t, err, &errors.AuthorGenericError)
+	assert.Nil(t, result)
+}
+
+func TestGetPageBySimilarNameSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`)).
+		WithArgs("0.3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "authors" WHERE immutable_unaccent(lower($1)) <% immutable_unaccent(lower(name)) AND "authors"."deleted_at" IS NULL`)).
+		WithArgs("Ramalo").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT *, word_similarity(immutable_unaccent(lower($1)), immutable_unaccent(lower(name))) AS similarity FROM "authors" WHERE immutable_unaccent(lower($2)) <% immutable_unaccent(lower(name)) AND "authors"."deleted_at" IS NULL ORDER BY similarity DESC, name, id LIMIT $3 OFFSET $4`)).
+		WithArgs("Ramalo", "Ramalo", 5, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at", "similarity"}).AddRow(authorID, "Luciano Ramalho", nil, 0.625))
+	mock.ExpectCommit()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	result, total, err := repository.GetPageBySimilarName("Ramalo", 0.3, 3, 5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(11), total)
+	assert.Len(t, result, 1)
+	assert.Equal(t, authorID, result[0].ID)
+	assert.Equal(t, 0.625, result[0].Similarity)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPageBySimilarNameWithoutMatches(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`)).
+		WithArgs("0.3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "authors"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectCommit()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	result, total, err := repository.GetPageBySimilarName("joao", 0.3, 1, 20)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPageSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "authors" WHERE "authors"."deleted_at" IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE "authors"."deleted_at" IS NULL ORDER BY name, id LIMIT $1 OFFSET $2`)).
+		WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(authorID, "Luciano Ramalho"))
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	result, total, err := repository.GetPage(2, 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, result, 1)
+	assert.Equal(t, authorID, result[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPageReturnGenericError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "authors"`)).
+		WillReturnError(&errors.AuthorGenericError)
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	result, total, err := repository.GetPage(1, 20)
+
+	assert.ErrorIs(t, err, &errors.AuthorGenericError)
+	assert.Equal(t, int64(0), total)
+	assert.Nil(t, result)
+}
+
 func TestDeleteSuccess(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
@@ -332,7 +620,12 @@ func TestDeleteSuccess(t *testing.T) {
 	expectedID := uuid.New()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "authors" WHERE "authors"."id" = $1`)).WithArgs(expectedID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND "authors"."deleted_at" IS NULL ORDER BY "authors"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(expectedID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(expectedID, "Luciano Ramalho"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "authors" SET "deleted_at"=$1 WHERE "authors"."id" = $2 AND "authors"."deleted_at" IS NULL`)).WithArgs(sqlmock.AnyArg(), expectedID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
 	mock.ExpectCommit()
 
 	repository := repositories.NewAuthorRepository(gormDB)
@@ -353,7 +646,10 @@ func TestDeleteNotExpectedError(t *testing.T) {
 	expectedID := uuid.New()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "authors" WHERE "authors"."id" = $1`)).WithArgs(expectedID).WillReturnError(&errors.AuthorGenericError)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND "authors"."deleted_at" IS NULL ORDER BY "authors"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(expectedID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(expectedID, "Luciano Ramalho"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "authors" SET "deleted_at"=$1 WHERE "authors"."id" = $2 AND "authors"."deleted_at" IS NULL`)).WithArgs(sqlmock.AnyArg(), expectedID).WillReturnError(&errors.AuthorGenericError)
 	mock.ExpectRollback()
 
 	repository := repositories.NewAuthorRepository(gormDB)
@@ -375,10 +671,10 @@ func TestDeleteNotFoundError(t *testing.T) {
 	expectedID := uuid.New()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "authors" WHERE "authors"."id" = $1`)).
-		WithArgs(expectedID).
-		WillReturnResult(sqlmock.NewResult(0, 0))
-	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND "authors"."deleted_at" IS NULL ORDER BY "authors"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(expectedID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	mock.ExpectRollback()
 
 	repository := repositories.NewAuthorRepository(gormDB)
 
@@ -387,3 +683,261 @@ func TestDeleteNotFoundError(t *testing.T) {
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, &errors.AuthorNotFound)
 }
+
+func TestGetAllUnscopedIncludeDeleted(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	repository := repositories.NewAuthorRepository(gormDB).Unscoped()
+
+	rows := mock.NewRows([]string{"id", "name", "deleted_at"}).
+		AddRow(uuid.New(), "J. K. Rowling", nil).
+		AddRow(uuid.New(), "Stephen King", time.Now())
+
+	mock.ExpectQuery("^" + regexp.QuoteMeta(`SELECT * FROM "authors"`) + "$").WillReturnRows(rows)
+
+	results, err := repository.GetAll()
+
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+	assert.False(t, results[0].DeletedAt.Valid)
+	assert.True(t, results[1].DeletedAt.Valid)
+}
+
+func TestRestoreAuthorSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND deleted_at IS NOT NULL ORDER BY "authors"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(authorID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at"}).AddRow(authorID, "Luciano Ramalho", time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "authors" SET "deleted_at"=$1 WHERE id = $2`)).
+		WithArgs(nil, authorID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	err := repository.Restore(authorID)
+
+	assert.Nil(t, err)
+}
+
+func TestRestoreAuthorReturnAlreadyExists(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND deleted_at IS NOT NULL ORDER BY "authors"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(authorID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at"}).AddRow(authorID, "Luciano Ramalho", time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "authors" SET "deleted_at"=$1 WHERE id = $2`)).
+		WithArgs(nil, authorID).
+		WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	err := repository.Restore(authorID)
+
+	assert.ErrorIs(t, err, &errors.AuthorAlreadyExists)
+}
+
+func TestRestoreAuthorNotFoundError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND deleted_at IS NOT NULL ORDER BY "authors"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(authorID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at"}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	err := repository.Restore(authorID)
+
+	assert.ErrorIs(t, err, &errors.AuthorNotFound)
+}
+
+func TestPurgeAuthorsSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	deletedBefore := time.Now().Add(-30 * 24 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "deleted_at"})
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	for _, id := range ids {
+		rows.AddRow(id, "Stephen King", deletedBefore.Add(-time.Hour))
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE deleted_at < $1`)).
+		WithArgs(deletedBefore).
+		WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "authors" WHERE "authors"."id" IN ($1,$2,$3)`)).
+		WithArgs(ids[0], ids[1], ids[2]).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	expectAudit(mock, 3)
+	expectOutbox(mock, 3)
+	mock.ExpectCommit()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	purged, err := repository.Purge(deletedBefore)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), purged)
+}
+
+const lockAuthor = `SELECT * FROM "authors" WHERE id = $1 AND "authors"."deleted_at" IS NULL ORDER BY "authors"."id" LIMIT $2 FOR UPDATE`
+
+func TestUpdateAuthorSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockAuthor)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "birth_year"}).AddRow(id, "Jorge Amado", 1912))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "authors" SET "death_year"=$1,"nationality"=$2 WHERE id = $3 AND "authors"."deleted_at" IS NULL`)).
+		WithArgs(2001, "BR", id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+
+	err := repository.Update(id, &models.AuthorUpdate{DeathYear: 2001, Nationality: "BR"})
+
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateAuthorReturnInvalidYearsAgainstStoredBirth(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockAuthor)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "birth_year"}).AddRow(id, "Jorge Amado", 1912))
+	mock.ExpectRollback()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+
+	err := repository.Update(id, &models.AuthorUpdate{DeathYear: 1900})
+
+	assert.ErrorIs(t, err, &errors.InvalidAuthorYears)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateAuthorReturnAlreadyExists(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockAuthor)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id, "Jorge Amado"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "authors" SET "name"=$1 WHERE id = $2 AND "authors"."deleted_at" IS NULL`)).
+		WithArgs("Luciano Ramalho", id).
+		WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+
+	err := repository.Update(id, &models.AuthorUpdate{Name: "Luciano Ramalho"})
+
+	assert.ErrorIs(t, err, &errors.AuthorAlreadyExists)
+}
+
+func TestUpdateAuthorReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockAuthor)).WithArgs(id, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewAuthorRepository(gormDB)
+
+	err := repository.Update(id, &models.AuthorUpdate{Name: "Luciano Ramalho"})
+
+	assert.ErrorIs(t, err, &errors.AuthorNotFound)
+}
+
+func TestGetAllWithNationality(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	repository := repositories.NewAuthorRepository(gormDB).WithNationality("BR")
+
+	rows := mock.NewRows([]string{"id", "name", "nationality"}).AddRow(uuid.New(), "Jorge Amado", "BR")
+
+	mock.ExpectQuery("^" + regexp.QuoteMeta(`SELECT * FROM "authors" WHERE nationality = $1 AND "authors"."deleted_at" IS NULL`) + "$").
+		WithArgs("BR").
+		WillReturnRows(rows)
+
+	results, err := repository.GetAll()
+
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "BR", *results[0].Nationality)
+}
@@ -27,13 +27,15 @@ func TestCreateBookAuthorSuccess(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectExec(
 		regexp.QuoteMeta(
-			`INSERT INTO "book_author" ("book_id","author_id") VALUES ($1,$2)`,
+			`INSERT INTO "book_author" ("book_id","author_id","role","position") VALUES ($1,$2,$3,$4)`,
 		),
-	).WithArgs(bookID, authorID).WillReturnResult(sqlmock.NewResult(1, 1))
+	).WithArgs(bookID, authorID, models.ContributorAuthor, 0).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
 	mock.ExpectCommit()
 
 	repository := repositories.NewBookAuthorRepository(gormDB)
-	err := repository.Create(&models.BookAuthor{BookID: bookID, AuthorID: authorID})
+	err := repository.Create(&models.BookAuthor{BookID: bookID, AuthorID: authorID, Role: models.ContributorAuthor})
 
 	assert.Nil(t, err)
 }
@@ -52,13 +54,13 @@ func TestCreateBookAuthorReturnGenericError(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectExec(
 		regexp.QuoteMeta(
-			`INSERT INTO "book_author" ("book_id","author_id") VALUES ($1,$2)`,
+			`INSERT INTO "book_author" ("book_id","author_id","role","position") VALUES ($1,$2,$3,$4)`,
 		),
-	).WithArgs(bookID, authorID).WillReturnError(&errors.BookAuthorGenericError)
+	).WithArgs(bookID, authorID, models.ContributorAuthor, 0).WillReturnError(&errors.BookAuthorGenericError)
 	mock.ExpectRollback()
 
 	repository := repositories.NewBookAuthorRepository(gormDB)
-	err := repository.Create(&models.BookAuthor{BookID: bookID, AuthorID: authorID})
+	err := repository.Create(&models.BookAuthor{BookID: bookID, AuthorID: authorID, Role: models.ContributorAuthor})
 
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, &errors.BookAuthorGenericError)
@@ -77,12 +79,12 @@ func TestCreateBookAuthorReturnRelationshipAlreadyExists(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectExec(
-		regexp.QuoteMeta(`INSERT INTO "book_author" ("book_id","author_id") VALUES ($1,$2)`),
-	).WithArgs(bookID, authorID).WillReturnError(&errors.RelationshipAlreadyExists)
+		regexp.QuoteMeta(`INSERT INTO "book_author" ("book_id","author_id","role","position") VALUES ($1,$2,$3,$4)`),
+	).WithArgs(bookID, authorID, models.ContributorAuthor, 0).WillReturnError(&errors.RelationshipAlreadyExists)
 	mock.ExpectRollback()
 
 	repository := repositories.NewBookAuthorRepository(gormDB)
-	err := repository.Create(&models.BookAuthor{BookID: bookID, AuthorID: authorID})
+	err := repository.Create(&models.BookAuthor{BookID: bookID, AuthorID: authorID, Role: models.ContributorAuthor})
 
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, &errors.RelationshipAlreadyExists)
@@ -99,11 +101,16 @@ func TestDeleteBookAuthorSuccess(t *testing.T) {
 	bookID := uuid.New()
 
 	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "author_id"}).AddRow(bookID, uuid.New()))
 	mock.ExpectExec(
 		regexp.QuoteMeta(
 			`DELETE FROM "book_author" WHERE book_id = $1`,
 		),
 	).WithArgs(bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
 	mock.ExpectCommit()
 
 	repository := repositories.NewBookAuthorRepository(gormDB)
@@ -122,6 +129,9 @@ func TestDeleteBookAuthorReturnGenericError(t *testing.T) {
 	bookID := uuid.New()
 
 	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "author_id"}).AddRow(bookID, uuid.New()))
 	mock.ExpectExec(
 		regexp.QuoteMeta(`DELETE FROM "book_author" WHERE book_id = $1`),
 	).WithArgs(bookID).WillReturnError(&errors.BookAuthorGenericError)
@@ -133,3 +143,26 @@ func TestDeleteBookAuthorReturnGenericError(t *testing.T) {
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, &errors.BookAuthorGenericError)
 }
+
+func TestDeleteBookAuthorWithoutRelationships(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "author_id"}))
+	mock.ExpectCommit()
+
+	repository := repositories.NewBookAuthorRepository(gormDB)
+	err := repository.Delete(bookID)
+
+	assert.Nil(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
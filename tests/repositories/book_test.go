@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -12,7 +13,9 @@ import (
 	"github.com/joaooliveira247/go_olist_challenge/src/models"
 	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
 	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestCreateBookSuccess(t *testing.T) {
@@ -27,22 +30,25 @@ func TestCreateBookSuccess(t *testing.T) {
 
 	bookID := uuid.New()
 
+	mock.ExpectBegin()
+	expectWork(mock, book.Title)
 	mock.ExpectQuery(
 		regexp.QuoteMeta(
-			`SELECT * FROM "books" WHERE "books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3 ORDER BY "books"."id" LIMIT $4`,
+			`SELECT * FROM "books" WHERE ("books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3) AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $4`,
 		),
 	).WithArgs(book.Title, book.Edition, book.PublicationYear, 1).WillReturnRows(sqlmock.NewRows([]string{}))
-	mock.ExpectBegin()
 	mock.ExpectQuery(
 		regexp.QuoteMeta(
-			`INSERT INTO "books" ("title","edition","publication_year") VALUES ($1,$2,$3) RETURNING "id"`,
+			`INSERT INTO "books" ("title","edition","publication_year","isbn","publisher_id","work_id","language","translation_of_id","version","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "id"`,
 		),
-	).WithArgs(book.Title, book.Edition, book.PublicationYear).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(bookID))
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, nil, nil, sqlmock.AnyArg(), nil, nil, 1, nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(bookID))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
 	mock.ExpectCommit()
 
 	repository := repositories.NewBookRepository(gormDB)
 
-	id, err := repository.Create(book)
+	id, err := repository.Create(&models.BookIn{Book: *book})
 
 	assert.Nil(t, err)
 	assert.Equal(t, bookID, id)
@@ -60,14 +66,17 @@ func TestCreateBookReturnAlredyExists(t *testing.T) {
 
 	book := mocks.NewMockBook()
 
+	mock.ExpectBegin()
+	expectWork(mock, book.Title)
 	mock.ExpectQuery(
 		regexp.QuoteMeta(
-			`SELECT * FROM "books" WHERE "books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3 ORDER BY "books"."id" LIMIT $4`,
+			`SELECT * FROM "books" WHERE ("books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3) AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $4`,
 		),
 	).WithArgs(book.Title, book.Edition, book.PublicationYear, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year"}).AddRow(bookID, book.Title, book.Edition, book.PublicationYear))
+	mock.ExpectRollback()
 
 	repository := repositories.NewBookRepository(gormDB)
-	id, err := repository.Create(book)
+	id, err := repository.Create(&models.BookIn{Book: *book})
 
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, &errors.BookAlreadyExists)
@@ -84,17 +93,18 @@ func TestCreateBookReturnGenericError(t *testing.T) {
 
 	book := mocks.NewMockBook()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE "books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3 ORDER BY "books"."id" LIMIT $4`)).WithArgs(book.Title, book.Edition, book.PublicationYear, 1).WillReturnRows(sqlmock.NewRows([]string{}))
 	mock.ExpectBegin()
+	expectWork(mock, book.Title)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE ("books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3) AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $4`)).WithArgs(book.Title, book.Edition, book.PublicationYear, 1).WillReturnRows(sqlmock.NewRows([]string{}))
 	mock.ExpectQuery(
 		regexp.QuoteMeta(
-			`INSERT INTO "books" ("title","edition","publication_year") VALUES ($1,$2,$3) RETURNING "id"`,
+			`INSERT INTO "books" ("title","edition","publication_year","isbn","publisher_id","work_id","language","translation_of_id","version","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "id"`,
 		),
-	).WithArgs(book.Title, book.Edition, book.PublicationYear).WillReturnError(&errors.BookGenericError)
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, nil, nil, sqlmock.AnyArg(), nil, nil, 1, nil).WillReturnError(&errors.BookGenericError)
 	mock.ExpectRollback()
 
 	repository := repositories.NewBookRepository(gormDB)
-	id, err := repository.Create(book)
+	id, err := repository.Create(&models.BookIn{Book: *book})
 
 	assert.Error(t, err)
 	t.Log(err)
@@ -102,7 +112,7 @@ func TestCreateBookReturnGenericError(t *testing.T) {
 	assert.Equal(t, uuid.Nil, id)
 }
 
-func TestUpdateBookSuccess(t *testing.T) {
+func TestCreateBookNormalizesISBN(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -110,29 +120,39 @@ func TestUpdateBookSuccess(t *testing.T) {
 		db.Close()
 	}()
 
+	book := mocks.NewMockBook()
+	isbn10 := "1-59327-828-4"
+	book.ISBN = &isbn10
+
 	bookID := uuid.New()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "edition"=$1,"publication_year"=$2 WHERE id = $3`)).WithArgs(2, 2023, bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectWork(mock, book.Title)
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`SELECT * FROM "books" WHERE ("books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3) AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $4`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`INSERT INTO "books" ("title","edition","publication_year","isbn","publisher_id","work_id","language","translation_of_id","version","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "id"`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, "9781593278281", nil, sqlmock.AnyArg(), nil, nil, 1, nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(bookID))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
 	mock.ExpectCommit()
 
 	repository := repositories.NewBookRepository(gormDB)
-	err := repository.Update(bookID, &models.BookUpdate{
-		BookInfo: struct {
-			Title           string `json:"title,omitempty"`
-			Edition         uint8  `json:"edition,omitempty"`
-			PublicationYear uint   `json:"publication_year,omitempty"`
-		}{
-			Edition:         2,
-			PublicationYear: 2023,
-		},
-		AuthorsID: nil,
-	})
+
+	in := models.BookIn{Book: *book}
+	id, err := repository.Create(&in)
 
 	assert.Nil(t, err)
+	assert.Equal(t, bookID, id)
+	assert.Equal(t, "9781593278281", *in.ISBN)
 }
 
-func TestUpdateBookReturnNothingToUpdate(t *testing.T) {
+func TestCreateBookReturnAlreadyExistsWhenISBNTaken(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -140,30 +160,33 @@ func TestUpdateBookReturnNothingToUpdate(t *testing.T) {
 		db.Close()
 	}()
 
-	bookID := uuid.New()
+	book := mocks.NewMockBook()
+	isbn13 := "9781593278281"
+	book.ISBN = &isbn13
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "edition"=$1,"publication_year"=$2 WHERE id = $3`)).WithArgs(2, 2023, bookID).WillReturnResult(sqlmock.NewResult(0, 0))
-	mock.ExpectCommit()
+	expectWork(mock, book.Title)
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`SELECT * FROM "books" WHERE ("books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3) AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $4`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`INSERT INTO "books" ("title","edition","publication_year","isbn","publisher_id","work_id","language","translation_of_id","version","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "id"`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, isbn13, nil, sqlmock.AnyArg(), nil, nil, 1, nil).WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
 
 	repository := repositories.NewBookRepository(gormDB)
-	err := repository.Update(bookID, &models.BookUpdate{
-		BookInfo: struct {
-			Title           string `json:"title,omitempty"`
-			Edition         uint8  `json:"edition,omitempty"`
-			PublicationYear uint   `json:"publication_year,omitempty"`
-		}{
-			Edition:         2,
-			PublicationYear: 2023,
-		},
-		AuthorsID: nil,
-	})
 
-	assert.Error(t, err)
-	assert.ErrorIs(t, err, &errors.BookNothingToUpdate)
+	id, err := repository.Create(&models.BookIn{Book: *book})
+
+	assert.ErrorIs(t, err, &errors.BookAlreadyExists)
+	assert.Equal(t, uuid.Nil, id)
 }
 
-func TestUpdateBookReturnGenericError(t *testing.T) {
+func TestCreateBookReturnInvalidISBN(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -171,30 +194,22 @@ func TestUpdateBookReturnGenericError(t *testing.T) {
 		db.Close()
 	}()
 
-	bookID := uuid.New()
+	book := mocks.NewMockBook()
+	invalid := "978-1-59327-828-2"
+	book.ISBN = &invalid
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "edition"=$1,"publication_year"=$2 WHERE id = $3`)).WithArgs(2, 2023, bookID).WillReturnError(&errors.BookGenericError)
 	mock.ExpectRollback()
 
 	repository := repositories.NewBookRepository(gormDB)
-	err := repository.Update(bookID, &models.BookUpdate{
-		BookInfo: struct {
-			Title           string `json:"title,omitempty"`
-			Edition         uint8  `json:"edition,omitempty"`
-			PublicationYear uint   `json:"publication_year,omitempty"`
-		}{
-			Edition:         2,
-			PublicationYear: 2023,
-		},
-		AuthorsID: nil,
-	})
 
-	assert.Error(t, err)
-	assert.ErrorIs(t, err, &errors.BookGenericError)
+	id, err := repository.Create(&models.BookIn{Book: *book})
+
+	assert.ErrorIs(t, err, &errors.InvalidISBN)
+	assert.Equal(t, uuid.Nil, id)
 }
 
-func TestGetAllBooksSuccess(t *testing.T) {
+func TestCreateBookReturnPublisherNotFound(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -202,24 +217,33 @@ func TestGetAllBooksSuccess(t *testing.T) {
 		db.Close()
 	}()
 
-	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "authors"})
-
-	MBooks := mocks.NewMockBooks()
-
-	for _, book := range MBooks {
-		rows.AddRow(book.ID, book.Title, book.Edition, book.PublicationYear, book.AuthorsName)
-	}
+	book := mocks.NewMockBook()
+	publisherID := uuid.New()
+	book.PublisherID = &publisherID
 
-	mock.ExpectQuery(regexp.QuoteMeta(`select b.id, b.title, b.edition, b.publication_year, array_agg(a.name) as authors from book_author ba inner join books b on ba.book_id = b.id inner join authors a on ba.author_id = a.id group by b.id;`)).WillReturnRows(rows)
+	mock.ExpectBegin()
+	expectWork(mock, book.Title)
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`SELECT * FROM "books" WHERE ("books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3) AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $4`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`INSERT INTO "books" ("title","edition","publication_year","isbn","publisher_id","work_id","language","translation_of_id","version","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "id"`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, nil, publisherID, sqlmock.AnyArg(), nil, nil, 1, nil).WillReturnError(gorm.ErrForeignKeyViolated)
+	mock.ExpectRollback()
 
 	repository := repositories.NewBookRepository(gormDB)
-	books, err := repository.GetAll()
 
-	assert.Nil(t, err)
-	assert.Len(t, books, 4)
+	id, err := repository.Create(&models.BookIn{Book: *book})
+
+	assert.ErrorIs(t, err, &errors.PublisherNotFound)
+	assert.Equal(t, uuid.Nil, id)
 }
 
-func TestGetAllReturnGenericError(t *testing.T) {
+func TestCreateManyBooksSuccess(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -227,18 +251,43 @@ func TestGetAllReturnGenericError(t *testing.T) {
 		db.Close()
 	}()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`select b.id, b.title, b.edition, b.publication_year, array_agg(a.name) as authors from book_author ba inner join books b on ba.book_id = b.id inner join authors a on ba.author_id = a.id group by b.id;`)).WillReturnError(&errors.BookGenericError)
+	book := mocks.NewMockBook()
+
+	bookID := uuid.New()
+	authorID := uuid.New()
+
+	mock.ExpectBegin()
+	expectWork(mock, book.Title)
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`SELECT * FROM "books" WHERE ("books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3) AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $4`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`INSERT INTO "books" ("title","edition","publication_year","isbn","publisher_id","work_id","language","translation_of_id","version","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "id"`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, nil, nil, sqlmock.AnyArg(), nil, nil, 1, nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(bookID))
+	expectAudit(mock, 1)
+	mock.ExpectExec(
+		regexp.QuoteMeta(
+			`INSERT INTO "book_author" ("book_id","author_id","role","position") VALUES ($1,$2,$3,$4)`,
+		),
+	).WithArgs(bookID, authorID, models.ContributorAuthor, 0).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
 
 	repository := repositories.NewBookRepository(gormDB)
 
-	books, err := repository.GetAll()
+	ids, err := repository.CreateMany([]models.BookIn{{Book: *book, AuthorsID: []uuid.UUID{authorID}}})
 
-	assert.Nil(t, books)
-	assert.Error(t, err)
-	assert.ErrorIs(t, err, &errors.BookGenericError)
+	assert.Nil(t, err)
+	assert.Equal(t, []uuid.UUID{bookID}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestGetBookByOneQuerySuccess(t *testing.T) {
+func TestCreateManyBooksRollbackWhenAuthorNotFound(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -246,27 +295,72 @@ func TestGetBookByOneQuerySuccess(t *testing.T) {
 		db.Close()
 	}()
 
-	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "authors"})
+	book := mocks.NewMockBook()
 
-	MBooks := mocks.NewMockBooks()[2:]
+	bookID := uuid.New()
+	authorID := uuid.New()
 
-	for _, book := range MBooks {
-		rows.AddRow(book.ID, book.Title, book.Edition, book.PublicationYear, book.AuthorsName)
-	}
+	mock.ExpectBegin()
+	expectWork(mock, book.Title)
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`SELECT * FROM "books" WHERE ("books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3) AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $4`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`INSERT INTO "books" ("title","edition","publication_year","isbn","publisher_id","work_id","language","translation_of_id","version","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "id"`,
+		),
+	).WithArgs(book.Title, book.Edition, book.PublicationYear, nil, nil, sqlmock.AnyArg(), nil, nil, 1, nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(bookID))
+	expectAudit(mock, 1)
+	mock.ExpectExec(
+		regexp.QuoteMeta(
+			`INSERT INTO "book_author" ("book_id","author_id","role","position") VALUES ($1,$2,$3,$4)`,
+		),
+	).WithArgs(bookID, authorID, models.ContributorAuthor, 0).WillReturnError(gorm.ErrForeignKeyViolated)
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE b.title = 'Python Fluente' GROUP BY b.id;`)).WillReturnRows(rows)
+	ids, err := repository.CreateMany([]models.BookIn{{Book: *book, AuthorsID: []uuid.UUID{authorID}}})
 
-	query := dto.BookQueryParams{Title: "Python Fluente"}
+	assert.ErrorIs(t, err, &errors.AuthorNotFound)
+	assert.Nil(t, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateBookSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "edition"=$1,"publication_year"=$2,"version"=version + 1 WHERE id = $3 AND "books"."deleted_at" IS NULL`)).WithArgs(2, 2023, bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
 
 	repository := repositories.NewBookRepository(gormDB)
-	books, err := repository.GetBookByQuery(query.AsQuery())
+	err := repository.Update(bookID, &models.BookUpdate{
+		BookInfo: models.BookInfo{
+			Edition:         2,
+			PublicationYear: 2023,
+		},
+		AuthorsID: nil,
+	}, 0)
 
 	assert.Nil(t, err)
-	assert.Equal(t, MBooks, books)
-	assert.Len(t, books, 2)
 }
 
-func TestGetBookByQuerySuccess(t *testing.T) {
+func TestUpdateBookReplacesContributorsAndGenresInOneTransaction(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -274,23 +368,43 @@ func TestGetBookByQuerySuccess(t *testing.T) {
 		db.Close()
 	}()
 
-	MBook := mocks.NewMockBookOut()
-
-	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "authors"}).AddRow(MBook.ID, MBook.Title, MBook.Edition, MBook.PublicationYear, MBook.AuthorsName)
-
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE b.title = 'the Rust Programming Language' AND b.edition = 1 AND b.publication_year = 2018 GROUP BY b.id;`)).WillReturnRows(rows)
+	bookID, authorID, genreID := uuid.New(), uuid.New(), uuid.New()
 
-	query := dto.BookQueryParams{Title: MBook.Title, Edition: MBook.Edition, PublicationYear: MBook.PublicationYear}
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "version"=version + 1 WHERE id = $1 AND "books"."deleted_at" IS NULL`)).WithArgs(bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "author_id"}).AddRow(bookID, uuid.New()))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_author" ("book_id","author_id","role","position") VALUES ($1,$2,$3,$4)`)).
+		WithArgs(bookID, authorID, models.ContributorAuthor, 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_genre" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "genre_id"}))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_genre" ("book_id","genre_id") VALUES ($1,$2)`)).
+		WithArgs(bookID, genreID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
 
 	repository := repositories.NewBookRepository(gormDB)
-	book, err := repository.GetBookByQuery(query.AsQuery())
+	err := repository.Update(bookID, &models.BookUpdate{AuthorsID: []uuid.UUID{authorID}, GenresID: []uuid.UUID{genreID}}, 0)
 
 	assert.Nil(t, err)
-	assert.Len(t, book, 1)
-	assert.Equal(t, MBook, book[0])
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestGetBookByQueryReturnGenericError(t *testing.T) {
+func TestUpdateBookRollbackWhenAuthorNotFound(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -298,23 +412,34 @@ func TestGetBookByQueryReturnGenericError(t *testing.T) {
 		db.Close()
 	}()
 
-	query := dto.BookQueryParams{
-		Title:           "the Rust Programming Language",
-		Edition:         1,
-		PublicationYear: 2018,
-	}
+	bookID, authorID := uuid.New(), uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE b.title = 'the Rust Programming Language' AND b.edition = 1 AND b.publication_year = 2018 GROUP BY b.id;`))).WillReturnError(&errors.BookGenericError)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "version"=version + 1 WHERE id = $1 AND "books"."deleted_at" IS NULL`)).WithArgs(bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "author_id"}).AddRow(bookID, uuid.New()))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_author" ("book_id","author_id","role","position") VALUES ($1,$2,$3,$4)`)).
+		WithArgs(bookID, authorID, models.ContributorAuthor, 0).
+		WillReturnError(gorm.ErrForeignKeyViolated)
+	mock.ExpectRollback()
 
 	repository := repositories.NewBookRepository(gormDB)
-	book, err := repository.GetBookByQuery(query.AsQuery())
+	err := repository.Update(bookID, &models.BookUpdate{AuthorsID: []uuid.UUID{authorID}}, 0)
 
-	assert.Nil(t, book)
-	assert.Error(t, err)
-	assert.ErrorIs(t, err, &errors.BookGenericError)
+	assert.ErrorIs(t, err, &errors.AuthorNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestGetBookByIDSuccess(t *testing.T) {
+func TestUpdateBookReturnAlreadyExistsWhenISBNTaken(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -322,23 +447,23 @@ func TestGetBookByIDSuccess(t *testing.T) {
 		db.Close()
 	}()
 
-	Mbook := mocks.NewMockBookOut()
-
-	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "authors"}).AddRow(Mbook.Book.ID, Mbook.Book.Title, Mbook.Book.Edition, Mbook.Book.PublicationYear, Mbook.AuthorsName)
+	bookID := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE ba.book_id = $1 GROUP BY b.id ORDER BY b.id LIMIT 1;`,
-	)).WithArgs(Mbook.Book.ID).WillReturnRows(rows)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "isbn"=$1,"version"=version + 1 WHERE id = $2 AND "books"."deleted_at" IS NULL`)).WithArgs("9780306406157", bookID).WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
 
 	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Update(bookID, &models.BookUpdate{BookInfo: models.BookInfo{ISBN: "0-306-40615-2"}}, 0)
 
-	book, err := repository.GetBookByID(Mbook.Book.ID)
-
-	assert.Equal(t, Mbook, book)
-	assert.Nil(t, err)
+	assert.ErrorIs(t, err, &errors.BookAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestGetBookByIDReturnBookNotFound(t *testing.T) {
+func TestUpdateBookReturnNothingToUpdate(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -348,17 +473,26 @@ func TestGetBookByIDReturnBookNotFound(t *testing.T) {
 
 	bookID := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE ba.book_id = $1 GROUP BY b.id ORDER BY b.id LIMIT 1;`)).WithArgs(bookID).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}))
+	mock.ExpectRollback()
 
 	repository := repositories.NewBookRepository(gormDB)
-	book, err := repository.GetBookByID(bookID)
+	err := repository.Update(bookID, &models.BookUpdate{
+		BookInfo: models.BookInfo{
+			Edition:         2,
+			PublicationYear: 2023,
+		},
+		AuthorsID: nil,
+	}, 0)
 
-	assert.Empty(t, book)
 	assert.Error(t, err)
-	assert.ErrorIs(t, err, &errors.BookNotFound)
+	assert.ErrorIs(t, err, &errors.BookNothingToUpdate)
 }
 
-func TestGetBookByIDReturnGenericError(t *testing.T) {
+func TestUpdateBookReturnGenericError(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -368,20 +502,27 @@ func TestGetBookByIDReturnGenericError(t *testing.T) {
 
 	bookID := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE ba.book_id = $1 GROUP BY b.id ORDER BY b.id LIMIT 1;`,
-	)).WithArgs(bookID).WillReturnError(&errors.BookGenericError)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "edition"=$1,"publication_year"=$2,"version"=version + 1 WHERE id = $3 AND "books"."deleted_at" IS NULL`)).WithArgs(2, 2023, bookID).WillReturnError(&errors.BookGenericError)
+	mock.ExpectRollback()
 
 	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Update(bookID, &models.BookUpdate{
+		BookInfo: models.BookInfo{
+			Edition:         2,
+			PublicationYear: 2023,
+		},
+		AuthorsID: nil,
+	}, 0)
 
-	book, err := repository.GetBookByID(bookID)
-
-	assert.Empty(t, book)
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, &errors.BookGenericError)
 }
 
-func TestGetBooksByAuthorIDSuccess(t *testing.T) {
+func TestGetAllBooksSuccess(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -389,29 +530,24 @@ func TestGetBooksByAuthorIDSuccess(t *testing.T) {
 		db.Close()
 	}()
 
-	authorID := uuid.New()
-
-	MBooks := mocks.NewMockBooks()[2:]
+	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version", "authors"})
 
-	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "authors"})
+	MBooks := mocks.NewMockBooks()
 
 	for _, book := range MBooks {
-		rows.AddRow(book.Book.ID, book.Book.Title, book.Book.Edition, book.Book.PublicationYear, book.AuthorsName)
+		rows.AddRow(book.ID, book.Title, book.Edition, book.PublicationYear, book.Version, book.AuthorsName)
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id
-		INNER JOIN authors a ON ba.author_id = a.id WHERE ba.author_id = $1 GROUP BY b.id ORDER BY b.id;`)).WithArgs(authorID).WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id;`)).WillReturnRows(rows)
 
 	repository := repositories.NewBookRepository(gormDB)
+	books, err := repository.GetAll()
 
-	books, err := repository.GetBooksByAuthorID(authorID)
-
-	assert.Len(t, books, 2)
-	assert.Equal(t, MBooks, books)
 	assert.Nil(t, err)
+	assert.Len(t, books, 4)
 }
 
-func TestGetBooksByAuthorIDReturnGenericError(t *testing.T) {
+func TestEachBookStreamsMatchingBooks(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -419,22 +555,32 @@ func TestGetBooksByAuthorIDReturnGenericError(t *testing.T) {
 		db.Close()
 	}()
 
-	authorID := uuid.New()
+	isbn := "9781593278281"
+	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "isbn", "version", "authors"})
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, array_agg(a.name) AS authors FROM book_author ba INNER JOIN books b ON ba.book_id = b.id
-		INNER JOIN authors a ON ba.author_id = a.id WHERE ba.author_id = $1 GROUP BY b.id ORDER BY b.id;`)).WithArgs(authorID).WillReturnError(&errors.BookGenericError)
+	for _, book := range mocks.NewMockBooks() {
+		rows.AddRow(book.ID, book.Title, book.Edition, book.PublicationYear, isbn, book.Version, book.AuthorsName)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE b.language = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id;`)).
+		WithArgs("en").
+		WillReturnRows(rows)
 
 	repository := repositories.NewBookRepository(gormDB)
 
-	books, err := repository.GetBooksByAuthorID(authorID)
+	var books []models.BookOut
 
-	assert.Empty(t, books)
-	assert.Error(t, err)
-	assert.ErrorIs(t, err, &errors.BookGenericError)
+	err := repository.EachBook("b.language = ?", []interface{}{"en"}, func(book models.BookOut) error {
+		books = append(books, book)
+		return nil
+	})
 
+	assert.Nil(t, err)
+	assert.Len(t, books, 4)
+	assert.Equal(t, "1593278284", *books[0].ISBN10)
 }
 
-func TestDeleteBookSuccess(t *testing.T) {
+func TestEachBookByAuthorIDReturnGenericError(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -442,19 +588,23 @@ func TestDeleteBookSuccess(t *testing.T) {
 		db.Close()
 	}()
 
-	bookID := uuid.New()
+	authorID := uuid.New()
 
-	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "books" WHERE "books"."id" = $1`)).WithArgs(bookID).WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE ba.author_id = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id;`)).
+		WithArgs(authorID).
+		WillReturnError(&errors.BookGenericError)
 
 	repository := repositories.NewBookRepository(gormDB)
-	err := repository.Delete(bookID)
 
-	assert.Nil(t, err)
+	err := repository.EachBookByAuthorID(authorID, func(models.BookOut) error {
+		t.Fatal("no row expected")
+		return nil
+	})
+
+	assert.ErrorIs(t, err, &errors.BookGenericError)
 }
 
-func TestDeleteBookReturnNotFound(t *testing.T) {
+func TestGetAllReturnGenericError(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -462,19 +612,18 @@ func TestDeleteBookReturnNotFound(t *testing.T) {
 		db.Close()
 	}()
 
-	bookID := uuid.New()
-
-	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "books" WHERE "books"."id" = $1`)).WithArgs(bookID).WillReturnResult(sqlmock.NewResult(0, 0))
-	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id;`)).WillReturnError(&errors.BookGenericError)
 
 	repository := repositories.NewBookRepository(gormDB)
-	err := repository.Delete(bookID)
+
+	books, err := repository.GetAll()
+
+	assert.Nil(t, books)
 	assert.Error(t, err)
-	assert.ErrorIs(t, err, &errors.BookNotFound)
+	assert.ErrorIs(t, err, &errors.BookGenericError)
 }
 
-func TestDeleteBookReturnGenericError(t *testing.T) {
+func TestGetBookByOneQuerySuccess(t *testing.T) {
 	gormDB, mock := mocks.SetupMockDB()
 
 	defer func() {
@@ -482,16 +631,1101 @@ func TestDeleteBookReturnGenericError(t *testing.T) {
 		db.Close()
 	}()
 
-	bookID := uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version", "authors"})
 
-	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "books" WHERE "books"."id" = $1`)).WithArgs(bookID).WillReturnError(&errors.BookGenericError)
-	mock.ExpectRollback()
+	MBooks := mocks.NewMockBooks()[2:]
+
+	for _, book := range MBooks {
+		rows.AddRow(book.ID, book.Title, book.Edition, book.PublicationYear, book.Version, book.AuthorsName)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE b.title = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id;`)).WithArgs("Python Fluente").WillReturnRows(rows)
+
+	query := dto.BookQueryParams{Title: "Python Fluente"}
+
+	repository := repositories.NewBookRepository(gormDB)
+	books, err := repository.GetBookByQuery(query.AsQuery())
+
+	assert.Nil(t, err)
+	assert.Equal(t, MBooks, books)
+	assert.Len(t, books, 2)
+}
+
+func TestGetBookByQueryBindsQuotedTitle(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version", "authors"})
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE b.title = $1 AND b.deleted_at IS NULL`)).WithArgs("O'Reilly' OR '1'='1").WillReturnRows(rows)
+
+	query := dto.BookQueryParams{Title: "O'Reilly' OR '1'='1"}
+
+	repository := repositories.NewBookRepository(gormDB)
+	books, err := repository.GetBookByQuery(query.AsQuery())
+
+	assert.Nil(t, err)
+	assert.Empty(t, books)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBookByQuerySuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	MBook := mocks.NewMockBookOut()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version", "authors"}).AddRow(MBook.ID, MBook.Title, MBook.Edition, MBook.PublicationYear, MBook.Version, MBook.AuthorsName)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE b.title = $1 AND b.edition = $2 AND b.publication_year = $3 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id;`)).WithArgs(MBook.Title, MBook.Edition, MBook.PublicationYear).WillReturnRows(rows)
+
+	query := dto.BookQueryParams{Title: MBook.Title, Edition: MBook.Edition, PublicationYear: MBook.PublicationYear}
+
+	repository := repositories.NewBookRepository(gormDB)
+	book, err := repository.GetBookByQuery(query.AsQuery())
+
+	assert.Nil(t, err)
+	assert.Len(t, book, 1)
+	assert.Equal(t, MBook, book[0])
+}
+
+func TestGetBookByQueryReturnGenericError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	query := dto.BookQueryParams{
+		Title:           "the Rust Programming Language",
+		Edition:         1,
+		PublicationYear: 2018,
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE b.title = $1 AND b.edition = $2 AND b.publication_year = $3 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id;`))).WithArgs(query.Title, query.Edition, query.PublicationYear).WillReturnError(&errors.BookGenericError)
+
+	repository := repositories.NewBookRepository(gormDB)
+	book, err := repository.GetBookByQuery(query.AsQuery())
+
+	assert.Nil(t, book)
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, &errors.BookGenericError)
+}
+
+func TestGetBookByIDSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	Mbook := mocks.NewMockBookOut()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version", "authors"}).AddRow(Mbook.Book.ID, Mbook.Book.Title, Mbook.Book.Edition, Mbook.Book.PublicationYear, Mbook.Book.Version, Mbook.AuthorsName)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE ba.book_id = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id LIMIT 1;`,
+	)).WithArgs(Mbook.Book.ID).WillReturnRows(rows)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	book, err := repository.GetBookByID(Mbook.Book.ID)
+
+	assert.Equal(t, Mbook, book)
+	assert.Nil(t, err)
+}
+
+func TestGetBookByIDDerivesISBN10(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "isbn"}).AddRow(bookID, "Fluent Python", "9781491946008")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`AS contributors`)).WithArgs(bookID).WillReturnRows(rows)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	book, err := repository.GetBookByID(bookID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "9781491946008", *book.ISBN)
+	assert.Equal(t, "1491946008", *book.ISBN10)
+}
+
+func TestGetBookByIDReturnContributorsInOrder(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+	authorID := uuid.New()
+	translatorID := uuid.New()
+
+	contributors := fmt.Sprintf(
+		`[{"author_id": "%s", "name": "Gabriel García Márquez", "role": "author"}, {"author_id": "%s", "name": "Eric Nepomuceno", "role": "translator"}]`,
+		authorID, translatorID,
+	)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "authors", "contributors"}).
+		AddRow(bookID, "Cem Anos de Solidão", "{\"Gabriel García Márquez\",\"Eric Nepomuceno\"}", []byte(contributors))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`AS contributors`)).WithArgs(bookID).WillReturnRows(rows)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	book, err := repository.GetBookByID(bookID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, pq.StringArray{"Gabriel García Márquez", "Eric Nepomuceno"}, book.AuthorsName)
+	assert.Equal(t, models.ContributorsOut{
+		{AuthorID: authorID, Name: "Gabriel García Márquez", Role: models.ContributorAuthor},
+		{AuthorID: translatorID, Name: "Eric Nepomuceno", Role: models.ContributorTranslator},
+	}, book.Contributors)
+}
+
+func TestGetBookByIDReturnBookNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE ba.book_id = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id LIMIT 1;`)).WithArgs(bookID).WillReturnRows(sqlmock.NewRows([]string{}))
+
+	repository := repositories.NewBookRepository(gormDB)
+	book, err := repository.GetBookByID(bookID)
+
+	assert.Empty(t, book)
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, &errors.BookNotFound)
+}
+
+func TestGetBookByIDReturnGenericError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE ba.book_id = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id LIMIT 1;`,
+	)).WithArgs(bookID).WillReturnError(&errors.BookGenericError)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	book, err := repository.GetBookByID(bookID)
+
+	assert.Empty(t, book)
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, &errors.BookGenericError)
+}
+
+func TestGetBooksByAuthorIDSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	MBooks := mocks.NewMockBooks()[2:]
+
+	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version", "authors"})
+
+	for _, book := range MBooks {
+		rows.AddRow(book.Book.ID, book.Book.Title, book.Book.Edition, book.Book.PublicationYear, book.Book.Version, book.AuthorsName)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE ba.author_id = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id;`)).WithArgs(authorID).WillReturnRows(rows)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	books, err := repository.GetBooksByAuthorID(authorID)
+
+	assert.Len(t, books, 2)
+	assert.Equal(t, MBooks, books)
+	assert.Nil(t, err)
+}
+
+func TestGetBooksByAuthorIDReturnGenericError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE ba.author_id = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id;`)).WithArgs(authorID).WillReturnError(&errors.BookGenericError)
 
 	repository := repositories.NewBookRepository(gormDB)
 
-	err := repository.Delete(bookID)
+	books, err := repository.GetBooksByAuthorID(authorID)
 
+	assert.Empty(t, books)
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, &errors.BookGenericError)
+
+}
+
+func TestDeleteBookSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "deleted_at"=$1 WHERE "books"."id" = $2 AND "books"."deleted_at" IS NULL`)).WithArgs(sqlmock.AnyArg(), bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Delete(bookID, 0)
+
+	assert.Nil(t, err)
+}
+
+func TestDeleteBookReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Delete(bookID, 0)
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, &errors.BookNotFound)
+}
+
+func TestDeleteBookReturnGenericError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "deleted_at"=$1 WHERE "books"."id" = $2 AND "books"."deleted_at" IS NULL`)).WithArgs(sqlmock.AnyArg(), bookID).WillReturnError(&errors.BookGenericError)
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	err := repository.Delete(bookID, 0)
+
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, &errors.BookGenericError)
+}
+
+func TestUpdateBookWithVersionReturnVersionMismatch(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 4))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Update(bookID, &models.BookUpdate{BookInfo: models.BookInfo{Edition: 2}}, 3)
+
+	assert.ErrorIs(t, err, &errors.BookVersionMismatch)
+}
+
+func TestUpdateBookWithVersionReturnNothingToUpdateWhenMissing(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Update(bookID, &models.BookUpdate{BookInfo: models.BookInfo{Edition: 2}}, 3)
+
+	assert.ErrorIs(t, err, &errors.BookNothingToUpdate)
+}
+
+func TestDeleteBookWithVersionSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "deleted_at"=$1 WHERE "books"."id" = $2 AND "books"."deleted_at" IS NULL`)).WithArgs(sqlmock.AnyArg(), bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Delete(bookID, 2)
+
+	assert.Nil(t, err)
+}
+
+func TestDeleteBookWithVersionReturnVersionMismatch(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 3))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Delete(bookID, 2)
+
+	assert.ErrorIs(t, err, &errors.BookVersionMismatch)
+}
+
+func TestGetAllBooksUnscopedIncludeDeleted(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	book := mocks.NewMockBookOut()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version", "deleted_at", "authors"}).
+		AddRow(book.ID, book.Title, book.Edition, book.PublicationYear, book.Version, time.Now(), book.AuthorsName)
+
+	mock.ExpectQuery("^" + regexp.QuoteMeta(`SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id GROUP BY b.id;`) + "$").WillReturnRows(rows)
+
+	repository := repositories.NewBookRepository(gormDB).Unscoped()
+	books, err := repository.GetAll()
+
+	assert.Nil(t, err)
+	assert.Len(t, books, 1)
+	assert.True(t, books[0].DeletedAt.Valid)
+}
+
+func TestRestoreBookSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND deleted_at IS NOT NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "version", "deleted_at"}).AddRow(bookID, "Fluent Python", 2, time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "deleted_at"=$1,"version"=version + 1 WHERE id = $2`)).
+		WithArgs(nil, bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Restore(bookID)
+
+	assert.Nil(t, err)
+}
+
+func TestRestoreBookReturnAlreadyExists(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND deleted_at IS NOT NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "version", "deleted_at"}).AddRow(bookID, "Fluent Python", 2, time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "deleted_at"=$1,"version"=version + 1 WHERE id = $2`)).
+		WithArgs(nil, bookID).
+		WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Restore(bookID)
+
+	assert.ErrorIs(t, err, &errors.BookAlreadyExists)
+}
+
+func TestRestoreBookReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND deleted_at IS NOT NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "version", "deleted_at"}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+	err := repository.Restore(bookID)
+
+	assert.ErrorIs(t, err, &errors.BookNotFound)
+}
+
+func TestPurgeBooksSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	deletedBefore := time.Now().Add(-30 * 24 * time.Hour)
+
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE deleted_at < $1`)).
+		WithArgs(deletedBefore).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "title", "deleted_at"}).
+				AddRow(ids[0], "Fluent Python", deletedBefore.Add(-time.Hour)).
+				AddRow(ids[1], "Clean Code", deletedBefore.Add(-time.Hour)),
+		)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "books" WHERE "books"."id" IN ($1,$2)`)).
+		WithArgs(ids[0], ids[1]).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	expectAudit(mock, 2)
+	expectOutbox(mock, 2)
+	mock.ExpectCommit()
+
+	repository := repositories.NewBookRepository(gormDB)
+	purged, err := repository.Purge(deletedBefore)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), purged)
+}
+
+func TestUpdateBookSetsGenres(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+	genres := []uuid.UUID{uuid.New(), uuid.New()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockBook)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "version"=version + 1 WHERE id = $1 AND "books"."deleted_at" IS NULL`)).WithArgs(bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_genre" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "genre_id"}).AddRow(bookID, uuid.New()))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "book_genre" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_genre" ("book_id","genre_id") VALUES ($1,$2),($3,$4)`)).
+		WithArgs(bookID, genres[0], bookID, genres[1]).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	err := repository.Update(bookID, &models.BookUpdate{GenresID: []uuid.UUID{genres[0], genres[1], genres[0]}}, 0)
+
+	assert.Nil(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateBookReturnGenreNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+	genreID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockBook)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Fluent Python", 1, 2015, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "version"=version + 1 WHERE id = $1 AND "books"."deleted_at" IS NULL`)).WithArgs(bookID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_genre" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "genre_id"}))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_genre" ("book_id","genre_id") VALUES ($1,$2)`)).
+		WithArgs(bookID, genreID).
+		WillReturnError(gorm.ErrForeignKeyViolated)
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	err := repository.Update(bookID, &models.BookUpdate{GenresID: []uuid.UUID{genreID}}, 0)
+
+	assert.ErrorIs(t, err, &errors.GenreNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+const lockBook = `SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`
+
+func TestCreateEditionSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	sourceID, bookID, workID, authorID, genreID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockBook)).
+		WithArgs(sourceID, 1).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "work_id", "version"}).
+				AddRow(sourceID, "Fluent Python", 1, 2015, workID, 1),
+		)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "books" WHERE (work_id = $1 AND edition = $2) AND "books"."deleted_at" IS NULL`)).
+		WithArgs(workID, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "works" WHERE id = $1 ORDER BY "works"."id" LIMIT $2`)).
+		WithArgs(workID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(workID))
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`SELECT * FROM "books" WHERE ("books"."title" = $1 AND "books"."edition" = $2 AND "books"."publication_year" = $3) AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $4`,
+		),
+	).WithArgs("Fluent Python", 2, 2022, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectQuery(
+		regexp.QuoteMeta(
+			`INSERT INTO "books" ("title","edition","publication_year","isbn","publisher_id","work_id","language","translation_of_id","version","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "id"`,
+		),
+	).WithArgs("Fluent Python", 2, 2022, nil, nil, workID, nil, nil, 1, nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(bookID))
+	expectAudit(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_author" WHERE book_id = $1 ORDER BY position`)).
+		WithArgs(sourceID).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"book_id", "author_id", "role", "position"}).
+				AddRow(sourceID, authorID, models.ContributorAuthor, 0),
+		)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_author" ("book_id","author_id","role","position") VALUES ($1,$2,$3,$4)`)).
+		WithArgs(bookID, authorID, models.ContributorAuthor, 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_genre" WHERE book_id = $1`)).
+		WithArgs(sourceID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "genre_id"}).AddRow(sourceID, genreID))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_genre" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_genre" ("book_id","genre_id") VALUES ($1,$2)`)).
+		WithArgs(bookID, genreID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	id, err := repository.CreateEdition(sourceID, &models.EditionIn{Edition: 2, PublicationYear: 2022})
+
+	assert.Nil(t, err)
+	assert.Equal(t, bookID, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateEditionReturnEditionAlreadyExists(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	sourceID, workID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockBook)).
+		WithArgs(sourceID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "work_id"}).AddRow(sourceID, "Fluent Python", 1, workID))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "books" WHERE (work_id = $1 AND edition = $2) AND "books"."deleted_at" IS NULL`)).
+		WithArgs(workID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	id, err := repository.CreateEdition(sourceID, &models.EditionIn{Edition: 1, PublicationYear: 2015})
+
+	assert.ErrorIs(t, err, &errors.EditionAlreadyExists)
+	assert.Equal(t, uuid.Nil, id)
+}
+
+func TestCreateEditionReturnBookNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	sourceID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockBook)).WithArgs(sourceID, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	_, err := repository.CreateEdition(sourceID, &models.EditionIn{Edition: 2, PublicationYear: 2022})
+
+	assert.ErrorIs(t, err, &errors.BookNotFound)
+}
+
+func TestCreateBookReturnWorkNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	book := mocks.NewMockBook()
+	workID := uuid.New()
+	book.WorkID = &workID
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "works" WHERE id = $1 ORDER BY "works"."id" LIMIT $2`)).
+		WithArgs(workID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	_, err := repository.Create(&models.BookIn{Book: *book})
+
+	assert.ErrorIs(t, err, &errors.WorkNotFound)
+}
+
+func TestGetBooksByIDsSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	first, second := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE b.id IN ($1,$2) AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id;`)).
+		WithArgs(first, second).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "title", "authors"}).
+				AddRow(first, "Fluent Python", pq.StringArray{"Luciano Ramalho"}).
+				AddRow(second, "Python Fluente", pq.StringArray{"Luciano Ramalho"}),
+		)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	books, err := repository.GetBooksByIDs([]uuid.UUID{first, second})
+
+	assert.Nil(t, err)
+	assert.Len(t, books, 2)
+	assert.Equal(t, second, books[1].ID)
+}
+
+func TestGetBooksPageByAuthorIDsKeepsEveryContributor(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	ramalho, slatkin, nobody := uuid.New(), uuid.New(), uuid.New()
+	fluent, fluente, effective := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WITH authored AS (SELECT DISTINCT ba.author_id, ba.book_id FROM book_author ba INNER JOIN books b ON ba.book_id = b.id WHERE ba.author_id IN ($1,$2,$3) AND b.deleted_at IS NULL) SELECT author_id, count(*) AS total FROM authored GROUP BY author_id`)).
+		WithArgs(ramalho, slatkin, nobody).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "total"}).AddRow(ramalho, 3).AddRow(slatkin, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`row_number() OVER (PARTITION BY author_id ORDER BY book_id) AS position FROM authored) ranked WHERE position > $4 AND position <= $5 + $6 ORDER BY author_id, position`)).
+		WithArgs(ramalho, slatkin, nobody, 2, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "book_id"}).AddRow(ramalho, fluent).AddRow(ramalho, fluente).AddRow(slatkin, effective))
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE b.id IN ($1,$2,$3) AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id;`)).
+		WithArgs(fluent, fluente, effective).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "title", "authors", "contributors"}).
+				AddRow(fluent, "Fluent Python", pq.StringArray{"Luciano Ramalho", "Someone Else"}, fmt.Sprintf(
+					`[{"author_id": "%s", "name": "Luciano Ramalho", "role": "author"}, {"author_id": "%s", "name": "Someone Else", "role": "editor"}]`,
+					ramalho, uuid.New(),
+				)).
+				AddRow(fluente, "Python Fluente", pq.StringArray{"Luciano Ramalho"}, "[]").
+				AddRow(effective, "Effective Python", pq.StringArray{"Brett Slatkin"}, "[]"),
+		)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	pages, err := repository.GetBooksPageByAuthorIDs([]uuid.UUID{ramalho, slatkin, nobody}, 2, 2)
+
+	assert.Nil(t, err)
+	assert.Len(t, pages, 3)
+	assert.Equal(t, int64(3), pages[ramalho].Total)
+	assert.Len(t, pages[ramalho].Books, 2)
+	assert.Len(t, pages[ramalho].Books[0].Contributors, 2)
+	assert.Equal(t, ramalho, pages[ramalho].Books[0].Contributors[0].AuthorID)
+	assert.Equal(t, int64(1), pages[slatkin].Total)
+	assert.Equal(t, effective, pages[slatkin].Books[0].ID)
+	assert.Equal(t, int64(0), pages[nobody].Total)
+	assert.NotNil(t, pages[nobody].Books)
+	assert.Empty(t, pages[nobody].Books)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBooksPageByAuthorIDsWithoutBooks(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT author_id, count(*) AS total FROM authored GROUP BY author_id`)).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "total"}))
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	pages, err := repository.GetBooksPageByAuthorIDs([]uuid.UUID{authorID}, 1, 20)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), pages[authorID].Total)
+	assert.Empty(t, pages[authorID].Books)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBooksPageByAuthorIDsReturnGenericError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WITH authored AS`)).
+		WillReturnError(&errors.BookGenericError)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	pages, err := repository.GetBooksPageByAuthorIDs([]uuid.UUID{uuid.New()}, 1, 20)
+
+	assert.Nil(t, pages)
+	assert.ErrorIs(t, err, &errors.BookGenericError)
+}
+
+func TestGetBooksPageSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM (SELECT b.id, b.title`) + `.*` + regexp.QuoteMeta(`WHERE b.language = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id) books`)).
+		WithArgs("pt").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE b.language = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id LIMIT $2 OFFSET $3;`)).
+		WithArgs("pt", 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "isbn"}).AddRow(bookID, "Python Fluente", "9788575224625"))
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	books, total, err := repository.GetBooksPage("b.language = ?", []interface{}{"pt"}, 3, 10)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(21), total)
+	assert.Len(t, books, 1)
+	assert.Equal(t, bookID, books[0].ID)
+	assert.NotNil(t, books[0].ISBN10)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBooksPageWithoutBooks(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id) books`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	books, total, err := repository.GetBooksPage("", nil, 1, 20)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.NotNil(t, books)
+	assert.Empty(t, books)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBooksPageReturnGenericError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM`)).
+		WillReturnError(&errors.BookGenericError)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	books, total, err := repository.GetBooksPage("", nil, 1, 20)
+
+	assert.Nil(t, books)
+	assert.Equal(t, int64(0), total)
+	assert.ErrorIs(t, err, &errors.BookGenericError)
+}
+
+func TestGetBooksByWorkIDSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	workID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE b.work_id = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.edition, b.publication_year;`)).
+		WithArgs(workID).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "title", "edition", "work_id", "authors"}).
+				AddRow(uuid.New(), "Fluent Python", 1, workID, pq.StringArray{"Luciano Ramalho"}).
+				AddRow(uuid.New(), "Fluent Python", 2, workID, pq.StringArray{"Luciano Ramalho"}),
+		)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	books, err := repository.GetBooksByWorkID(workID)
+
+	assert.Nil(t, err)
+	assert.Len(t, books, 2)
+	assert.Equal(t, uint8(2), books[1].Edition)
+	assert.Equal(t, workID, *books[1].WorkID)
+}
+
+func TestCreateBookReturnOriginalBookNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	book := mocks.NewMockBook()
+	originalID := uuid.New()
+	language := "PT"
+	book.Language = &language
+	book.TranslationOfID = &originalID
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2`)).
+		WithArgs(originalID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	in := models.BookIn{Book: *book}
+	_, err := repository.Create(&in)
+
+	assert.ErrorIs(t, err, &errors.OriginalBookNotFound)
+	assert.Equal(t, "pt", *in.Language)
+}
+
+func TestUpdateBookLanguageAndTranslation(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID, originalID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockBook)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version"}).AddRow(bookID, "Python Fluente", 1, 2015, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2`)).
+		WithArgs(originalID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(originalID))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "language"=$1,"translation_of_id"=$2,"version"=version + 1 WHERE id = $3 AND "books"."deleted_at" IS NULL`)).
+		WithArgs("pt", originalID, bookID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	err := repository.Update(bookID, &models.BookUpdate{
+		BookInfo: models.BookInfo{Language: "PT", TranslationOfID: originalID},
+	}, 0)
+
+	assert.Nil(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateBookReturnInvalidTranslation(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockBook)).
+		WithArgs(bookID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "version"}).AddRow(bookID, "Python Fluente", 1))
+	mock.ExpectRollback()
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	err := repository.Update(bookID, &models.BookUpdate{BookInfo: models.BookInfo{TranslationOfID: bookID}}, 0)
+
+	assert.ErrorIs(t, err, &errors.InvalidTranslation)
+}
+
+func TestGetTranslationsSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	originalID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE b.translation_of_id = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.language, b.publication_year;`)).
+		WithArgs(originalID).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "title", "language", "translation_of_id", "authors"}).
+				AddRow(uuid.New(), "Python Fluente", "pt", originalID, pq.StringArray{"Luciano Ramalho"}),
+		)
+
+	repository := repositories.NewBookRepository(gormDB)
+
+	books, err := repository.GetTranslations(originalID)
+
+	assert.Nil(t, err)
+	assert.Len(t, books, 1)
+	assert.Equal(t, "pt", *books[0].Language)
+	assert.Equal(t, originalID, *books[0].TranslationOfID)
 }
@@ -0,0 +1,281 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/cache"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const getAuthor = `SELECT * FROM "authors" WHERE id = $1 AND "authors"."deleted_at" IS NULL ORDER BY "authors"."id" LIMIT $2`
+
+const getBook = `SELECT b.id, b.title, b.edition, b.publication_year, b.isbn, b.publisher_id, b.work_id, b.language, b.translation_of_id, b.version, b.deleted_at, array_agg(a.name ORDER BY ba.position, a.name) AS authors, json_agg(json_build_object('author_id', a.id, 'name', a.name, 'role', ba.role) ORDER BY ba.position, a.name) AS contributors, ARRAY(SELECT g.name FROM book_genre bg INNER JOIN genres g ON bg.genre_id = g.id WHERE bg.book_id = b.id ORDER BY g.name) AS genres FROM book_author ba INNER JOIN books b ON ba.book_id = b.id INNER JOIN authors a ON ba.author_id = a.id WHERE ba.book_id = $1 AND b.deleted_at IS NULL AND a.deleted_at IS NULL GROUP BY b.id ORDER BY b.id LIMIT 1;`
+
+func expectGetAuthor(mock sqlmock.Sqlmock, id uuid.UUID, name string) {
+	mock.ExpectQuery(regexp.QuoteMeta(getAuthor)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id, name))
+}
+
+func expectGetBook(mock sqlmock.Sqlmock, id uuid.UUID, authorID uuid.UUID, author string) {
+	mock.ExpectQuery(regexp.QuoteMeta(getBook)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "contributors"}).
+			AddRow(id, "Capitães da Areia", fmt.Sprintf(`[{"author_id": "%s", "name": "%s", "role": "author"}]`, authorID, author)))
+}
+
+func TestCachedAuthorReadsHitTheDatabaseOnce(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+	repository := repositories.NewCachedAuthorRepository(repositories.NewAuthorRepository(gormDB), cache.Shared())
+
+	expectGetAuthor(mock, id, "Jorge Amado")
+
+	first, err := repository.WithContext(context.Background()).GetByID(id)
+	assert.NoError(t, err)
+
+	second, err := repository.WithContext(context.Background()).GetByID(id)
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthorUpdateInvalidatesTheAuthorAndItsBooks(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID, bookID := uuid.New(), uuid.New()
+	authors := repositories.NewCachedAuthorRepository(repositories.NewAuthorRepository(gormDB), cache.Shared())
+	books := repositories.NewCachedBookRepository(repositories.NewBookRepository(gormDB), cache.Shared())
+
+	expectGetAuthor(mock, authorID, "Jorge Amado")
+	expectGetBook(mock, bookID, authorID, "Jorge Amado")
+
+	authors.GetByID(authorID)
+	books.GetBookByID(bookID)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockAuthor)).
+		WithArgs(authorID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(authorID, "Jorge Amado"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "authors" SET "name"=$1 WHERE id = $2 AND "authors"."deleted_at" IS NULL`)).
+		WithArgs("Jorge Leal Amado", authorID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	expectGetAuthor(mock, authorID, "Jorge Leal Amado")
+	expectGetBook(mock, bookID, authorID, "Jorge Leal Amado")
+
+	assert.NoError(t, authors.Update(authorID, &models.AuthorUpdate{Name: "Jorge Leal Amado"}))
+
+	author, err := authors.GetByID(authorID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Jorge Leal Amado", author.Name)
+
+	book, err := books.GetBookByID(bookID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Jorge Leal Amado", book.Contributors[0].Name)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRolledBackWriteKeepsCachedEntries(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+	repository := repositories.NewCachedAuthorRepository(repositories.NewAuthorRepository(gormDB), cache.Shared())
+
+	expectGetAuthor(mock, id, "Jorge Amado")
+
+	repository.GetByID(id)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockAuthor)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id, "Jorge Amado"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "authors" SET "name"=$1 WHERE id = $2 AND "authors"."deleted_at" IS NULL`)).
+		WithArgs("Luciano Ramalho", id).
+		WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
+
+	err := repository.Update(id, &models.AuthorUpdate{Name: "Luciano Ramalho"})
+	assert.ErrorIs(t, err, &errors.AuthorAlreadyExists)
+
+	author, err := repository.GetByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Jorge Amado", author.Name)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookAuthorChangeInvalidatesTheBook(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID, authorID := uuid.New(), uuid.New()
+	books := repositories.NewCachedBookRepository(repositories.NewBookRepository(gormDB), cache.Shared())
+
+	expectGetBook(mock, bookID, authorID, "Jorge Amado")
+
+	books.GetBookByID(bookID)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "author_id"}).AddRow(bookID, authorID))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	expectOutbox(mock, 1)
+	mock.ExpectCommit()
+
+	expectGetBook(mock, bookID, authorID, "Jorge Amado")
+
+	assert.NoError(t, repositories.NewBookAuthorRepository(gormDB).Delete(bookID))
+
+	_, err := books.GetBookByID(bookID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCachedBooksShareNothingWithTheCache(t *testing.T) {
+	isbn := "9788535914061"
+	stored := []models.BookOut{{
+		Book:         models.Book{ID: uuid.New(), Title: "Capitães da Areia", ISBN: &isbn},
+		AuthorsName:  pq.StringArray{"Jorge Amado"},
+		Contributors: models.ContributorsOut{{AuthorID: uuid.New(), Name: "Jorge Amado", Role: "author"}},
+		GenresName:   pq.StringArray{"Romance"},
+	}}
+
+	repository := new(mocks.BookRepository)
+	repository.On("GetAll").Return(stored, nil).Once()
+
+	books := repositories.NewCachedBookRepository(repository, cache.New(time.Minute, 10, time.Now))
+
+	first, err := books.GetAll()
+	assert.NoError(t, err)
+
+	*first[0].ISBN = "0000000000000"
+	first[0].AuthorsName[0] = "changed"
+	first[0].Contributors[0].Name = "changed"
+	first[0].GenresName[0] = "changed"
+
+	second, err := books.GetAll()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "9788535914061", *second[0].ISBN)
+	assert.Equal(t, pq.StringArray{"Jorge Amado"}, second[0].AuthorsName)
+	assert.Equal(t, "Jorge Amado", second[0].Contributors[0].Name)
+	assert.Equal(t, pq.StringArray{"Romance"}, second[0].GenresName)
+	repository.AssertExpectations(t)
+}
+
+func TestCachedBookPagesShareNothingWithTheCache(t *testing.T) {
+	authorID := uuid.New()
+	book := models.BookOut{
+		Book:        models.Book{ID: uuid.New(), Title: "Capitães da Areia"},
+		AuthorsName: pq.StringArray{"Jorge Amado"},
+	}
+
+	repository := new(mocks.BookRepository)
+	repository.On("GetBooksPage", "", []interface{}(nil), 2, 1).Return([]models.BookOut{book}, int64(3), nil).Once()
+	repository.On("GetBooksPageByAuthorIDs", []uuid.UUID{authorID}, 1, 20).Return(map[uuid.UUID]models.BookPage{
+		authorID: {Books: []models.BookOut{book}, Total: 1},
+	}, nil).Once()
+
+	books := repositories.NewCachedBookRepository(repository, cache.New(time.Minute, 10, time.Now))
+
+	first, total, err := books.GetBooksPage("", nil, 2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	first[0].AuthorsName[0] = "changed"
+
+	second, total, err := books.GetBooksPage("", nil, 2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, pq.StringArray{"Jorge Amado"}, second[0].AuthorsName)
+
+	pages, err := books.GetBooksPageByAuthorIDs([]uuid.UUID{authorID}, 1, 20)
+	assert.NoError(t, err)
+	pages[authorID].Books[0].AuthorsName[0] = "changed"
+
+	pages, err = books.GetBooksPageByAuthorIDs([]uuid.UUID{authorID}, 1, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pages[authorID].Total)
+	assert.Equal(t, pq.StringArray{"Jorge Amado"}, pages[authorID].Books[0].AuthorsName)
+	repository.AssertExpectations(t)
+}
+
+func TestCachedReadsBypassedByContext(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+	repository := repositories.NewCachedAuthorRepository(repositories.NewAuthorRepository(gormDB), cache.Shared()).
+		WithContext(cache.WithBypass(context.Background()))
+
+	expectGetAuthor(mock, id, "Jorge Amado")
+	expectGetAuthor(mock, id, "Jorge Amado")
+
+	repository.GetByID(id)
+	repository.GetByID(id)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnscopedReadsSkipTheCache(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+	repository := repositories.NewCachedAuthorRepository(repositories.NewAuthorRepository(gormDB), cache.Shared()).Unscoped()
+
+	for range 2 {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 ORDER BY "authors"."id" LIMIT $2`)).
+			WithArgs(id, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id, "Jorge Amado"))
+	}
+
+	repository.GetByID(id)
+	repository.GetByID(id)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
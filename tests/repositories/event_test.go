@@ -0,0 +1,69 @@
+package repositories_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEventsAfter(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox" WHERE id > $1 ORDER BY id LIMIT $2`)).
+		WithArgs(3, 500).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow(4, models.EventAuthorCreated).AddRow(5, models.EventBookCreated))
+
+	events, err := repositories.NewEventRepository(gormDB).GetAfter(3, 0, nil, 500)
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, uint64(5), events[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetEventsAfterUntilForEntities(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox" WHERE id > $1 AND id <= $2 AND split_part(type, '.', 1) IN ($3) ORDER BY id LIMIT $4`)).
+		WithArgs(3, 9, "book", 500).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}))
+
+	events, err := repositories.NewEventRepository(gormDB).GetAfter(3, 9, []string{"book"}, 500)
+
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLastEventID(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT coalesce(max(id), 0) FROM "outbox"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(42))
+
+	last, err := repositories.NewEventRepository(gormDB).LastID()
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), last)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
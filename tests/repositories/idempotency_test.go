@@ -0,0 +1,112 @@
+package repositories_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	key := &models.IdempotencyKey{Client: "client", Key: "retry-me", RequestHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "idempotency_keys" WHERE client = $1 AND key = $2 AND expires_at <= $3`)).
+		WithArgs("client", "retry-me", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "idempotency_keys" ("client","key","request_hash","status_code","content_type","body","created_at","expires_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) ON CONFLICT DO NOTHING`)).
+		WithArgs("client", "retry-me", "hash", 0, "", sqlmock.AnyArg(), sqlmock.AnyArg(), key.ExpiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	reserved, ok, err := repositories.NewIdempotencyRepository(gormDB).Reserve(key)
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, key, reserved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveIdempotencyKeyTaken(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	key := &models.IdempotencyKey{Client: "client", Key: "retry-me", RequestHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "idempotency_keys"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "idempotency_keys"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "idempotency_keys" WHERE client = $1 AND key = $2 ORDER BY "idempotency_keys"."client" LIMIT $3`)).
+		WithArgs("client", "retry-me", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"client", "key", "request_hash", "status_code", "content_type", "body"}).
+			AddRow("client", "retry-me", "hash", 201, "application/json", []byte(`{"id": 1}`)))
+
+	existing, ok, err := repositories.NewIdempotencyRepository(gormDB).Reserve(key)
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 201, existing.StatusCode)
+	assert.Equal(t, `{"id": 1}`, string(existing.Body))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompleteIdempotencyKey(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "idempotency_keys" SET "body"=$1,"content_type"=$2,"status_code"=$3 WHERE client = $4 AND key = $5`)).
+		WithArgs([]byte(`{"id": 1}`), "application/json", 201, "client", "retry-me").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repositories.NewIdempotencyRepository(gormDB).Complete("client", "retry-me", 201, "application/json", []byte(`{"id": 1}`))
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReleaseIdempotencyKey(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "idempotency_keys" WHERE client = $1 AND key = $2 AND status_code = 0`)).
+		WithArgs("client", "retry-me").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repositories.NewIdempotencyRepository(gormDB).Release("client", "retry-me")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,279 @@
+package repositories_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const lockPublisher = `SELECT * FROM "publishers" WHERE id = $1 ORDER BY "publishers"."id" LIMIT $2 FOR UPDATE`
+
+func TestNamedCreateSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	publisherID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "publishers" ("name") VALUES ($1) RETURNING "id"`)).
+		WithArgs("O'Reilly Media").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(publisherID))
+	expectAudit(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	id, err := repository.Create(&models.Publisher{Name: "O'Reilly Media"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, publisherID, id)
+}
+
+func TestNamedCreateAlreadyExists(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "publishers" ("name") VALUES ($1) RETURNING "id"`)).
+		WithArgs("O'Reilly Media").
+		WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	id, err := repository.Create(&models.Publisher{Name: "O'Reilly Media"})
+
+	assert.ErrorIs(t, err, &errors.PublisherAlreadyExists)
+	assert.Equal(t, uuid.Nil, id)
+}
+
+func TestNamedGetAllSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	publishers := []models.Publisher{
+		{ID: uuid.New(), Name: "Novatec"},
+		{ID: uuid.New(), Name: "O'Reilly Media"},
+	}
+
+	mock.ExpectQuery("^" + regexp.QuoteMeta(`SELECT * FROM "publishers" ORDER BY name`) + "$").
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "name"}).
+				AddRow(publishers[0].ID, publishers[0].Name).
+				AddRow(publishers[1].ID, publishers[1].Name),
+		)
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	found, err := repository.GetAll()
+
+	assert.Nil(t, err)
+	assert.Equal(t, publishers, found)
+}
+
+func TestNamedGetByNameSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	publisher := models.Publisher{ID: uuid.New(), Name: "Editora Três Estrelas"}
+
+	mock.ExpectQuery(
+		"^" + regexp.QuoteMeta(`SELECT * FROM "publishers" WHERE immutable_unaccent(lower(name)) LIKE immutable_unaccent(lower($1)) ORDER BY name`) + "$",
+	).WithArgs("%tres%").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(publisher.ID, publisher.Name))
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	found, err := repository.GetByName("tres")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []models.Publisher{publisher}, found)
+}
+
+func TestNamedGetByIDReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "publishers" WHERE id = $1 ORDER BY "publishers"."id" LIMIT $2`)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	_, err := repository.GetByID(id)
+
+	assert.ErrorIs(t, err, &errors.PublisherNotFound)
+}
+
+func TestNamedUpdateSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockPublisher)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id, "OReilly"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "publishers" SET "name"=$1 WHERE id = $2`)).
+		WithArgs("O'Reilly Media", id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	err := repository.Update(id, &models.Publisher{Name: "O'Reilly Media"})
+
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestNamedUpdateReturnAlreadyExists(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockPublisher)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id, "OReilly"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "publishers" SET "name"=$1 WHERE id = $2`)).
+		WithArgs("Novatec", id).
+		WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	err := repository.Update(id, &models.Publisher{Name: "Novatec"})
+
+	assert.ErrorIs(t, err, &errors.PublisherAlreadyExists)
+}
+
+func TestNamedUpdateReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockPublisher)).WithArgs(id, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	err := repository.Update(id, &models.Publisher{Name: "Novatec"})
+
+	assert.ErrorIs(t, err, &errors.PublisherNotFound)
+}
+
+func TestNamedDeleteSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockPublisher)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id, "Novatec"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "publishers" WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	mock.ExpectCommit()
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	err := repository.Delete(id)
+
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestNamedDeleteReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockPublisher)).WithArgs(id, 1).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectRollback()
+
+	repository := repositories.NewPublisherRepository(gormDB)
+	err := repository.Delete(id)
+
+	assert.ErrorIs(t, err, &errors.PublisherNotFound)
+}
+
+func TestGenreRepositoryNamesGenres(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "genres" ("name") VALUES ($1) RETURNING "id"`)).
+		WithArgs("Fantasy").
+		WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "genres" WHERE id = $1 ORDER BY "genres"."id" LIMIT $2`)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	repository := repositories.NewGenreRepository(gormDB)
+
+	_, err := repository.Create(&models.Genre{Name: "Fantasy"})
+	assert.ErrorIs(t, err, &errors.GenreAlreadyExists)
+
+	_, err = repository.GetByID(id)
+	assert.ErrorIs(t, err, &errors.GenreNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,124 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const insertOutbox = `INSERT INTO "outbox" ("type","entity_id","payload","created_at","dispatched_at") VALUES`
+
+func expectOutbox(mock sqlmock.Sqlmock, events int) {
+	rows := sqlmock.NewRows([]string{"id"})
+
+	for i := range events {
+		rows.AddRow(uint64(i + 1))
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(insertOutbox)).WillReturnRows(rows)
+}
+
+// changeOf matches the payload of an event about a change to entity.
+type changeOf string
+
+func (entity changeOf) Match(value driver.Value) bool {
+	var data models.EventData
+
+	switch payload := value.(type) {
+	case string:
+		if err := json.Unmarshal([]byte(payload), &data); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+
+	return data.Entity == string(entity)
+}
+
+func TestCreateManyBooksPublishesOneEventPerBook(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	book := mocks.NewMockBook()
+
+	bookID := uuid.New()
+	authorID := uuid.New()
+
+	mock.ExpectBegin()
+	expectWork(mock, book.Title)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE`)).WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "books"`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(bookID))
+	expectAudit(mock, 1)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_author"`)).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAudit(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(insertOutbox+` ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs(models.EventBookCreated, bookID, changeOf(audit.EntityBook), sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	_, err := repositories.NewBookRepository(gormDB).CreateMany([]models.BookIn{{Book: *book, AuthorsID: []uuid.UUID{authorID}}})
+
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestBookAuthorChangePublishesBookUpdated(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID, authorID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "author_id"}).AddRow(bookID, authorID))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "book_author" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(mock, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(insertOutbox)).
+		WithArgs(models.EventBookUpdated, bookID, changeOf(audit.EntityBookAuthor), sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repositories.NewBookAuthorRepository(gormDB).WithContext(context.Background()).Delete(bookID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPublisherChangePublishesNoEvent(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "publishers"`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	expectAudit(mock, 1)
+	mock.ExpectCommit()
+
+	_, err := repositories.NewPublisherRepository(gormDB).Create(&models.Publisher{Name: "Novatec"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
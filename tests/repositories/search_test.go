@@ -0,0 +1,114 @@
+package repositories_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchBooksSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	bookID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM (SELECT b.id, b.title`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+	mock.ExpectQuery(regexp.QuoteMeta(`WITH q AS (SELECT english, portuguese, english || portuguese AS query FROM (SELECT websearch_to_tsquery('english', $1) AS english, websearch_to_tsquery('portuguese', $2) AS portuguese) parsed)`)+`.*`+
+		regexp.QuoteMeta(`ts_headline('portuguese', ts_headline('english', replace(replace(replace(b.title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.english, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'), q.portuguese, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>')`)+`.*`+
+		regexp.QuoteMeta(`GROUP BY b.id, q.english, q.portuguese, q.query ORDER BY rank DESC, b.title, b.id LIMIT $3 OFFSET $4`)).
+		WithArgs("python", "python", 10, 20).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "title", "edition", "publication_year", "version", "authors", "rank", "title_highlight", "authors_highlight"}).
+				AddRow(bookID, "Fluent Python", 2, 2022, 1, pq.StringArray{"Luciano Ramalho"}, 0.6, "Fluent <mark>Python</mark>", "Luciano Ramalho"),
+		)
+
+	repository := repositories.NewSearchRepository(gormDB)
+	results, total, err := repository.SearchBooks("python", 3, 10)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(21), total)
+	assert.Len(t, results, 1)
+	assert.Equal(t, bookID, results[0].ID)
+	assert.Equal(t, "Fluent <mark>Python</mark>", results[0].TitleHighlight)
+	assert.Equal(t, []string{"Luciano Ramalho"}, []string(results[0].AuthorsName))
+	assert.InDelta(t, 0.6, results[0].Rank, 0.0001)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchBooksEscapesHighlightedText(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM (SELECT b.id, b.title`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`ts_headline('english', replace(replace(replace(b.title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.english`) + `.*` +
+		regexp.QuoteMeta(`ts_headline('english', replace(replace(replace(string_agg(a.name, ', ' ORDER BY ba.position, a.name), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.english`)).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "title", "title_highlight", "authors_highlight"}).
+				AddRow(uuid.New(), "<script>Python</script>", "&lt;script&gt;<mark>Python</mark>&lt;/script&gt;", "Tom &amp; Jerry"),
+		)
+
+	repository := repositories.NewSearchRepository(gormDB)
+	results, _, err := repository.SearchBooks("python", 1, 20)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "&lt;script&gt;<mark>Python</mark>&lt;/script&gt;", results[0].TitleHighlight)
+	assert.Equal(t, "Tom &amp; Jerry", results[0].AuthorsHighlight)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchBooksWithoutMatches(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM (SELECT b.id, b.title`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	repository := repositories.NewSearchRepository(gormDB)
+	results, total, err := repository.SearchBooks("nothing", 1, 20)
+
+	assert.Nil(t, err)
+	assert.Zero(t, total)
+	assert.NotNil(t, results)
+	assert.Empty(t, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchBooksReturnGenericError(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM (SELECT b.id, b.title`)).
+		WillReturnError(&errors.BookGenericError)
+
+	repository := repositories.NewSearchRepository(gormDB)
+	results, total, err := repository.SearchBooks("python", 1, 20)
+
+	assert.ErrorIs(t, err, &errors.BookGenericError)
+	assert.Nil(t, results)
+	assert.Zero(t, total)
+}
@@ -0,0 +1,198 @@
+package repositories_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFanOutCreatesDeliveriesForSubscribedWebhooks(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	everything, books := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox" WHERE dispatched_at IS NULL ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED`)).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow(1, models.EventAuthorCreated).AddRow(2, models.EventBookCreated))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhooks"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "events"}).AddRow(everything, nil).AddRow(books, `{book.created,book.updated}`))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "webhook_deliveries" ("webhook_id","event_id","attempts","next_attempt_at","last_status","last_error","delivered_at","dead_at","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9),($10,$11,$12,$13,$14,$15,$16,$17,$18),($19,$20,$21,$22,$23,$24,$25,$26,$27) ON CONFLICT DO NOTHING RETURNING "id"`)).
+		WithArgs(
+			everything, 1, 0, sqlmock.AnyArg(), nil, nil, nil, nil, sqlmock.AnyArg(),
+			everything, 2, 0, sqlmock.AnyArg(), nil, nil, nil, nil, sqlmock.AnyArg(),
+			books, 2, 0, sqlmock.AnyArg(), nil, nil, nil, nil, sqlmock.AnyArg(),
+		).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()).AddRow(uuid.New()).AddRow(uuid.New()))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox" SET "dispatched_at"=$1 WHERE id IN ($2,$3)`)).
+		WithArgs(sqlmock.AnyArg(), 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	events, err := repositories.NewWebhookRepository(gormDB).FanOut(10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFanOutWithoutEvents(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox" WHERE dispatched_at IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	events, err := repositories.NewWebhookRepository(gormDB).FanOut(10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimDeliveriesLeasesThem(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id, webhookID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "webhook_deliveries" WHERE delivered_at IS NULL AND dead_at IS NULL AND next_attempt_at <= $1 ORDER BY next_attempt_at LIMIT $2 FOR UPDATE SKIP LOCKED`)).
+		WithArgs(sqlmock.AnyArg(), 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "webhook_deliveries" SET "next_attempt_at"=$1 WHERE id IN ($2)`)).
+		WithArgs(sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhook_deliveries" WHERE id IN ($1) ORDER BY next_attempt_at`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "event_id"}).AddRow(id, webhookID, 7))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox" WHERE "outbox"."id" = $1`)).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow(7, models.EventBookDeleted))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhooks" WHERE "webhooks"."id" = $1`)).
+		WithArgs(webhookID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url"}).AddRow(webhookID, "https://example.com/hooks"))
+	mock.ExpectCommit()
+
+	deliveries, err := repositories.NewWebhookRepository(gormDB).ClaimDeliveries(time.Minute, 5)
+
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, "https://example.com/hooks", deliveries[0].Webhook.URL)
+	assert.Equal(t, models.EventBookDeleted, deliveries[0].Event.Type)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkFailedWithoutRetryDeadLetters(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "webhook_deliveries" SET "attempts"=attempts + 1,"dead_at"=$1,"last_error"=$2,"last_status"=$3 WHERE id = $4`)).
+		WithArgs(sqlmock.AnyArg(), "unexpected status 500", 500, id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repositories.NewWebhookRepository(gormDB).MarkFailed(id, 500, "unexpected status 500", nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadDeliveries(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "webhook_deliveries" WHERE dead_at IS NOT NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhook_deliveries" WHERE dead_at IS NOT NULL ORDER BY created_at DESC LIMIT $1`)).
+		WithArgs(50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_id"}).AddRow(id, 3))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox" WHERE "outbox"."id" = $1`)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow(3, models.EventAuthorUpdated))
+
+	deliveries, total, err := repositories.NewWebhookRepository(gormDB).GetDeliveries(models.DeliveryDead, 1, 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, models.EventAuthorUpdated, deliveries[0].Event.Type)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryDeliveryReturnNotFoundUnlessDead(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "webhook_deliveries" SET "attempts"=$1,"dead_at"=$2,"next_attempt_at"=$3 WHERE id = $4 AND dead_at IS NOT NULL`)).
+		WithArgs(0, nil, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repositories.NewWebhookRepository(gormDB).Retry(id)
+
+	assert.ErrorIs(t, err, &errors.DeliveryNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteWebhookReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "webhooks" WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repositories.NewWebhookRepository(gormDB).Delete(id)
+
+	assert.ErrorIs(t, err, &errors.WebhookNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,63 @@
+package repositories_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectWork expects the work created for a new book titled title.
+func expectWork(mock sqlmock.Sqlmock, title string) {
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "works" ("title") VALUES ($1) RETURNING "id"`)).
+		WithArgs(title).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	expectAudit(mock, 1)
+}
+
+func TestGetWorkByIDSuccess(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	work := models.Work{ID: uuid.New(), Title: "Fluent Python"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "works" WHERE id = $1 ORDER BY "works"."id" LIMIT $2`)).
+		WithArgs(work.ID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(work.ID, work.Title))
+
+	repository := repositories.NewWorkRepository(gormDB)
+	found, err := repository.GetByID(work.ID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, work, found)
+}
+
+func TestGetWorkByIDReturnNotFound(t *testing.T) {
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "works" WHERE id = $1 ORDER BY "works"."id" LIMIT $2`)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{}))
+
+	repository := repositories.NewWorkRepository(gormDB)
+	_, err := repository.GetByID(id)
+
+	assert.ErrorIs(t, err, &errors.WorkNotFound)
+}
@@ -0,0 +1,119 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joaooliveira247/go_olist_challenge/src/docs"
+	"github.com/joaooliveira247/go_olist_challenge/src/routes"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+type openAPI struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+func setupEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	gormDB, _ := mocks.SetupMockDB()
+
+	eng := gin.New()
+	routes.RegistryRoutes(eng, gormDB)
+
+	return eng
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	var spec openAPI
+
+	assert.NoError(t, json.Unmarshal(docs.OpenAPI, &spec))
+
+	for _, route := range setupEngine().Routes() {
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			operations, ok := spec.Paths[path]
+
+			if assert.Truef(t, ok, "path %s missing from openapi.json", path) {
+				assert.Containsf(t, operations, method, "%s %s missing from openapi.json", route.Method, path)
+			}
+		})
+	}
+}
+
+func TestEveryDocumentedRouteIsRegistered(t *testing.T) {
+	var spec openAPI
+
+	assert.NoError(t, json.Unmarshal(docs.OpenAPI, &spec))
+
+	registered := map[string]bool{}
+
+	for _, route := range setupEngine().Routes() {
+		registered[strings.ToLower(route.Method)+" "+pathParam.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			assert.Truef(t, registered[method+" "+path], "%s %s documented but not registered", method, path)
+		}
+	}
+}
+
+func TestGetSpecSuccess(t *testing.T) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+
+	setupEngine().ServeHTTP(w, req)
+
+	var spec openAPI
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+}
+
+func TestGetDocsSuccess(t *testing.T) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/docs", nil)
+
+	setupEngine().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "/openapi.json")
+}
+
+func TestGetDocsServesSwaggerUIItself(t *testing.T) {
+	eng := setupEngine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/docs", nil)
+	eng.ServeHTTP(w, req)
+
+	assert.NotContains(t, w.Body.String(), "https://")
+
+	for _, asset := range []string{"/docs/assets/swagger-ui.css", "/docs/assets/swagger-ui-bundle.js"} {
+		assert.Contains(t, w.Body.String(), asset)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, asset, nil)
+		eng.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, asset)
+		assert.NotEmpty(t, w.Body.Bytes(), asset)
+	}
+}
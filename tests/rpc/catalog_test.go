@@ -0,0 +1,621 @@
+package rpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/audit"
+	"github.com/joaooliveira247/go_olist_challenge/src/auth"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/errors"
+	"github.com/joaooliveira247/go_olist_challenge/src/middlewares"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/rpc"
+	"github.com/joaooliveira247/go_olist_challenge/src/rpc/catalogpb"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var hmacSecret = []byte("a-very-secret-secret")
+
+type repositories struct {
+	authors *mocks.AuthorRepository
+	books   *mocks.BookRepository
+	apiKeys *mocks.APIKeyRepository
+}
+
+func newRepositories() repositories {
+	repos := repositories{new(mocks.AuthorRepository), new(mocks.BookRepository), new(mocks.APIKeyRepository)}
+
+	repos.authors.On("WithContext", mock.Anything).Return(repos.authors).Maybe()
+	repos.books.On("WithContext", mock.Anything).Return(repos.books).Maybe()
+	repos.apiKeys.On("WithContext", mock.Anything).Return(repos.apiKeys).Maybe()
+
+	return repos
+}
+
+// dial serves the Catalog service on an in-process listener and returns a
+// client connected to it.
+func dial(t *testing.T, repos repositories) catalogpb.CatalogClient {
+	return dialWith(t, repos, middlewares.NewMemoryRateLimitStore(nil))
+}
+
+func dialWith(t *testing.T, repos repositories, store middlewares.RateLimitStore) catalogpb.CatalogClient {
+	listener := bufconn.Listen(1 << 20)
+
+	service := rpc.NewCatalogService(repos.authors, repos.books)
+	server := rpc.NewServer(service, repos.apiKeys, auth.NewJWTVerifier(hmacSecret, nil, "", ""), store)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return catalogpb.NewCatalogClient(conn)
+}
+
+func assertStatus(t *testing.T, err error, code codes.Code, message string) {
+	got, ok := status.FromError(err)
+
+	assert.True(t, ok)
+	assert.Equal(t, code, got.Code())
+	assert.Equal(t, message, got.Message())
+}
+
+func withToken(t *testing.T, role string) context.Context {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "someone",
+		"role": role,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString(hmacSecret)
+
+	assert.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func enableAuth(t *testing.T, publicReads bool) {
+	config.AuthEnabled = true
+	config.AuthPublicReads = publicReads
+
+	t.Cleanup(func() {
+		config.AuthEnabled = false
+		config.AuthPublicReads = true
+	})
+}
+
+func TestCreateAuthorSuccess(t *testing.T) {
+	id := uuid.New()
+	repos := newRepositories()
+
+	repos.authors.On("Create", mock.MatchedBy(func(author *models.Author) bool {
+		return author.Name == "Luciano Ramalho" && *author.Nationality == "BR" && *author.BirthYear == 1970
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Author).ID = id
+	}).Return(id, nil)
+
+	client := dial(t, repos)
+
+	nationality, birthYear := "BR", uint32(1970)
+
+	author, err := client.CreateAuthor(context.Background(), &catalogpb.CreateAuthorRequest{
+		Author: &catalogpb.Author{Name: "Luciano Ramalho", Nationality: &nationality, BirthYear: &birthYear},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), author.GetId())
+	assert.Equal(t, "Luciano Ramalho", author.GetName())
+	assert.Equal(t, uint32(1970), author.GetBirthYear())
+}
+
+func TestCreateAuthorReturnError(t *testing.T) {
+	birthYear, deathYear, tooLate := uint32(1970), uint32(1960), uint32(70000)
+
+	testCases := []struct {
+		name    string
+		author  *catalogpb.Author
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{"short name", &catalogpb.Author{Name: "L"}, nil, codes.InvalidArgument, "invalid argument"},
+		{"died before born", &catalogpb.Author{Name: "Luciano Ramalho", BirthYear: &birthYear, DeathYear: &deathYear}, nil, codes.InvalidArgument, "invalid argument"},
+		{"year out of range", &catalogpb.Author{Name: "Luciano Ramalho", BirthYear: &tooLate}, nil, codes.InvalidArgument, "invalid argument"},
+		{"already exists", &catalogpb.Author{Name: "Luciano Ramalho"}, &errors.AuthorAlreadyExists, codes.AlreadyExists, "author already exists"},
+		{"generic error", &catalogpb.Author{Name: "Luciano Ramalho"}, &errors.AuthorGenericError, codes.Internal, "unable to create entity"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repos := newRepositories()
+			repos.authors.On("Create", mock.Anything).Return(uuid.UUID{}, testCase.err).Maybe()
+
+			_, err := dial(t, repos).CreateAuthor(context.Background(), &catalogpb.CreateAuthorRequest{Author: testCase.author})
+
+			assertStatus(t, err, testCase.code, testCase.message)
+		})
+	}
+}
+
+func TestGetAuthorReturnError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		id      string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{"invalid id", "not-an-id", nil, codes.InvalidArgument, "invalid id"},
+		{"not found", uuid.NewString(), &errors.AuthorNotFound, codes.NotFound, "author not found"},
+		{"generic error", uuid.NewString(), &errors.AuthorGenericError, codes.Internal, "unable to fetch entity"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repos := newRepositories()
+			repos.authors.On("GetByID", mock.Anything).Return(models.Author{}, testCase.err).Maybe()
+
+			_, err := dial(t, repos).GetAuthor(context.Background(), &catalogpb.GetAuthorRequest{Id: testCase.id})
+
+			assertStatus(t, err, testCase.code, testCase.message)
+		})
+	}
+}
+
+func TestListAuthorsWithNationalityPaginates(t *testing.T) {
+	repos := newRepositories()
+
+	repos.authors.On("WithNationality", "BR").Return(repos.authors)
+	repos.authors.On("GetAll").Return([]models.Author{
+		{ID: uuid.New(), Name: "Clarice Lispector"},
+		{ID: uuid.New(), Name: "João Guimarães Rosa"},
+		{ID: uuid.New(), Name: "Machado de Assis"},
+	}, nil)
+
+	result, err := dial(t, repos).ListAuthors(context.Background(), &catalogpb.ListAuthorsRequest{
+		Filter:   &catalogpb.AuthorFilter{Nationality: "BR"},
+		Page:     2,
+		PageSize: 2,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), result.GetTotal())
+	assert.Equal(t, uint32(2), result.GetPage())
+	assert.Len(t, result.GetAuthors(), 1)
+	assert.Equal(t, "Machado de Assis", result.GetAuthors()[0].GetName())
+}
+
+func TestListAuthorsByName(t *testing.T) {
+	repos := newRepositories()
+
+	repos.authors.On("GetBySimilarName", "joao", config.AuthorSimilarityThreshold).Return([]models.AuthorMatch{
+		{Author: models.Author{ID: uuid.New(), Name: "João Guimarães Rosa"}, Similarity: 0.5},
+	}, nil)
+
+	result, err := dial(t, repos).ListAuthors(context.Background(), &catalogpb.ListAuthorsRequest{
+		Filter: &catalogpb.AuthorFilter{Name: "joao"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(20), result.GetPageSize())
+	assert.Equal(t, "João Guimarães Rosa", result.GetAuthors()[0].GetName())
+}
+
+func TestListAuthorsReturnInvalidArgument(t *testing.T) {
+	testCases := []struct {
+		name    string
+		request *catalogpb.ListAuthorsRequest
+	}{
+		{"page size too large", &catalogpb.ListAuthorsRequest{PageSize: 500}},
+		{"invalid nationality", &catalogpb.ListAuthorsRequest{Filter: &catalogpb.AuthorFilter{Nationality: "Brazil"}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := dial(t, newRepositories()).ListAuthors(context.Background(), testCase.request)
+
+			assertStatus(t, err, codes.InvalidArgument, "invalid argument")
+		})
+	}
+}
+
+func TestUpdateAuthorSuccess(t *testing.T) {
+	id := uuid.New()
+	website := "https://ramalho.org"
+	repos := newRepositories()
+
+	repos.authors.On("Update", id, &models.AuthorUpdate{Website: website}).Return(nil)
+	repos.authors.On("GetByID", id).Return(models.Author{ID: id, Name: "Luciano Ramalho", Website: &website}, nil)
+
+	author, err := dial(t, repos).UpdateAuthor(context.Background(), &catalogpb.UpdateAuthorRequest{Id: id.String(), Website: website})
+
+	assert.NoError(t, err)
+	assert.Equal(t, website, author.GetWebsite())
+}
+
+func TestDeleteAuthorNotFound(t *testing.T) {
+	id := uuid.New()
+	repos := newRepositories()
+
+	repos.authors.On("Delete", id).Return(&errors.AuthorNotFound)
+
+	_, err := dial(t, repos).DeleteAuthor(context.Background(), &catalogpb.DeleteAuthorRequest{Id: id.String()})
+
+	assertStatus(t, err, codes.NotFound, "author not found")
+}
+
+func TestCreateBookSuccess(t *testing.T) {
+	id, authorID, translatorID, genreID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	language := "pt"
+	repos := newRepositories()
+
+	repos.books.On("CreateMany", mock.MatchedBy(func(books []models.BookIn) bool {
+		book := books[0]
+		return len(books) == 1 && book.Title == "Python Fluente" && *book.Language == "pt" &&
+			len(book.Contributors) == 2 && book.Contributors[1].AuthorID == translatorID && book.Contributors[1].Role == "translator" &&
+			len(book.GenresID) == 1 && book.GenresID[0] == genreID
+	})).Return([]uuid.UUID{id}, nil)
+	repos.books.On("GetBookByID", id).Return(models.BookOut{
+		Book: models.Book{ID: id, Title: "Python Fluente", Edition: 1, PublicationYear: 2015, Language: &language, Version: 1},
+		Contributors: models.ContributorsOut{
+			{AuthorID: authorID, Name: "Luciano Ramalho", Role: "author"},
+			{AuthorID: translatorID, Name: "Paulo Translator", Role: "translator"},
+		},
+		GenresName: pq.StringArray{"Programming"},
+	}, nil)
+
+	book, err := dial(t, repos).CreateBook(context.Background(), &catalogpb.CreateBookRequest{
+		Title:           "Python Fluente",
+		Edition:         1,
+		PublicationYear: 2015,
+		Language:        &language,
+		Contributors: []*catalogpb.ContributorInput{
+			{AuthorId: authorID.String()},
+			{AuthorId: translatorID.String(), Role: "translator"},
+		},
+		GenreIds: []string{genreID.String()},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), book.GetId())
+	assert.Equal(t, "pt", book.GetLanguage())
+	assert.Equal(t, uint32(1), book.GetVersion())
+	assert.Equal(t, []string{"Programming"}, book.GetGenres())
+	assert.Equal(t, "translator", book.GetContributors()[1].GetRole())
+	assert.Nil(t, book.PublisherId)
+}
+
+func TestCreateBookReturnError(t *testing.T) {
+	authorID := uuid.NewString()
+	badISBN, badPublisher := "9788575224620", "not-an-id"
+
+	testCases := []struct {
+		name    string
+		request *catalogpb.CreateBookRequest
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{"without contributors", &catalogpb.CreateBookRequest{Title: "Fluent Python", Edition: 1, PublicationYear: 2015}, nil, codes.InvalidArgument, "invalid argument"},
+		{"invalid isbn", &catalogpb.CreateBookRequest{Title: "Fluent Python", Edition: 1, PublicationYear: 2015, Isbn: &badISBN, Contributors: []*catalogpb.ContributorInput{{AuthorId: authorID}}}, nil, codes.InvalidArgument, "invalid argument"},
+		{"invalid publisher", &catalogpb.CreateBookRequest{Title: "Fluent Python", Edition: 1, PublicationYear: 2015, PublisherId: &badPublisher, Contributors: []*catalogpb.ContributorInput{{AuthorId: authorID}}}, nil, codes.InvalidArgument, "invalid id"},
+		{"edition out of range", &catalogpb.CreateBookRequest{Title: "Fluent Python", Edition: 300, PublicationYear: 2015, Contributors: []*catalogpb.ContributorInput{{AuthorId: authorID}}}, nil, codes.InvalidArgument, "invalid argument"},
+		{"author not found", &catalogpb.CreateBookRequest{Title: "Fluent Python", Edition: 1, PublicationYear: 2015, Contributors: []*catalogpb.ContributorInput{{AuthorId: authorID}}}, &errors.AuthorNotFound, codes.NotFound, "author not found"},
+		{"already exists", &catalogpb.CreateBookRequest{Title: "Fluent Python", Edition: 1, PublicationYear: 2015, Contributors: []*catalogpb.ContributorInput{{AuthorId: authorID}}}, &errors.BookAlreadyExists, codes.AlreadyExists, "book already exists"},
+		{"generic error", &catalogpb.CreateBookRequest{Title: "Fluent Python", Edition: 1, PublicationYear: 2015, Contributors: []*catalogpb.ContributorInput{{AuthorId: authorID}}}, &errors.BookGenericError, codes.Internal, "unable to create entity"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repos := newRepositories()
+			repos.books.On("CreateMany", mock.Anything).Return(nil, testCase.err).Maybe()
+
+			_, err := dial(t, repos).CreateBook(context.Background(), testCase.request)
+
+			assertStatus(t, err, testCase.code, testCase.message)
+		})
+	}
+}
+
+func TestGetBookNotFound(t *testing.T) {
+	id := uuid.New()
+	repos := newRepositories()
+
+	repos.books.On("GetBookByID", id).Return(models.BookOut{}, &errors.BookNotFound)
+
+	_, err := dial(t, repos).GetBook(context.Background(), &catalogpb.GetBookRequest{Id: id.String()})
+
+	assertStatus(t, err, codes.NotFound, "book not found")
+}
+
+func TestListBooksWithFilter(t *testing.T) {
+	repos := newRepositories()
+
+	repos.books.On("GetBookByQuery", "b.edition = ? AND b.language = ?", []interface{}{uint8(2), "pt"}).Return([]models.BookOut{
+		{Book: models.Book{ID: uuid.New(), Title: "Python Fluente", Edition: 2, PublicationYear: 2023, Version: 1}},
+	}, nil)
+
+	result, err := dial(t, repos).ListBooks(context.Background(), &catalogpb.ListBooksRequest{
+		Filter: &catalogpb.BookFilter{Edition: 2, Language: "PT"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), result.GetTotal())
+	assert.Equal(t, "Python Fluente", result.GetBooks()[0].GetTitle())
+}
+
+func TestListBooksByAuthor(t *testing.T) {
+	authorID := uuid.New()
+	repos := newRepositories()
+
+	repos.books.On("GetBooksByAuthorID", authorID).Return([]models.BookOut{
+		{Book: models.Book{ID: uuid.New(), Title: "Fluent Python", Edition: 1, PublicationYear: 2015, Version: 1}},
+	}, nil)
+
+	result, err := dial(t, repos).ListBooks(context.Background(), &catalogpb.ListBooksRequest{
+		Filter: &catalogpb.BookFilter{AuthorId: authorID.String(), Title: "ignored"},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.GetBooks(), 1)
+}
+
+func TestUpdateBookReplacesContributorsAndGenres(t *testing.T) {
+	id, authorID, genreID := uuid.New(), uuid.New(), uuid.New()
+	repos := newRepositories()
+
+	repos.books.On("Update", id, mock.MatchedBy(func(update *models.BookUpdate) bool {
+		return update.Title == "Fluent Python" &&
+			assert.ObjectsAreEqual([]models.BookAuthor{{BookID: id, AuthorID: authorID, Role: "author", Position: 0}}, update.BookAuthors(id)) &&
+			assert.ObjectsAreEqual([]uuid.UUID{genreID}, update.GenresID)
+	}), uint(3)).Return(nil).Once()
+	repos.books.On("GetBookByID", id).Return(models.BookOut{Book: models.Book{ID: id, Title: "Fluent Python", Version: 4}}, nil)
+
+	book, err := dial(t, repos).UpdateBook(context.Background(), &catalogpb.UpdateBookRequest{
+		Id:           id.String(),
+		Version:      3,
+		Title:        "Fluent Python",
+		Contributors: []*catalogpb.ContributorInput{{AuthorId: authorID.String()}},
+		GenreIds:     []string{genreID.String()},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(4), book.GetVersion())
+	repos.books.AssertExpectations(t)
+}
+
+func TestUpdateBookReturnError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		version        uint32
+		requireVersion bool
+		err            error
+		code           codes.Code
+		message        string
+	}{
+		{"version mismatch", 2, false, &errors.BookVersionMismatch, codes.FailedPrecondition, "version does not match"},
+		{"version required", 0, true, nil, codes.FailedPrecondition, "version required"},
+		{"translation of itself", 2, false, &errors.InvalidTranslation, codes.InvalidArgument, "book can not be a translation of itself"},
+		{"not found", 2, false, &errors.BookNothingToUpdate, codes.NotFound, "book not found"},
+		{"author not found", 2, false, &errors.AuthorNotFound, codes.NotFound, "author not found"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config.RequireIfMatch = testCase.requireVersion
+			defer func() { config.RequireIfMatch = false }()
+
+			repos := newRepositories()
+			repos.books.On("Update", mock.Anything, mock.Anything, uint(testCase.version)).Return(testCase.err).Maybe()
+
+			_, err := dial(t, repos).UpdateBook(context.Background(), &catalogpb.UpdateBookRequest{
+				Id:      uuid.NewString(),
+				Version: testCase.version,
+				Title:   "Fluent Python",
+			})
+
+			assertStatus(t, err, testCase.code, testCase.message)
+		})
+	}
+}
+
+func TestDeleteBookWithVersion(t *testing.T) {
+	id := uuid.New()
+	repos := newRepositories()
+
+	repos.books.On("Delete", id, uint(5)).Return(nil)
+
+	_, err := dial(t, repos).DeleteBook(context.Background(), &catalogpb.DeleteBookRequest{Id: id.String(), Version: 5})
+
+	assert.NoError(t, err)
+	repos.books.AssertExpectations(t)
+}
+
+func TestExportBooksStreamsEveryBook(t *testing.T) {
+	repos := newRepositories()
+
+	var books []models.BookOut
+	for i := 1; i <= 150; i++ {
+		books = append(books, models.BookOut{Book: models.Book{ID: uuid.New(), Title: fmt.Sprintf("Book %d", i), Version: 1}})
+	}
+	repos.books.On("GetAll").Return(books, nil)
+
+	stream, err := dial(t, repos).ExportBooks(context.Background(), &catalogpb.ExportBooksRequest{})
+
+	assert.NoError(t, err)
+
+	var titles []string
+	for {
+		book, err := stream.Recv()
+
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		titles = append(titles, book.GetTitle())
+	}
+
+	assert.Len(t, titles, 150)
+	assert.Equal(t, "Book 150", titles[149])
+}
+
+func TestExportAuthorsReturnError(t *testing.T) {
+	repos := newRepositories()
+
+	repos.authors.On("GetAll").Return(nil, &errors.AuthorGenericError)
+
+	stream, err := dial(t, repos).ExportAuthors(context.Background(), &catalogpb.ExportAuthorsRequest{})
+
+	assert.NoError(t, err)
+
+	_, err = stream.Recv()
+
+	assertStatus(t, err, codes.Internal, "unable to fetch entity")
+}
+
+func TestAuthorization(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ctx         func(t *testing.T) context.Context
+		publicReads bool
+		read        codes.Code
+		write       codes.Code
+	}{
+		{"anonymous with public reads", func(*testing.T) context.Context { return context.Background() }, true, codes.OK, codes.Unauthenticated},
+		{"anonymous without public reads", func(*testing.T) context.Context { return context.Background() }, false, codes.Unauthenticated, codes.Unauthenticated},
+		{"reader", func(t *testing.T) context.Context { return withToken(t, "reader") }, false, codes.OK, codes.PermissionDenied},
+		{"librarian", func(t *testing.T) context.Context { return withToken(t, "librarian") }, false, codes.OK, codes.OK},
+		{"invalid token", func(*testing.T) context.Context {
+			return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer nope")
+		}, true, codes.Unauthenticated, codes.Unauthenticated},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			enableAuth(t, testCase.publicReads)
+
+			repos := newRepositories()
+			repos.authors.On("GetAll").Return([]models.Author{}, nil).Maybe()
+			repos.authors.On("Create", mock.Anything).Return(uuid.New(), nil).Maybe()
+
+			client := dial(t, repos)
+
+			_, err := client.ListAuthors(testCase.ctx(t), &catalogpb.ListAuthorsRequest{})
+			assert.Equal(t, testCase.read, status.Code(err))
+
+			_, err = client.CreateAuthor(testCase.ctx(t), &catalogpb.CreateAuthorRequest{Author: &catalogpb.Author{Name: "Luciano Ramalho"}})
+			assert.Equal(t, testCase.write, status.Code(err))
+		})
+	}
+}
+
+func TestAuthorizationWithAPIKeySetsAuditActor(t *testing.T) {
+	enableAuth(t, false)
+
+	key := "olk_secret"
+	keyID := uuid.New()
+	repos := newRepositories()
+
+	repos.apiKeys.On("GetActiveByHash", auth.HashAPIKey(key)).Return(models.APIKey{ID: keyID, Role: "admin"}, nil)
+
+	var actor string
+
+	repos.books.ExpectedCalls = nil
+	repos.books.On("WithContext", mock.Anything).Run(func(args mock.Arguments) {
+		actor = audit.Actor(args.Get(0).(context.Context))
+	}).Return(repos.books)
+	repos.books.On("Delete", mock.Anything, uint(0)).Return(nil)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+
+	_, err := dial(t, repos).DeleteBook(ctx, &catalogpb.DeleteBookRequest{Id: uuid.NewString()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("api_key:%s", keyID), actor)
+}
+
+func limitReads(t *testing.T, requests int) {
+	reads := config.RateLimitReads
+	config.RateLimitReads = requests
+
+	t.Cleanup(func() { config.RateLimitReads = reads })
+}
+
+func TestRateLimitRejectsCallsOverTheClientBudget(t *testing.T) {
+	limitReads(t, 1)
+
+	repos := newRepositories()
+	repos.authors.On("GetAll").Return([]models.Author{}, nil).Once()
+
+	client := dial(t, repos)
+
+	_, err := client.ListAuthors(context.Background(), &catalogpb.ListAuthorsRequest{})
+	assert.NoError(t, err)
+
+	var header metadata.MD
+
+	_, err = client.ListAuthors(context.Background(), &catalogpb.ListAuthorsRequest{}, grpc.Header(&header))
+
+	assertStatus(t, err, codes.ResourceExhausted, "too many requests")
+	assert.Equal(t, []string{"60"}, header.Get("retry-after"))
+}
+
+func TestRateLimitFailedAuthThrottlesKeyGuessing(t *testing.T) {
+	enableAuth(t, false)
+	limitReads(t, 2)
+
+	repos := newRepositories()
+	repos.apiKeys.On("GetActiveByHash", mock.Anything).Return(models.APIKey{}, &errors.APIKeyNotFound)
+	repos.authors.On("GetAll").Return([]models.Author{}, nil).Maybe()
+
+	store := middlewares.NewMemoryRateLimitStore(nil)
+	client := dialWith(t, repos, store)
+
+	for _, key := range []string{"olk_guess1", "olk_guess2"} {
+		_, err := client.ListAuthors(metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key), &catalogpb.ListAuthorsRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	_, err := client.ListAuthors(withToken(t, "reader"), &catalogpb.ListAuthorsRequest{})
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	repos.authors.AssertNotCalled(t, "GetAll")
+}
+
+func TestRateLimitDoesNotChargeSuccessfulAuthToFailedAuth(t *testing.T) {
+	enableAuth(t, false)
+	limitReads(t, 3)
+
+	repos := newRepositories()
+	repos.authors.On("GetAll").Return([]models.Author{}, nil)
+
+	client := dial(t, repos)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.ListAuthors(withToken(t, "reader"), &catalogpb.ListAuthorsRequest{})
+		assert.NoError(t, err)
+	}
+}
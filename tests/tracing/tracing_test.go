@@ -0,0 +1,120 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/config"
+	"github.com/joaooliveira247/go_olist_challenge/src/repositories"
+	"github.com/joaooliveira247/go_olist_challenge/src/tracing"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return recorder
+}
+
+func TestSetupDisabledReturnNoop(t *testing.T) {
+	config.TracingEnabled = false
+
+	shutdown, err := tracing.Setup(context.Background())
+
+	assert.Nil(t, err)
+	assert.Nil(t, shutdown(context.Background()))
+}
+
+func TestSetupReturnErrorWhenExporterNotSupported(t *testing.T) {
+	config.TracingEnabled = true
+	config.TracingExporter = "zipkin"
+
+	defer func() {
+		config.TracingEnabled = false
+		config.TracingExporter = "stdout"
+	}()
+
+	_, err := tracing.Setup(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestRepositoryCallCreatesChildSpan(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	gormDB, mock := mocks.SetupMockDB()
+
+	defer func() {
+		db, _ := gormDB.DB()
+		db.Close()
+	}()
+
+	authorID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "authors" WHERE id = $1 AND "authors"."deleted_at" IS NULL ORDER BY "authors"."id" LIMIT $2`)).
+		WithArgs(authorID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(authorID, "Luciano Ramalho"))
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "request")
+
+	repository := repositories.NewAuthorRepository(gormDB)
+	_, err := repository.WithContext(ctx).GetByID(authorID)
+
+	parent.End()
+
+	assert.Nil(t, err)
+
+	spans := recorder.Ended()
+
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "authorRepository.GetByID", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+}
+
+func TestMiddlewarePropagatesTraceContext(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	eng.Use(tracing.Middleware())
+	eng.GET("/books/", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/books/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	eng.ServeHTTP(w, req)
+
+	spans := recorder.Ended()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+}
@@ -0,0 +1,180 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func writeCSV(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "authors.csv")
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseAuthorsFromCSVMapsHeaderColumns(t *testing.T) {
+	path := writeCSV(t, "name,Nationality,birth_year,death_year,sort_name\n"+
+		"Jorge Amado,br,1912,2001,\"Amado, Jorge\"\n"+
+		"Luciano Ramalho,,,,\n")
+
+	authors, err := utils.ParseAuthorsFromCSV(path, true, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, authors, 2)
+	assert.Equal(t, "Jorge Amado", authors[0].Name)
+	assert.Equal(t, "BR", *authors[0].Nationality)
+	assert.Equal(t, uint16(1912), *authors[0].BirthYear)
+	assert.Equal(t, uint16(2001), *authors[0].DeathYear)
+	assert.Equal(t, "Amado, Jorge", *authors[0].SortName)
+	assert.Equal(t, "Luciano Ramalho", authors[1].Name)
+	assert.Nil(t, authors[1].Nationality)
+	assert.Nil(t, authors[1].BirthYear)
+}
+
+func TestParseAuthorsFromCSVUsesColumnsOrName(t *testing.T) {
+	path := writeCSV(t, "Jorge Amado,BR\n")
+
+	authors, err := utils.ParseAuthorsFromCSV(path, false, []string{"name", "nationality"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "BR", *authors[0].Nationality)
+
+	authors, err = utils.ParseAuthorsFromCSV(path, false, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Jorge Amado", authors[0].Name)
+	assert.Nil(t, authors[0].Nationality)
+}
+
+func TestParseAuthorsFromCSVReturnError(t *testing.T) {
+	cases := map[string]string{
+		"unknown column": "name,country\nJorge Amado,BR\n",
+		"invalid year":   "name,birth_year\nJorge Amado,nineteen twelve\n",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := utils.ParseAuthorsFromCSV(writeCSV(t, content), true, nil)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseBooksFromCSVMapsHeaderColumns(t *testing.T) {
+	authorID, translatorID, genreID := uuid.New(), uuid.New(), uuid.New()
+
+	path := writeCSV(t, "title,edition,publication_year,isbn,language,author_ids,genre_ids\n"+
+		"Python Fluente,1,2015,0-306-40615-2,pt,"+authorID.String()+"; "+translatorID.String()+","+genreID.String()+"\n"+
+		"Capitães da Areia,3,1937,,,"+authorID.String()+",\n")
+
+	books, err := utils.ParseBooksFromCSV(path, true, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, books, 2)
+	assert.Equal(t, "Python Fluente", books[0].Title)
+	assert.Equal(t, uint8(1), books[0].Edition)
+	assert.Equal(t, uint(2015), books[0].PublicationYear)
+	assert.Equal(t, "0-306-40615-2", *books[0].ISBN)
+	assert.Equal(t, "pt", *books[0].Language)
+	assert.Equal(t, []uuid.UUID{authorID, translatorID}, books[0].AuthorsID)
+	assert.Equal(t, []uuid.UUID{genreID}, books[0].GenresID)
+	assert.Nil(t, books[1].ISBN)
+	assert.Nil(t, books[1].GenresID)
+}
+
+func TestParseBooksFromCSVDefaultsToBookColumns(t *testing.T) {
+	authorID := uuid.New()
+	path := writeCSV(t, "Python Fluente,1,2015,9780306406157,,pt,"+authorID.String()+"\n")
+
+	books, err := utils.ParseBooksFromCSV(path, false, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "9780306406157", *books[0].ISBN)
+	assert.Nil(t, books[0].PublisherID)
+	assert.Equal(t, []uuid.UUID{authorID}, books[0].AuthorsID)
+	assert.Equal(t, "title", utils.BookColumns[0])
+}
+
+func TestParseBooksFromCSVReturnError(t *testing.T) {
+	cases := map[string]string{
+		"unknown column":    "title,authors\nPython Fluente,Luciano Ramalho\n",
+		"invalid edition":   "title,edition\nPython Fluente,first\n",
+		"invalid author id": "title,author_ids\nPython Fluente,Luciano Ramalho\n",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := utils.ParseBooksFromCSV(writeCSV(t, content), true, nil)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAuthorRecordLeavesUnsetFieldsEmpty(t *testing.T) {
+	id := uuid.New()
+	sortName, website := "Amado, Jorge", "https://jorgeamado.org.br"
+	deletedAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	record := utils.AuthorRecord(models.Author{
+		ID:        id,
+		Name:      "Jorge Amado",
+		SortName:  &sortName,
+		Website:   &website,
+		DeletedAt: models.DeletedAt{DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+	})
+
+	assert.Len(t, record, len(utils.AuthorExportColumns))
+	assert.Equal(t, []string{id.String(), "Jorge Amado", "Amado, Jorge", "", "", "", "", website, "2024-05-01T12:30:00Z"}, record)
+}
+
+func TestBookRecordJoinsListColumns(t *testing.T) {
+	id, publisherID, workID := uuid.New(), uuid.New(), uuid.New()
+
+	record := utils.BookRecord(models.BookOut{
+		Book: models.Book{ID: id, Title: "Capitães da Areia", Edition: 3, PublicationYear: 1937, PublisherID: &publisherID, WorkID: &workID, Version: 1},
+		Contributors: models.ContributorsOut{
+			{AuthorID: uuid.New(), Name: "Jorge Amado", Role: "author"},
+			{AuthorID: uuid.New(), Name: "Poty", Role: "illustrator"},
+		},
+		AuthorsName: []string{"Jorge Amado", "Poty"},
+	})
+
+	assert.Len(t, record, len(utils.BookExportColumns))
+	assert.Equal(t, []string{
+		id.String(), "Capitães da Areia", "3", "1937", "", "", publisherID.String(), workID.String(), "", "", "1",
+		"Jorge Amado; Poty", "Jorge Amado (author); Poty (illustrator)", "", "",
+	}, record)
+}
+
+func TestRecordsNeutralizeFormulas(t *testing.T) {
+	biography, website := "-2+3", "@SUM(A1:A2)"
+
+	author := utils.AuthorRecord(models.Author{ID: uuid.New(), Name: "=HYPERLINK(\"http://evil\")", Biography: &biography, Website: &website})
+
+	assert.Equal(t, "'=HYPERLINK(\"http://evil\")", author[1])
+	assert.Equal(t, "'-2+3", author[3])
+	assert.Equal(t, "'@SUM(A1:A2)", author[7])
+
+	book := utils.BookRecord(models.BookOut{
+		Book:        models.Book{ID: uuid.New(), Title: "+cmd|' /C calc'!A0", Edition: 1, PublicationYear: 2020},
+		AuthorsName: []string{"=1+1", "Jorge Amado"},
+		GenresName:  []string{"Drama", "=Fiction"},
+	})
+
+	assert.Equal(t, "'+cmd|' /C calc'!A0", book[1])
+	assert.Equal(t, "'=1+1; Jorge Amado", book[11])
+	assert.Equal(t, "Drama; =Fiction", book[13])
+	assert.Equal(t, "1", book[2])
+}
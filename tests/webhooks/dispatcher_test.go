@@ -0,0 +1,172 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joaooliveira247/go_olist_challenge/src/models"
+	"github.com/joaooliveira247/go_olist_challenge/src/webhooks"
+	"github.com/joaooliveira247/go_olist_challenge/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// receiver records the requests delivered to it and answers status.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (receiver *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	receiver.mu.Lock()
+	receiver.requests = append(receiver.requests, r)
+	receiver.bodies = append(receiver.bodies, body)
+	receiver.mu.Unlock()
+
+	w.WriteHeader(receiver.status)
+}
+
+func newDelivery(url string, attempts int) models.WebhookDelivery {
+	bookID := uuid.New()
+
+	return models.WebhookDelivery{
+		ID:       uuid.New(),
+		Attempts: attempts,
+		Webhook:  models.Webhook{ID: uuid.New(), URL: url, Secret: "secret"},
+		Event: models.OutboxEvent{
+			ID:       42,
+			Type:     models.EventBookCreated,
+			EntityID: bookID,
+			Payload:  models.NewJSON(models.EventData{ID: bookID, Entity: "book", After: models.NewJSON(map[string]string{"title": "Capitães da Areia"})}),
+		},
+	}
+}
+
+func newRepository(deliveries ...models.WebhookDelivery) *mocks.WebhookRepository {
+	repository := new(mocks.WebhookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("FanOut", mock.Anything).Return(len(deliveries), nil).Once()
+	repository.On("ClaimDeliveries", mock.Anything, mock.Anything).Return(deliveries, nil).Once()
+
+	return repository
+}
+
+func TestRunOnceDeliversSignedEvents(t *testing.T) {
+	hook := &receiver{status: http.StatusNoContent}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	delivery := newDelivery(server.URL, 0)
+	repository := newRepository(delivery)
+	repository.On("MarkDelivered", delivery.ID, http.StatusNoContent).Return(nil).Once()
+
+	dispatcher := webhooks.NewDispatcher(repository, 3, time.Second, time.Second)
+
+	assert.NoError(t, dispatcher.RunOnce(context.Background()))
+	repository.AssertExpectations(t)
+
+	assert.Len(t, hook.requests, 1)
+
+	request, body := hook.requests[0], hook.bodies[0]
+	timestamp, err := strconv.ParseInt(request.Header.Get(webhooks.TimestampHeader), 10, 64)
+
+	assert.NoError(t, err)
+	assert.Equal(t, webhooks.Sign("secret", timestamp, body), request.Header.Get(webhooks.SignatureHeader))
+	assert.NotEqual(t, webhooks.Sign("other", timestamp, body), request.Header.Get(webhooks.SignatureHeader))
+	assert.Equal(t, models.EventBookCreated, request.Header.Get(webhooks.EventHeader))
+	assert.Equal(t, delivery.ID.String(), request.Header.Get(webhooks.DeliveryHeader))
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+	var event models.OutboxEvent
+
+	assert.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, delivery.Event.ID, event.ID)
+	assert.Equal(t, delivery.Event.EntityID, event.EntityID)
+	assert.JSONEq(t, string(delivery.Event.Payload), string(event.Payload))
+}
+
+func TestRunOnceRetriesFailedDeliveriesWithBackoff(t *testing.T) {
+	hook := &receiver{status: http.StatusInternalServerError}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	delivery := newDelivery(server.URL, 2)
+	repository := newRepository(delivery)
+
+	before := time.Now()
+
+	repository.On("MarkFailed", delivery.ID, http.StatusInternalServerError, "unexpected status 500", mock.MatchedBy(func(retryAt *time.Time) bool {
+		return retryAt != nil && !retryAt.Before(before.Add(4*time.Minute)) && retryAt.Before(time.Now().Add(4*time.Minute+time.Second))
+	})).Return(nil).Once()
+
+	dispatcher := webhooks.NewDispatcher(repository, 5, time.Minute, time.Second)
+
+	assert.NoError(t, dispatcher.RunOnce(context.Background()))
+	repository.AssertExpectations(t)
+}
+
+func TestRunOnceDeadLettersTheLastAttempt(t *testing.T) {
+	hook := &receiver{status: http.StatusGone}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	delivery := newDelivery(server.URL, 4)
+	repository := newRepository(delivery)
+	repository.On("MarkFailed", delivery.ID, http.StatusGone, "unexpected status 410", (*time.Time)(nil)).Return(nil).Once()
+
+	dispatcher := webhooks.NewDispatcher(repository, 5, time.Minute, time.Second)
+
+	assert.NoError(t, dispatcher.RunOnce(context.Background()))
+	repository.AssertExpectations(t)
+}
+
+func TestRunOnceRetriesUnreachableWebhooks(t *testing.T) {
+	server := httptest.NewServer(&receiver{status: http.StatusOK})
+	server.Close()
+
+	delivery := newDelivery(server.URL, 0)
+	repository := newRepository(delivery)
+	repository.On("MarkFailed", delivery.ID, 0, mock.AnythingOfType("string"), mock.AnythingOfType("*time.Time")).Return(nil).Once()
+
+	dispatcher := webhooks.NewDispatcher(repository, 5, time.Minute, time.Second)
+
+	assert.NoError(t, dispatcher.RunOnce(context.Background()))
+	repository.AssertExpectations(t)
+}
+
+func TestRunOnceFansOutEveryBatch(t *testing.T) {
+	repository := new(mocks.WebhookRepository)
+	repository.On("WithContext", mock.Anything).Return(repository).Maybe()
+	repository.On("FanOut", 100).Return(100, nil).Twice()
+	repository.On("FanOut", 100).Return(3, nil).Once()
+	repository.On("ClaimDeliveries", mock.Anything, 100).Return([]models.WebhookDelivery{}, nil).Once()
+
+	dispatcher := webhooks.NewDispatcher(repository, 5, time.Minute, time.Second)
+
+	assert.NoError(t, dispatcher.RunOnce(context.Background()))
+	repository.AssertExpectations(t)
+}
+
+func TestBackoffDoublesUpToTheCap(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhooks.Backoff(30*time.Second, 1))
+	assert.Equal(t, time.Minute, webhooks.Backoff(30*time.Second, 2))
+	assert.Equal(t, 4*time.Minute, webhooks.Backoff(30*time.Second, 4))
+	assert.Equal(t, 6*time.Hour, webhooks.Backoff(30*time.Second, 40))
+}
+
+func TestSignMatchesTheDocumentedScheme(t *testing.T) {
+	// echo -n '1700000000.{"id":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11", webhooks.Sign("secret", 1700000000, []byte(`{"id":1}`)))
+}